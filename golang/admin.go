@@ -0,0 +1,288 @@
+package logger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// AdminOptions configures the handler returned by NewAdminHandler
+type AdminOptions struct {
+	// Authorize is called for every request; requests it rejects are answered
+	// with 403 Forbidden. If nil, all requests are allowed.
+	Authorize func(r *http.Request) bool
+}
+
+// BearerTokenAuth returns an Authorize function accepting requests that carry
+// the given token in an "Authorization: Bearer <token>" header
+func BearerTokenAuth(token string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+	}
+}
+
+// adminHandler serves the runtime log control API of a logger
+type adminHandler struct {
+	logger *Logger
+	opts   AdminOptions
+	mux    *http.ServeMux
+}
+
+// NewAdminHandler returns an http.Handler for inspecting and changing the
+// levels and sampling rates of a running logger. It serves:
+//
+//	GET    /                           complete state (levels, sampling, queue statistics)
+//	GET    /level                      global level
+//	PUT    /level                      set global level, body {"level": "debug"}
+//	GET    /components                 levels set per component
+//	PUT    /components/{name}          set component level, body {"level": "debug"}
+//	DELETE /components/{name}          remove component level
+//	GET    /sampling                   sampling rates set per key
+//	PUT    /sampling/{key}             set sampling rate, body {"rate": 100}
+//	DELETE /sampling/{key}             restore the call-site sampling rate
+//	GET    /sampling/components        sampling rates set per component
+//	PUT    /sampling/components/{name} sample all entries of a component, body {"rate": 100}
+//	DELETE /sampling/components/{name} stop sampling a component
+//	GET    /sampling/levels            sampling rates set per level
+//	PUT    /sampling/levels/{level}    sample all entries at a level, body {"rate": 100}
+//	DELETE /sampling/levels/{level}    stop sampling a level
+//	GET    /stats                      queue and drop statistics
+//	GET    /health                     pipeline health, 503 Service Unavailable if unhealthy
+//
+// Mount it under a prefix with http.StripPrefix:
+//
+//	mux.Handle("/debug/logging/", http.StripPrefix("/debug/logging",
+//		logger.NewAdminHandler(l, logger.AdminOptions{Authorize: logger.BearerTokenAuth(token)})))
+func NewAdminHandler(l *Logger, opts AdminOptions) http.Handler {
+	h := &adminHandler{
+		logger: l,
+		opts:   opts,
+		mux:    http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("GET /{$}", h.getState)
+	h.mux.HandleFunc("GET /level", h.getLevel)
+	h.mux.HandleFunc("PUT /level", h.putLevel)
+	h.mux.HandleFunc("GET /components", h.getComponents)
+	h.mux.HandleFunc("PUT /components/{name}", h.putComponent)
+	h.mux.HandleFunc("DELETE /components/{name}", h.deleteComponent)
+	h.mux.HandleFunc("GET /sampling", h.getSampling)
+	h.mux.HandleFunc("PUT /sampling/{key}", h.putSampling)
+	h.mux.HandleFunc("DELETE /sampling/{key}", h.deleteSampling)
+	h.mux.HandleFunc("GET /sampling/components", h.getComponentSampling)
+	h.mux.HandleFunc("PUT /sampling/components/{name}", h.putComponentSampling)
+	h.mux.HandleFunc("DELETE /sampling/components/{name}", h.deleteComponentSampling)
+	h.mux.HandleFunc("GET /sampling/levels", h.getLevelSampling)
+	h.mux.HandleFunc("PUT /sampling/levels/{level}", h.putLevelSampling)
+	h.mux.HandleFunc("DELETE /sampling/levels/{level}", h.deleteLevelSampling)
+	h.mux.HandleFunc("GET /stats", h.getStats)
+	h.mux.HandleFunc("GET /health", h.getHealth)
+
+	return h
+}
+
+// ServeHTTP checks authorization and dispatches the request
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Authorize != nil && !h.opts.Authorize(r) {
+		writeAdminError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// adminState is the complete state returned by GET /
+type adminState struct {
+	Level             string            `json:"level"`
+	Components        map[string]string `json:"components"`
+	Sampling          map[string]int    `json:"sampling"`
+	ComponentSampling map[string]int    `json:"component_sampling"`
+	LevelSampling     map[string]int    `json:"level_sampling"`
+	Queue             QueueStats        `json:"queue"`
+}
+
+func (h *adminHandler) componentLevels() map[string]string {
+	levels := h.logger.ComponentLevels()
+	names := make(map[string]string, len(levels))
+	for name, level := range levels {
+		names[name] = level.String()
+	}
+	return names
+}
+
+func (h *adminHandler) getState(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, adminState{
+		Level:             h.logger.GetLevel().String(),
+		Components:        h.componentLevels(),
+		Sampling:          h.logger.SamplingRates(),
+		ComponentSampling: h.logger.ComponentSamplingRates(),
+		LevelSampling:     h.levelSamplingRates(),
+		Queue:             h.logger.QueueStats(),
+	})
+}
+
+func (h *adminHandler) getLevel(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, map[string]string{"level": h.logger.GetLevel().String()})
+}
+
+func (h *adminHandler) putLevel(w http.ResponseWriter, r *http.Request) {
+	level, ok := readAdminLevel(w, r)
+	if !ok {
+		return
+	}
+	h.logger.SetLevel(level)
+	h.getLevel(w, r)
+}
+
+func (h *adminHandler) getComponents(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, h.componentLevels())
+}
+
+func (h *adminHandler) putComponent(w http.ResponseWriter, r *http.Request) {
+	level, ok := readAdminLevel(w, r)
+	if !ok {
+		return
+	}
+	h.logger.SetComponentLevel(r.PathValue("name"), level)
+	h.getComponents(w, r)
+}
+
+func (h *adminHandler) deleteComponent(w http.ResponseWriter, r *http.Request) {
+	if !h.logger.ClearComponentLevel(r.PathValue("name")) {
+		writeAdminError(w, http.StatusNotFound, "no level set for component")
+		return
+	}
+	h.getComponents(w, r)
+}
+
+func (h *adminHandler) getSampling(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, h.logger.SamplingRates())
+}
+
+func (h *adminHandler) putSampling(w http.ResponseWriter, r *http.Request) {
+	rate, ok := readAdminRate(w, r)
+	if !ok {
+		return
+	}
+	h.logger.SetSamplingRate(r.PathValue("key"), rate)
+	h.getSampling(w, r)
+}
+
+func (h *adminHandler) deleteSampling(w http.ResponseWriter, r *http.Request) {
+	h.logger.SetSamplingRate(r.PathValue("key"), 0)
+	h.getSampling(w, r)
+}
+
+func (h *adminHandler) getComponentSampling(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, h.logger.ComponentSamplingRates())
+}
+
+func (h *adminHandler) putComponentSampling(w http.ResponseWriter, r *http.Request) {
+	rate, ok := readAdminRate(w, r)
+	if !ok {
+		return
+	}
+	h.logger.SetComponentSamplingRate(r.PathValue("name"), rate)
+	h.getComponentSampling(w, r)
+}
+
+func (h *adminHandler) deleteComponentSampling(w http.ResponseWriter, r *http.Request) {
+	h.logger.SetComponentSamplingRate(r.PathValue("name"), 0)
+	h.getComponentSampling(w, r)
+}
+
+func (h *adminHandler) levelSamplingRates() map[string]int {
+	rates := h.logger.LevelSamplingRates()
+	names := make(map[string]int, len(rates))
+	for level, rate := range rates {
+		names[level.String()] = rate
+	}
+	return names
+}
+
+func (h *adminHandler) getLevelSampling(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, h.levelSamplingRates())
+}
+
+func (h *adminHandler) putLevelSampling(w http.ResponseWriter, r *http.Request) {
+	level, err := ParseLevel(r.PathValue("level"))
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rate, ok := readAdminRate(w, r)
+	if !ok {
+		return
+	}
+	h.logger.SetLevelSamplingRate(level, rate)
+	h.getLevelSampling(w, r)
+}
+
+func (h *adminHandler) deleteLevelSampling(w http.ResponseWriter, r *http.Request) {
+	level, err := ParseLevel(r.PathValue("level"))
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.logger.SetLevelSamplingRate(level, 0)
+	h.getLevelSampling(w, r)
+}
+
+func (h *adminHandler) getStats(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, h.logger.QueueStats())
+}
+
+func (h *adminHandler) getHealth(w http.ResponseWriter, r *http.Request) {
+	health := h.logger.Health()
+	status := http.StatusOK
+	if !health.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	writeAdminJSON(w, status, health)
+}
+
+// readAdminLevel decodes a {"level": "..."} request body
+func readAdminLevel(w http.ResponseWriter, r *http.Request) (Level, bool) {
+	var body struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeAdminError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return 0, false
+	}
+	level, err := ParseLevel(body.Level)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	return level, true
+}
+
+// readAdminRate decodes a {"rate": N} request body, rejecting rates below 1
+func readAdminRate(w http.ResponseWriter, r *http.Request) (int, bool) {
+	var body struct {
+		Rate int `json:"rate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeAdminError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return 0, false
+	}
+	if body.Rate < 1 {
+		writeAdminError(w, http.StatusBadRequest, "rate must be at least 1")
+		return 0, false
+	}
+	return body.Rate, true
+}
+
+// writeAdminJSON writes v as a JSON response
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAdminError writes a JSON error response
+func writeAdminError(w http.ResponseWriter, status int, msg string) {
+	writeAdminJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaxErrorGroups bounds the fingerprints an aggregator tracks at once; errors
+// with new fingerprints past it are logged as usual, untracked
+const MaxErrorGroups = 1000
+
+// FingerprintField carries the fingerprint of aggregated errors and of their
+// summaries, and OccurrencesField how often the error occurred in a summary
+const (
+	FingerprintField = "fingerprint"
+	OccurrencesField = "occurrences"
+)
+
+// errorGroup counts the errors sharing a fingerprint
+type errorGroup struct {
+	fingerprint string
+	message     string // Message template
+	component   string
+	caller      string // file:line, if caller capture is enabled
+	level       Level  // Most severe level seen
+	count       uint64 // Occurrences since the last summary
+	suppressed  uint64 // Occurrences since the last summary that were dropped
+	total       uint64 // Occurrences since first seen
+	first       time.Time
+	last        time.Time
+}
+
+// errorAggregator fingerprints entries at Error and above by component,
+// message template and caller, and periodically logs a summary of those that
+// repeated. Groups idle for a whole interval are forgotten.
+type errorAggregator struct {
+	active     int32 // Atomic, non-zero when aggregating
+	mu         sync.Mutex
+	suppress   bool // Drop repeats within an interval
+	groups     map[string]*errorGroup
+	lastReport time.Time
+	started    bool
+	stop       chan struct{} // Closed to stop the summary goroutine
+	logger     *Logger       // Root logger the summaries are written to
+}
+
+func newErrorAggregator(l *Logger) *errorAggregator {
+	return &errorAggregator{
+		groups: make(map[string]*errorGroup),
+		logger: l,
+	}
+}
+
+// configure starts aggregating with the given interval, or stops for an
+// interval of zero or less, reporting what was counted so far
+func (a *errorAggregator) configure(interval time.Duration, suppress bool) {
+	a.report()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.started {
+		close(a.stop)
+		a.started = false
+	}
+	a.groups = make(map[string]*errorGroup)
+	if interval <= 0 {
+		atomic.StoreInt32(&a.active, 0)
+		return
+	}
+	a.suppress = suppress
+	a.lastReport = a.logger.now()
+	a.started = true
+	a.stop = make(chan struct{})
+	go a.run(interval, a.stop)
+	atomic.StoreInt32(&a.active, 1)
+}
+
+// aggregates reports whether entries at level are counted
+func (a *errorAggregator) aggregates(level Level) bool {
+	return level <= LevelError && atomic.LoadInt32(&a.active) != 0
+}
+
+// observe counts an entry at Error or above and reports whether it should
+// still be logged. Entries carry their fingerprint once counted.
+func (a *errorAggregator) observe(level Level, entry *LogEntry) bool {
+	if !a.aggregates(level) {
+		return true
+	}
+
+	// printf-style messages are told apart by their format, not by their
+	// arguments. They are formatted here, as they would have been already
+	// if errors weren't aggregated.
+	template := entry.Message
+	if entry.format != "" {
+		template = entry.format
+	}
+	entry.resolve()
+	var caller string
+	if entry.File != "" {
+		caller = entry.File + ":" + strconv.Itoa(entry.Line)
+	}
+	key := entry.Component + "\x00" + template + "\x00" + caller
+
+	a.mu.Lock()
+	group, exists := a.groups[key]
+	if !exists {
+		if len(a.groups) >= MaxErrorGroups {
+			a.mu.Unlock()
+			return true
+		}
+		group = &errorGroup{
+			fingerprint: fingerprint(key),
+			message:     template,
+			component:   entry.Component,
+			caller:      caller,
+			level:       level,
+			first:       entry.Timestamp,
+		}
+		a.groups[key] = group
+	}
+	group.count++
+	group.total++
+	group.last = entry.Timestamp
+	if level < group.level {
+		group.level = level
+	}
+	drop := a.suppress && group.count > 1
+	if drop {
+		group.suppressed++
+	}
+	id := group.fingerprint
+	a.mu.Unlock()
+
+	if drop {
+		return false
+	}
+	entry.setField(FingerprintField, id)
+	return true
+}
+
+// run periodically reports aggregated errors until stopped or the logger is
+// closed
+func (a *errorAggregator) run(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.report()
+		case <-stop:
+			return
+		case <-a.logger.done:
+			return
+		}
+	}
+}
+
+// report logs a Warning for each error that occurred more than once since
+// the last report, most frequent first, and forgets idle groups
+func (a *errorAggregator) report() {
+	a.mu.Lock()
+	now := a.logger.now()
+	since := now.Sub(a.lastReport).Round(time.Millisecond)
+	a.lastReport = now
+	var repeated []errorGroup
+	for key, group := range a.groups {
+		switch {
+		case group.count == 0:
+			delete(a.groups, key)
+			continue
+		case group.count > 1:
+			repeated = append(repeated, *group)
+		}
+		group.count = 0
+		group.suppressed = 0
+	}
+	a.mu.Unlock()
+
+	sort.Slice(repeated, func(i, j int) bool {
+		return repeated[i].count > repeated[j].count
+	})
+	for _, group := range repeated {
+		l := a.logger
+		if group.component != "" {
+			l = l.With(group.component)
+		}
+		msg := fmt.Sprintf("Error %q occurred %d times in the last %s", group.message, group.count, since)
+		fields := []Field{
+			F(FingerprintField, group.fingerprint),
+			F("level", group.level.String()),
+			F(OccurrencesField, group.count),
+		}
+		if group.suppressed > 0 {
+			fields = append(fields, F("suppressed", group.suppressed))
+		}
+		fields = append(fields,
+			F("total", group.total),
+			F("first_seen", group.first),
+			F("last_seen", group.last),
+		)
+		if group.caller != "" {
+			fields = append(fields, F("caller", group.caller))
+		}
+		l.write(LevelWarning, 1, msg, fields)
+	}
+}
+
+// fingerprint returns a short stable ID for an aggregation key
+func fingerprint(key string) string {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// SetErrorAggregation fingerprints entries at Error and above by component,
+// message template and caller, and every interval logs a Warning for each
+// error that occurred more than once, such as
+//
+//	Error "query failed: %v" occurred 4312 times in the last 5m0s
+//
+// with its FingerprintField, OccurrencesField, "total" since first seen,
+// "first_seen", "last_seen" and "caller". Errors logged carry their
+// FingerprintField too. With suppressRepeats, only the first occurrence of
+// an error in each interval is logged and the summary counts the rest as
+// "suppressed", so long incidents don't drown other entries. Errors not seen
+// for a whole interval are forgotten. An interval of zero or less disables
+// aggregation, logging the summaries still due.
+func (l *Logger) SetErrorAggregation(interval time.Duration, suppressRepeats bool) {
+	l.aggregator.configure(interval, suppressRepeats)
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// AuditLevel is the level name carried by audit entries
+const AuditLevel = "AUDIT"
+
+// ErrNoAuditOutputs is returned by Audit when no audit output is configured
+var ErrNoAuditOutputs = errors.New("logger: no audit outputs configured")
+
+// Syncer is implemented by outputs that can commit written entries to stable
+// storage, such as FileOutput
+type Syncer interface {
+	Sync() error
+}
+
+// auditTrail holds the audit outputs shared by a logger and everything derived from it
+type auditTrail struct {
+	mu      sync.Mutex // Serializes audit writes and guards outputs
+	outputs []Output
+}
+
+func newAuditTrail() *auditTrail {
+	return &auditTrail{}
+}
+
+// AddAuditOutput adds a destination for audit entries. Audit outputs only
+// receive entries logged through Audit, never regular entries.
+func (l *Logger) AddAuditOutput(output Output) {
+	l.audit.mu.Lock()
+	defer l.audit.mu.Unlock()
+	l.audit.outputs = append(l.audit.outputs, output)
+}
+
+// Audit records a compliance event. Unlike regular logging, audit entries are
+// never filtered, sampled, rate limited or dropped: they are written
+// synchronously to every audit output and synced to stable storage (for
+// outputs implementing Syncer) before Audit returns. Secrets and sensitive
+// fields are still redacted. The returned error joins the failures of all
+// outputs; the event should be considered unrecorded if it is non-nil.
+func (l *Logger) Audit(event string, fields map[string]interface{}) error {
+	entry := &LogEntry{
+		Timestamp:  l.now(),
+		Level:      AuditLevel,
+		Message:    event,
+		Component:  l.component,
+		InstanceID: l.instanceID,
+	}
+
+	if _, file, line, ok := runtime.Caller(1); ok {
+		entry.File = filepath.Base(file)
+		entry.Line = line
+	}
+
+	l.mu.RLock()
+	if len(l.defaultFields) > 0 || len(fields) > 0 {
+		entry.Fields = make(map[string]interface{}, len(l.defaultFields)+len(fields))
+		for _, f := range l.defaultFields {
+			entry.setField(f.Key, f.Value)
+		}
+	}
+	l.mu.RUnlock()
+	entry.addFields(l.groups, fields)
+	if len(entry.Fields) > 0 && hasErrorValues(entry.Fields) {
+		encodeErrorFields(entry.Fields, l.errorEncoder())
+	}
+
+	l.redactor.Redact(entry)
+
+	l.audit.mu.Lock()
+	defer l.audit.mu.Unlock()
+
+	if len(l.audit.outputs) == 0 {
+		return ErrNoAuditOutputs
+	}
+
+	var errs []error
+	for _, output := range l.audit.outputs {
+		if err := output.Write(entry); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if syncer, ok := output.(Syncer); ok {
+			if err := syncer.Sync(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"errors"
+	"time"
+)
+
+// BufferedOutput is implemented by outputs that hold written entries back to
+// coalesce them into fewer, larger writes
+type BufferedOutput interface {
+	Output
+	// EndBatch is called by a worker after it has written a batch of
+	// entries, i.e. once per drain of the queue
+	EndBatch() error
+	// Flush writes out every entry held back
+	Flush() error
+}
+
+// DefaultBatchBytes is the write size FileOutput coalesces entries up to
+// once batching is enabled without a size
+const DefaultBatchBytes = 256 << 10
+
+// SetBatching makes the file output coalesce entries into a single write of
+// up to maxBytes per drain of the queue instead of one write per entry. With
+// a maxDelay above zero, entries may also be held across drains for up to
+// maxDelay to fill larger writes under a steady trickle. A maxBytes of zero
+// uses DefaultBatchBytes and one below zero disables batching, writing out
+// anything held back.
+func (o *FileOutput) SetBatching(maxBytes int, maxDelay time.Duration) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if maxBytes == 0 {
+		maxBytes = DefaultBatchBytes
+	}
+	if maxBytes < 0 {
+		o.batchBytes, o.batchDelay = 0, 0
+		return o.writePending()
+	}
+	o.batchBytes, o.batchDelay = maxBytes, maxDelay
+	if len(o.pending) >= maxBytes || (maxDelay <= 0 && len(o.pending) > 0) {
+		return o.writePending()
+	}
+	return nil
+}
+
+// EndBatch writes out the entries held back unless a max delay lets them
+// wait for more. It also returns the error of a delayed write since the
+// last call.
+func (o *FileOutput) EndBatch() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	err := o.delayedErr
+	o.delayedErr = nil
+	if o.batchDelay > 0 {
+		return err
+	}
+	return errors.Join(err, o.writePending())
+}
+
+// Flush writes out the entries held back
+func (o *FileOutput) Flush() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	err := o.delayedErr
+	o.delayedErr = nil
+	return errors.Join(err, o.writePending())
+}
+
+// hold appends formatted entries to the pending batch, writing it out once
+// it reaches the max batch size; o.mu must be held
+func (o *FileOutput) hold(data []byte) error {
+	o.pending = append(o.pending, data...)
+	if len(o.pending) >= o.batchBytes {
+		return o.writePending()
+	}
+	if o.batchDelay > 0 && o.timer == nil {
+		o.timer = time.AfterFunc(o.batchDelay, o.flushDelayed)
+	}
+	return nil
+}
+
+// writePending writes the pending batch with a single write; o.mu must be
+// held
+func (o *FileOutput) writePending() error {
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	if len(o.pending) == 0 {
+		return nil
+	}
+
+	n, err := o.file.Write(o.pending)
+	o.currentSize += int64(n)
+	if cap(o.pending) > 2*o.batchBytes && cap(o.pending) > maxPooledBuffer {
+		// Don't keep the memory of an unusually large batch around
+		o.pending = nil
+	} else {
+		o.pending = o.pending[:0]
+	}
+	return err
+}
+
+// flushDelayed writes out the pending batch once its max delay has passed.
+// A failure is kept for the next EndBatch or Flush to return, since no
+// logger is at hand to report it to.
+func (o *FileOutput) flushDelayed() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.timer = nil
+	if err := o.writePending(); err != nil {
+		o.delayedErr = err
+	}
+}
+
+// endBatch tells the buffered outputs among outputs that a batch is written;
+// l.out.mu must be held for reading
+func (l *Logger) endBatch(outputs []Output) {
+	for _, output := range outputs {
+		if b, ok := output.(BufferedOutput); ok {
+			if err := b.EndBatch(); err != nil {
+				l.reportError(output, err)
+			}
+		}
+	}
+}
+
+// flushOutput writes out whatever output holds back, if it is buffered;
+// the lock of the set holding it must be held for reading
+func (l *Logger) flushOutput(output Output) {
+	if b, ok := output.(BufferedOutput); ok {
+		if err := b.Flush(); err != nil {
+			l.reportError(output, err)
+		}
+	}
+}
+
+// sameOutputs reports whether a and b are the same output slice
+func sameOutputs(a, b []Output) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
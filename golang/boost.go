@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// levelBoost is an active temporary level change
+type levelBoost struct {
+	previous    Level
+	hadPrevious bool // Whether the component had its own level before
+	timer       *time.Timer
+}
+
+// boostRegistry tracks the active boosts of a logger and everything derived
+// from it, keyed by component ("" for the global level)
+type boostRegistry struct {
+	mu     sync.Mutex
+	boosts map[string]*levelBoost
+}
+
+func newBoostRegistry() *boostRegistry {
+	return &boostRegistry{boosts: make(map[string]*levelBoost)}
+}
+
+// BoostLevel temporarily sets the level of a component, or the global level
+// if component is empty, and reverts it once the duration has passed or the
+// returned cancel function is called, whichever comes first. Notice entries
+// mark the start and end of the boost. Boosting a component that is already
+// boosted supersedes the active boost, whose cancel function then does
+// nothing; the level in effect before the first boost is the one restored.
+//
+//	cancel := l.BoostLevel("db", logger.LevelDebug, 15*time.Minute)
+//	defer cancel()
+func (l *Logger) BoostLevel(component string, level Level, duration time.Duration) (cancel func()) {
+	r := l.boosts
+	r.mu.Lock()
+	boost := &levelBoost{}
+	if active, exists := r.boosts[component]; exists {
+		// Supersede the active boost, keeping the level it will restore
+		active.timer.Stop()
+		boost.previous, boost.hadPrevious = active.previous, active.hadPrevious
+	} else if component == "" {
+		boost.previous, boost.hadPrevious = l.GetLevel(), true
+	} else {
+		boost.previous, boost.hadPrevious = l.levels.get(component)
+	}
+	r.boosts[component] = boost
+
+	var once sync.Once
+	end := func() {
+		once.Do(func() { l.endBoost(component, boost) })
+	}
+	boost.timer = time.AfterFunc(duration, end)
+
+	if component == "" {
+		l.SetLevel(level)
+	} else {
+		l.SetComponentLevel(component, level)
+	}
+	r.mu.Unlock()
+
+	l.write(LevelNotice, 1, fmt.Sprintf("Level of %s boosted to %s for %s", boostTarget(component), level, duration),
+		[]Field{F("boost_component", component), F("boost_level", level.String())})
+	return end
+}
+
+// endBoost restores the level in effect before a boost, unless the boost
+// has been superseded by a newer one
+func (l *Logger) endBoost(component string, boost *levelBoost) {
+	r := l.boosts
+	r.mu.Lock()
+	if r.boosts[component] != boost {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.boosts, component)
+	boost.timer.Stop()
+
+	switch {
+	case component == "":
+		l.SetLevel(boost.previous)
+	case boost.hadPrevious:
+		l.SetComponentLevel(component, boost.previous)
+	default:
+		l.ClearComponentLevel(component)
+	}
+	r.mu.Unlock()
+
+	l.write(LevelNotice, 1, fmt.Sprintf("Level boost of %s ended", boostTarget(component)),
+		[]Field{F("boost_component", component)})
+}
+
+// boostTarget describes the target of a boost in messages
+func boostTarget(component string) string {
+	if component == "" {
+		return "all components"
+	}
+	return component
+}
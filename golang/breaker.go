@@ -0,0 +1,214 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BreakerState is the state of a BreakerOutput
+type BreakerState int32
+
+const (
+	// BreakerClosed passes every entry to the output
+	BreakerClosed BreakerState = iota
+	// BreakerOpen skips the output after repeated failures
+	BreakerOpen
+	// BreakerHalfOpen passes a single entry to the output to probe whether
+	// it recovered
+	BreakerHalfOpen
+)
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int32(s))
+	}
+}
+
+// ErrCircuitOpen is wrapped by the error a BreakerOutput returns for the
+// write that opens it
+var ErrCircuitOpen = errors.New("circuit opened")
+
+// Breaker defaults used for zero fields of a BreakerPolicy
+const (
+	DefaultBreakerFailures = 5
+	DefaultBreakerCooldown = 10 * time.Second
+)
+
+// BreakerPolicy describes when a BreakerOutput stops and resumes writing
+type BreakerPolicy struct {
+	// Failures is the number of consecutive failed writes that open the
+	// circuit (default DefaultBreakerFailures)
+	Failures int
+	// Cooldown is how long the circuit stays open before an entry is
+	// written to probe the output (default DefaultBreakerCooldown)
+	Cooldown time.Duration
+	// OnStateChange, if set, is called on every state change. err is the
+	// write error that opened the circuit, or nil. It runs on the worker
+	// writing the entry and must not log through the same logger.
+	OnStateChange func(output Output, from, to BreakerState, err error)
+}
+
+// BreakerOutput wraps an output in a circuit breaker: after repeated
+// failures it stops calling the output for a cooldown, so a dead sink doesn't
+// cost a write timeout per entry. Once the cooldown has passed, the next
+// entry is written as a probe, closing the circuit if it succeeds and
+// opening it for another cooldown if not.
+//
+// Entries skipped while the circuit is open are discarded without an error,
+// so the error handler only hears about the write that opened it; Skipped
+// counts them.
+type BreakerOutput struct {
+	output  Output
+	policy  BreakerPolicy
+	skipped atomic.Uint64
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int       // Consecutive failed writes
+	openedAt time.Time // When the circuit last opened
+	lastErr  error     // Most recent write error
+}
+
+// NewBreakerOutput returns output behind a circuit breaker following policy
+func NewBreakerOutput(output Output, policy BreakerPolicy) *BreakerOutput {
+	if policy.Failures < 1 {
+		policy.Failures = DefaultBreakerFailures
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultBreakerCooldown
+	}
+	return &BreakerOutput{output: output, policy: policy}
+}
+
+// Write writes the entry unless the circuit is open
+func (o *BreakerOutput) Write(entry *LogEntry) error {
+	o.mu.Lock()
+	switch o.state {
+	case BreakerHalfOpen:
+		// Another worker is probing
+		o.mu.Unlock()
+		o.skipped.Add(1)
+		return nil
+	case BreakerOpen:
+		if time.Since(o.openedAt) < o.policy.Cooldown {
+			o.mu.Unlock()
+			o.skipped.Add(1)
+			return nil
+		}
+		o.state = BreakerHalfOpen
+		o.mu.Unlock()
+		o.notify(BreakerOpen, BreakerHalfOpen, nil)
+	default:
+		o.mu.Unlock()
+	}
+
+	err := o.output.Write(entry)
+
+	o.mu.Lock()
+	from := o.state
+	if err == nil {
+		o.failures = 0
+		o.state = BreakerClosed
+		o.mu.Unlock()
+		if from != BreakerClosed {
+			o.notify(from, BreakerClosed, nil)
+		}
+		return nil
+	}
+
+	o.lastErr = err
+	o.failures++
+	if from == BreakerClosed && o.failures < o.policy.Failures {
+		o.mu.Unlock()
+		return err
+	}
+	o.state = BreakerOpen
+	o.openedAt = time.Now()
+	failures := o.failures
+	o.mu.Unlock()
+	o.notify(from, BreakerOpen, err)
+
+	if from == BreakerHalfOpen {
+		// The probe failed; the error handler already heard the circuit
+		// open, so don't report every failed probe
+		return nil
+	}
+	return fmt.Errorf("%w after %d failures: %w", ErrCircuitOpen, failures, err)
+}
+
+// notify reports a state change to the policy's callback
+func (o *BreakerOutput) notify(from, to BreakerState, err error) {
+	if o.policy.OnStateChange != nil {
+		o.policy.OnStateChange(o, from, to, err)
+	}
+}
+
+// State returns the current state of the circuit
+func (o *BreakerOutput) State() BreakerState {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.state
+}
+
+// LastError returns the most recent write error, or nil if the output has
+// never failed
+func (o *BreakerOutput) LastError() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.lastErr
+}
+
+// Skipped returns the number of entries discarded while the circuit was open
+func (o *BreakerOutput) Skipped() uint64 {
+	return o.skipped.Load()
+}
+
+// Close closes the wrapped output
+func (o *BreakerOutput) Close() error {
+	return o.output.Close()
+}
+
+// EndBatch ends a batch of the wrapped output if it buffers writes
+func (o *BreakerOutput) EndBatch() error {
+	if b, ok := o.output.(BufferedOutput); ok {
+		return b.EndBatch()
+	}
+	return nil
+}
+
+// Flush flushes the wrapped output if it buffers writes
+func (o *BreakerOutput) Flush() error {
+	if b, ok := o.output.(BufferedOutput); ok {
+		return b.Flush()
+	}
+	return nil
+}
+
+// Sync syncs the wrapped output if it implements Syncer
+func (o *BreakerOutput) Sync() error {
+	if s, ok := o.output.(Syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped output
+func (o *BreakerOutput) Unwrap() Output {
+	return o.output
+}
+
+// String names the output after the one it wraps
+func (o *BreakerOutput) String() string {
+	return outputName(o.output)
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// budgetSummaryInterval is the minimum time between two summaries of shed
+// entries during a sustained overload
+const budgetSummaryInterval = 10 * time.Second
+
+// logBudget caps the number of entries per second across a logger. When the
+// cap is approached, less severe entries are shed first: each level may only
+// use a share of the budget, from all of it for Warning and above down to half
+// of it for Trace.
+type logBudget struct {
+	active      int32 // Atomic, non-zero when a limit is set
+	mu          sync.Mutex
+	limit       int // Entries per second, zero disables the budget
+	windowStart time.Time
+	used        int
+	shedInWin   bool             // Whether the current window shed anything
+	shed        map[Level]uint64 // Entries shed since the last summary
+	shedSince   time.Time        // Start of the period covered by shed
+	lastSummary time.Time
+	logger      *Logger // Root logger the summaries are written to
+}
+
+func newLogBudget(l *Logger) *logBudget {
+	return &logBudget{
+		shed:   make(map[Level]uint64),
+		logger: l,
+	}
+}
+
+// budgetShare returns the fraction of the budget entries at a level may use
+func budgetShare(level Level) float64 {
+	if level <= LevelWarning {
+		return 1
+	}
+	share := 1 - 0.1*float64(level-LevelWarning)
+	if share < 0.5 {
+		share = 0.5
+	}
+	return share
+}
+
+// allow reports whether an entry at the given level fits in the budget.
+// Exempt entries are always allowed but still use up the budget.
+func (b *logBudget) allow(level Level, exempt bool) bool {
+	if atomic.LoadInt32(&b.active) == 0 {
+		return true
+	}
+
+	b.mu.Lock()
+
+	if b.limit <= 0 {
+		b.mu.Unlock()
+		return true
+	}
+
+	now := b.logger.now()
+	var summary func()
+	if now.Sub(b.windowStart) >= time.Second {
+		// Report once the overload is over, or periodically while it lasts
+		if len(b.shed) > 0 && (!b.shedInWin || now.Sub(b.lastSummary) >= budgetSummaryInterval) {
+			summary = b.takeSummaryLocked(now)
+		}
+		b.windowStart = now
+		b.used = 0
+		b.shedInWin = false
+	}
+
+	allowed := exempt || float64(b.used) < float64(b.limit)*budgetShare(level)
+	if allowed {
+		b.used++
+	} else {
+		if len(b.shed) == 0 {
+			b.shedSince = now
+		}
+		b.shed[level]++
+		b.shedInWin = true
+	}
+	b.mu.Unlock()
+
+	if summary != nil {
+		summary()
+	}
+	return allowed
+}
+
+// takeSummaryLocked resets the shed counters and returns a function writing
+// the Notice that reports them; b.mu must be held
+func (b *logBudget) takeSummaryLocked(now time.Time) func() {
+	var total uint64
+	byLevel := make(map[string]interface{}, len(b.shed))
+	for level, count := range b.shed {
+		byLevel[level.String()] = count
+		total += count
+	}
+	msg := fmt.Sprintf("Log budget of %d entries/s exceeded, shed %d entries in the last %s",
+		b.limit, total, now.Sub(b.shedSince).Round(time.Millisecond))
+
+	b.shed = make(map[Level]uint64)
+	b.lastSummary = now
+
+	return func() {
+		b.logger.write(LevelNotice, 1, msg, []Field{F("shed", byLevel)})
+	}
+}
+
+// flush writes the summary of entries shed so far, if any
+func (b *logBudget) flush() {
+	b.mu.Lock()
+	var summary func()
+	if len(b.shed) > 0 {
+		summary = b.takeSummaryLocked(b.logger.now())
+	}
+	b.mu.Unlock()
+
+	if summary != nil {
+		summary()
+	}
+}
+
+// SetLogBudget caps the number of entries written per second across this
+// logger and every logger derived from it. When the cap is approached the
+// least severe entries are shed first, and a single Notice summarizes what
+// was shed. Entries exempt from sampling (see SetNeverSampleLevel) are never
+// shed. A limit of zero or less removes the cap.
+func (l *Logger) SetLogBudget(perSecond int) {
+	l.budget.mu.Lock()
+	defer l.budget.mu.Unlock()
+	l.budget.limit = perSecond
+	if perSecond > 0 {
+		atomic.StoreInt32(&l.budget.active, 1)
+	} else {
+		atomic.StoreInt32(&l.budget.active, 0)
+	}
+}
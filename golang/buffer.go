@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"strconv"
+	"sync"
+)
+
+// maxPooledBuffer caps the capacity of buffers kept for reuse, so an
+// occasional huge entry doesn't pin a large buffer in the pool
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds byte slices that formatters and outputs serialize entries
+// into
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns a buffer to the pool unless it has grown too large. The
+// caller must not use it afterwards.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+	*buf = (*buf)[:0]
+	bufferPool.Put(buf)
+}
+
+// ANSI escape sequences used by colored text output
+const (
+	colorReset = "\033[0m"
+	colorGray  = "\033[90m"
+)
+
+// levelColor returns the ANSI color used for a level name
+func levelColor(level string) string {
+	switch level {
+	case "EMERG", "ALERT", "CRIT":
+		return "\033[1;31m" // Bold Red
+	case "ERROR":
+		return "\033[31m" // Red
+	case "WARN":
+		return "\033[33m" // Yellow
+	case "NOTICE":
+		return "\033[1;34m" // Bold Blue
+	case "INFO":
+		return "\033[32m" // Green
+	case "DEBUG":
+		return "\033[36m" // Cyan
+	case "VERB", "TRACE":
+		return "\033[35m" // Magenta
+	default:
+		return colorReset
+	}
+}
+
+// AppendEntry appends the entry as a line in the given format, as a
+// FileOutput writes it: text without colors, or one JSON object
+func AppendEntry(buf []byte, entry *LogEntry, format OutputFormat) ([]byte, error) {
+	if format == FormatJSON {
+		data, err := appendEntryJSON(buf, entry)
+		if err != nil {
+			return buf, err
+		}
+		return append(data, '\n'), nil
+	}
+	return appendTextEntry(buf, entry, false), nil
+}
+
+// appendTextEntry appends an entry as a human-readable line, optionally with
+// ANSI colors, followed by a newline:
+//
+//	2024-01-02 15:04:05.000 [INFO] (component) [file.go:42] message {"key":"value"}
+func appendTextEntry(buf []byte, entry *LogEntry, colored bool) []byte {
+	buf = textTimes.appendTime(buf, entry.Timestamp)
+	buf = append(buf, " ["...)
+	if colored {
+		buf = append(buf, levelColor(entry.Level)...)
+		buf = append(buf, entry.Level...)
+		buf = append(buf, colorReset...)
+	} else {
+		buf = append(buf, entry.Level...)
+	}
+	buf = append(buf, ']')
+	if entry.Component != "" {
+		buf = append(buf, " ("...)
+		buf = append(buf, entry.Component...)
+		buf = append(buf, ')')
+	}
+	if entry.File != "" {
+		buf = append(buf, ' ')
+		if colored {
+			buf = append(buf, colorGray...)
+		}
+		buf = append(buf, '[')
+		buf = append(buf, entry.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(entry.Line), 10)
+		buf = append(buf, ']')
+		if colored {
+			buf = append(buf, colorReset...)
+		}
+	}
+	buf = append(buf, ' ')
+	buf = append(buf, entry.Message...)
+	if len(entry.Fields) > 0 {
+		buf = append(buf, ' ')
+		if colored {
+			buf = append(buf, colorGray...)
+		}
+		buf, _ = appendFieldsJSON(buf, entry)
+		if colored {
+			buf = append(buf, colorReset...)
+		}
+	}
+	return append(buf, '\n')
+}
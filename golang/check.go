@@ -0,0 +1,56 @@
+package logger
+
+import "sync"
+
+// CheckedEntry is a log entry that has passed the level, rate limit and
+// budget checks of its logger but has not been written yet (see Check)
+type CheckedEntry struct {
+	logger *Logger
+	level  Level
+	msg    string
+}
+
+var checkedPool = sync.Pool{
+	New: func() interface{} { return new(CheckedEntry) },
+}
+
+// Enabled reports whether entries at level would pass the level checks of
+// this logger, taking its component and a context level into account
+func (l *Logger) Enabled(level Level) bool {
+	return l.isLoggable(level, l.component)
+}
+
+// Check returns an entry to be completed with Write when a message at level
+// would be logged, or nil otherwise. It lets callers skip building
+// expensive fields for disabled levels:
+//
+//	if ce := log.Check(LevelDebug, "state"); ce != nil {
+//	    ce.Write(map[string]interface{}{"dump": state.Dump()})
+//	}
+//
+// Rate limits and the log budget are charged by Check, so an entry that
+// is checked should be written.
+func (l *Logger) Check(level Level, msg string) *CheckedEntry {
+	if !l.allow(level) {
+		return nil
+	}
+
+	ce := checkedPool.Get().(*CheckedEntry)
+	ce.logger, ce.level, ce.msg = l, level, msg
+	return ce
+}
+
+// Write logs the checked entry with the given fields. The entry is recycled,
+// so Write must be called at most once and the entry not used afterwards.
+// Writing a nil entry does nothing.
+func (ce *CheckedEntry) Write(fields ...map[string]interface{}) {
+	if ce == nil {
+		return
+	}
+
+	l, level, msg := ce.logger, ce.level, ce.msg
+	*ce = CheckedEntry{}
+	checkedPool.Put(ce)
+
+	l.write(level, 1, msg, nil, fields...)
+}
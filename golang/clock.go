@@ -0,0 +1,49 @@
+package logger
+
+import "time"
+
+// Clock supplies the current time for entry timestamps and for the time
+// windows of sampling, rate limiting and the log budget. Tests can plug in a
+// fake clock for deterministic output; deployments can plug in a
+// specialized time source.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+// Now calls f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the default Clock, reading the system time
+var SystemClock Clock = ClockFunc(time.Now)
+
+// clockBox wraps a Clock so it can be stored in an atomic.Value
+type clockBox struct {
+	clock Clock
+}
+
+// SetClock sets the clock of this logger and every logger derived from it.
+// Passing nil restores SystemClock.
+func (l *Logger) SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock
+	}
+	l.settings.clock.Store(clockBox{clock: c})
+}
+
+// Clock returns the clock in use
+func (l *Logger) Clock() Clock {
+	if box, ok := l.settings.clock.Load().(clockBox); ok {
+		return box.clock
+	}
+	return SystemClock
+}
+
+// now returns the current time according to the logger's clock
+func (l *Logger) now() time.Time {
+	return l.Clock().Now()
+}
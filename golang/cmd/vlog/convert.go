@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/hemant-mann/logger/golang/vlogconv"
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+// runConvert implements "vlog convert"
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("vlog convert", flag.ContinueOnError)
+	var read readFlags
+	read.register(fs)
+	to := fs.String("to", "", "format to write: json, text, logfmt or msgpack")
+	output := fs.String("o", "", "file to write, standard output by default")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: vlog convert -to FORMAT [flags] [FILE...]\n\nConverts the entries of the files, or standard input, to FORMAT.\n\n"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, err := vlogread.ParseFormat(*to)
+	if err != nil {
+		return err
+	}
+	if format == vlogread.FormatAuto {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	opts, err := read.options()
+	if err != nil {
+		return err
+	}
+
+	var dst io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		dst = f
+	}
+
+	return eachInput(fs.Args(), func(src io.Reader) error {
+		_, err := vlogconv.Convert(dst, src, format, opts)
+		return err
+	})
+}
+
+// eachInput calls fn with each of the named files in turn, or with standard
+// input if there are none
+func eachInput(names []string, fn func(io.Reader) error) error {
+	if len(names) == 0 {
+		return fn(os.Stdin)
+	}
+	var errs []error
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := fn(f); err != nil {
+			errs = append(errs, err)
+		}
+		f.Close()
+	}
+	return errors.Join(errs...)
+}
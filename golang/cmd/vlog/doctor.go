@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// runDoctor implements "vlog doctor"
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("vlog doctor", flag.ContinueOnError)
+	config := fs.String("config", "", "logger configuration file to check (required)")
+	timeout := fs.Duration("timeout", 10*time.Second, "how long to wait for outputs to answer")
+	asJSON := fs.Bool("json", false, "print the diagnosis as JSON")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: vlog doctor -config FILE [flags]\n\nChecks that a logger built from the configuration can write its entries:\nthat file paths are writable and can rotate, and that outputs answer.\n\n"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *config == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	report := diagnose(*config, *timeout)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(report)
+	}
+	if !report.OK {
+		return fmt.Errorf("%s has problems that lose entries", *config)
+	}
+	return nil
+}
+
+// diagnose loads the configuration, builds the logger and runs its
+// self-test, reporting the first step that fails as a failed check
+func diagnose(path string, timeout time.Duration) logger.SelfTestReport {
+	check := logger.SelfTestCheck{Check: "config " + path, Status: logger.CheckOK}
+	config, err := logger.LoadConfig(path)
+	if err == nil {
+		var l *logger.Logger
+		if l, err = config.Build(); err == nil {
+			defer l.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			report := l.SelfTest(ctx)
+			report.Checks = append([]logger.SelfTestCheck{check}, report.Checks...)
+			return report
+		}
+	}
+	check.Status, check.Detail = logger.CheckFailed, err.Error()
+	return logger.SelfTestReport{Checks: []logger.SelfTestCheck{check}}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+// runIndex implements "vlog index"
+func runIndex(args []string) error {
+	fs := flag.NewFlagSet("vlog index", flag.ContinueOnError)
+	format := fs.String("format", "auto", "format of the logs: auto, json, text, logfmt or msgpack")
+	chunk := fs.Int64("chunk", vlogread.DefaultIndexChunkSize, "approximate size of the chunks indexed, in bytes")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: vlog index [flags] FILE...\n\nIndexes log files that no longer change, such as rotated files, for vlog query.\n\n"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	parsed, err := vlogread.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	opts := vlogread.IndexOptions{Options: vlogread.Options{Format: parsed}, ChunkSize: *chunk}
+	var errs []error
+	for _, name := range fs.Args() {
+		idx, err := vlogread.IndexFile(name, opts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d chunks\n", name+vlogread.IndexSuffix, len(idx.Chunks))
+	}
+	return errors.Join(errs...)
+}
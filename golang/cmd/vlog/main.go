@@ -0,0 +1,180 @@
+// Command vlog works with log files written by the logger package.
+//
+// Usage:
+//
+//	vlog <command> [flags] [files]
+//
+// The commands are:
+//
+//	convert convert logs between text, JSON, logfmt and MessagePack
+//	doctor  check that a logger configuration can write its entries
+//	index   index rotated log files so queries skip what they don't select
+//	query   select entries across a log file and the files rotated from it
+//	replay  write recorded entries to the outputs of a logger configuration
+//	tail    follow a log file as it is written, across rotations
+//
+// Run "vlog <command> -h" for the flags of a command.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+// command is a vlog subcommand
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+// commands maps subcommand names to their implementation
+var commands = map[string]command{
+	"convert": {"convert logs between text, JSON, logfmt and MessagePack", runConvert},
+	"doctor":  {"check that a logger configuration can write its entries", runDoctor},
+	"index":   {"index rotated log files so queries skip what they don't select", runIndex},
+	"query":   {"select entries across a log file and the files rotated from it", runQuery},
+	"replay":  {"write recorded entries to the outputs of a logger configuration", runReplay},
+	"tail":    {"follow a log file as it is written, across rotations", runTail},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "vlog: unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "vlog %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// usage lists the commands
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: vlog <command> [flags] [files]\n\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].summary)
+	}
+}
+
+// fieldFlags collects repeated -field key=value flags
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldFlags) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("want key=value, got %q", s)
+	}
+	f[key] = value
+	return nil
+}
+
+// readFlags are the flags selecting and parsing entries, shared by the
+// commands reading logs
+type readFlags struct {
+	format    string
+	level     string
+	component string
+	contains  string
+	fields    fieldFlags
+}
+
+// register adds the flags to fs
+func (r *readFlags) register(fs *flag.FlagSet) {
+	r.fields = fieldFlags{}
+	fs.StringVar(&r.format, "format", "auto", "format of the log: auto, json, text, logfmt or msgpack")
+	fs.StringVar(&r.level, "level", "", "only show entries at this level or more severe")
+	fs.StringVar(&r.component, "component", "", "only show entries of this component, its descendants, or matching this glob")
+	fs.StringVar(&r.contains, "grep", "", "only show entries whose message contains this string")
+	fs.Var(r.fields, "field", "only show entries with this field value, as key=value (repeatable)")
+}
+
+// options returns the reader options the flags describe
+func (r *readFlags) options() (vlogread.Options, error) {
+	opts := vlogread.Options{
+		Filter: vlogread.Filter{
+			Component: r.component,
+			Contains:  r.contains,
+			Fields:    r.fields,
+		},
+		OnError: func(err *vlogread.LineError) {
+			fmt.Fprintf(os.Stderr, "vlog: skipping %v\n", err)
+		},
+	}
+	format, err := vlogread.ParseFormat(r.format)
+	if err != nil {
+		return opts, err
+	}
+	opts.Format = format
+	if r.level != "" {
+		level, err := logger.ParseLevel(r.level)
+		if err != nil {
+			return opts, err
+		}
+		opts.Filter.Level = &level
+	}
+	return opts, nil
+}
+
+// printer writes entries to standard output
+type printer struct {
+	w      *bufio.Writer
+	format logger.OutputFormat
+}
+
+// newPrinter returns a printer writing JSON entries if json is set, text
+// otherwise
+func newPrinter(w io.Writer, json bool) *printer {
+	p := &printer{w: bufio.NewWriter(w), format: logger.FormatText}
+	if json {
+		p.format = logger.FormatJSON
+	}
+	return p
+}
+
+// print writes an entry
+func (p *printer) print(entry *logger.LogEntry) error {
+	data, err := logger.AppendEntry(nil, entry, p.format)
+	if err != nil {
+		return err
+	}
+	_, err = p.w.Write(data)
+	return err
+}
+
+// flush writes out the buffered entries
+func (p *printer) flush() error {
+	return p.w.Flush()
+}
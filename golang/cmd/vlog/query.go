@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+// runQuery implements "vlog query"
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("vlog query", flag.ContinueOnError)
+	var read readFlags
+	read.register(fs)
+	since := fs.String("since", "", "only show entries logged at or after this time, or this long ago, e.g. 2h")
+	until := fs.String("until", "", "only show entries logged before this time, or this long ago")
+	limit := fs.Int("limit", 0, "stop after this many entries")
+	rotated := fs.Bool("rotated", true, "also query the files rotated from each FILE, oldest first")
+	noIndex := fs.Bool("noindex", false, "read files whole, ignoring their indexes")
+	stats := fs.Bool("stats", false, "print how many files and chunks were read to standard error")
+	json := fs.Bool("json", false, "print entries as JSON instead of text")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: vlog query [flags] FILE...\n\nPrints the entries of the files, and the files rotated from them, that the flags select.\nIndexed files (see vlog index) only have the chunks that can hold such entries read.\n\n"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	opts, err := read.options()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if opts.Filter.Since, err = parseTime(*since, now); err != nil {
+		return fmt.Errorf("-since: %w", err)
+	}
+	if opts.Filter.Until, err = parseTime(*until, now); err != nil {
+		return fmt.Errorf("-until: %w", err)
+	}
+
+	var paths []string
+	for _, name := range fs.Args() {
+		if *rotated {
+			paths = append(paths, vlogread.RotatedFiles(name)...)
+		}
+		paths = append(paths, name)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	out := newPrinter(os.Stdout, *json)
+	query := vlogread.Query{Options: opts, Limit: *limit, NoIndex: *noIndex}
+	result, err := query.Run(ctx, paths, func(_ string, entry *logger.LogEntry) error {
+		return out.print(entry)
+	})
+	if flushErr := out.flush(); err == nil {
+		err = flushErr
+	}
+	if *stats {
+		fmt.Fprintf(os.Stderr, "%d entries from %d files, %d indexed: %d chunks read, %d skipped\n",
+			result.Entries, result.Files, result.Indexed, result.ChunksRead, result.ChunksSkipped)
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// timeLayouts are the layouts parseTime accepts, besides durations
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTime parses a time, in local time unless it has a zone, or a
+// duration before now. It returns the zero time for "".
+func parseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, want e.g. 2006-01-02 15:04:05 or 2h", s)
+}
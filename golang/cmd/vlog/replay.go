@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+// runReplay implements "vlog replay"
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("vlog replay", flag.ContinueOnError)
+	var read readFlags
+	read.register(fs)
+	config := fs.String("config", "", "logger configuration file whose outputs receive the entries (required)")
+	speed := fs.Float64("speed", 0, "pace entries as logged, this many times faster; 0 replays them at once")
+	maxGap := fs.Duration("max-gap", 0, "longest wait between two paced entries, 0 for no limit")
+	retime := fs.Bool("retime", false, "stamp entries with the time they are replayed")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: vlog replay -config FILE [flags] [FILE...]\n\nWrites the entries of the files, or standard input, to the outputs the configuration describes.\n\n"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *config == "" {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	opts, err := read.options()
+	if err != nil {
+		return err
+	}
+
+	l, err := logger.NewFromConfig(*config)
+	if err != nil {
+		return err
+	}
+	// A backfill waits for the outputs rather than dropping entries
+	l.SetOverflowPolicy(logger.OverflowBlock, 0)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	total := 0
+	err = eachInput(fs.Args(), func(src io.Reader) error {
+		n, err := vlogread.Replay(ctx, l, vlogread.NewReader(src, opts), vlogread.ReplayOptions{
+			Speed:  *speed,
+			MaxGap: *maxGap,
+			Retime: *retime,
+		})
+		total += n
+		return err
+	})
+	if closeErr := l.Close(); err == nil {
+		err = closeErr
+	}
+	fmt.Fprintf(os.Stderr, "replayed %d entries\n", total)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+// runTail implements "vlog tail"
+func runTail(args []string) error {
+	fs := flag.NewFlagSet("vlog tail", flag.ContinueOnError)
+	var read readFlags
+	read.register(fs)
+	fromStart := fs.Bool("all", false, "show the entries already in the file first")
+	json := fs.Bool("json", false, "print entries as JSON instead of text")
+	poll := fs.Duration("poll", vlogread.DefaultPollInterval, "how often to check the file for new entries")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: vlog tail [flags] FILE\n\nFollows FILE as it is written and rotated, like tail -F.\n\n"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	opts, err := read.options()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	tailer := vlogread.NewTailer(fs.Arg(0), vlogread.TailOptions{
+		Options:      opts,
+		FromStart:    *fromStart,
+		PollInterval: *poll,
+	})
+	defer tailer.Close()
+
+	out := newPrinter(os.Stdout, *json)
+	for tailer.Next(ctx) {
+		if err := out.print(tailer.Entry()); err != nil {
+			return err
+		}
+		if err := out.flush(); err != nil {
+			return err
+		}
+	}
+	if err := tailer.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
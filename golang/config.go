@@ -0,0 +1,389 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat identifies the syntax of a configuration file
+type ConfigFormat string
+
+const (
+	// ConfigJSON is a JSON configuration file
+	ConfigJSON ConfigFormat = "json"
+	// ConfigYAML is a YAML configuration file
+	ConfigYAML ConfigFormat = "yaml"
+	// ConfigTOML is a TOML configuration file
+	ConfigTOML ConfigFormat = "toml"
+)
+
+// Config declaratively describes a fully configured Logger.
+//
+// Example (YAML):
+//
+//	level: info
+//	components:
+//	  db.*: debug
+//	  http: warn
+//	outputs:
+//	  - type: console
+//	    format: text
+//	  - type: file
+//	    format: json
+//	    path: /var/log/app.log
+//	    max_size_mb: 100
+//	sampling:
+//	  cache-miss: 100
+//	component_sampling:
+//	  http.access: 10
+//	level_sampling:
+//	  debug: 50
+//	fields:
+//	  service: checkout
+//	caller: false
+type Config struct {
+	// Level is the global log level, e.g. "info" (default "info")
+	Level string `json:"level" yaml:"level" toml:"level"`
+	// Components maps component names or glob patterns to levels
+	Components map[string]string `json:"components" yaml:"components" toml:"components"`
+	// Outputs lists the destinations entries are written to
+	Outputs []OutputConfig `json:"outputs" yaml:"outputs" toml:"outputs"`
+	// Sampling maps sampling keys to rates, overriding the call-site rates
+	Sampling map[string]int `json:"sampling" yaml:"sampling" toml:"sampling"`
+	// ComponentSampling maps components to rates at which all their entries,
+	// and those of their descendants, are sampled
+	ComponentSampling map[string]int `json:"component_sampling" yaml:"component_sampling" toml:"component_sampling"`
+	// LevelSampling maps level names to rates at which all entries at the
+	// level are sampled
+	LevelSampling map[string]int `json:"level_sampling" yaml:"level_sampling" toml:"level_sampling"`
+	// Fields are default fields added to every entry
+	Fields map[string]interface{} `json:"fields" yaml:"fields" toml:"fields"`
+	// Caller enables or disables capturing the caller's file and line
+	// (default enabled)
+	Caller *bool `json:"caller" yaml:"caller" toml:"caller"`
+	// Development enables development mode, in which DPanic panics
+	Development *bool `json:"development" yaml:"development" toml:"development"`
+}
+
+// OutputConfig describes a single output
+type OutputConfig struct {
+	// Type is "console" or "file"
+	Type string `json:"type" yaml:"type" toml:"type"`
+	// Format is "text" (default) or "json"
+	Format string `json:"format" yaml:"format" toml:"format"`
+	// Stream selects "stdout" (default) or "stderr" for console outputs
+	Stream string `json:"stream" yaml:"stream" toml:"stream"`
+	// Path is the log file of file outputs
+	Path string `json:"path" yaml:"path" toml:"path"`
+	// MaxSizeMB is the size at which file outputs rotate, 0 disables rotation
+	MaxSizeMB int `json:"max_size_mb" yaml:"max_size_mb" toml:"max_size_mb"`
+	// Rotation is "rename" or "copytruncate", defaulting to copytruncate
+	// on Windows and rename elsewhere
+	Rotation string `json:"rotation" yaml:"rotation" toml:"rotation"`
+	// BatchKB coalesces the entries of file outputs into writes of up to
+	// this size, 0 writes every entry on its own
+	BatchKB int `json:"batch_kb" yaml:"batch_kb" toml:"batch_kb"`
+	// BatchDelayMS is how long file outputs may hold entries back to fill
+	// a batch, 0 writes the batch once the queue is drained
+	BatchDelayMS int `json:"batch_delay_ms" yaml:"batch_delay_ms" toml:"batch_delay_ms"`
+	// Components routes these components, and their descendants, to this
+	// output only, and no other entries (see RouteComponent). Outputs
+	// listing the same component share its entries.
+	Components []string `json:"components" yaml:"components" toml:"components"`
+}
+
+// configFormatFromPath guesses the configuration format from a file extension
+func configFormatFromPath(path string) (ConfigFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ConfigJSON, nil
+	case ".yaml", ".yml":
+		return ConfigYAML, nil
+	case ".toml":
+		return ConfigTOML, nil
+	default:
+		return "", fmt.Errorf("cannot determine config format of %s", path)
+	}
+}
+
+// LoadConfig reads a configuration file, choosing the format by its extension
+// (.json, .yaml, .yml or .toml)
+func LoadConfig(path string) (*Config, error) {
+	format, err := configFormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config, err := ParseConfig(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+// ParseConfig reads a configuration in the given format. Unknown keys are
+// rejected so typos don't go unnoticed.
+func ParseConfig(r io.Reader, format ConfigFormat) (*Config, error) {
+	config := &Config{}
+
+	switch format {
+	case ConfigJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(config); err != nil {
+			return nil, err
+		}
+	case ConfigYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(config); err != nil && err != io.EOF {
+			return nil, err
+		}
+	case ConfigTOML:
+		md, err := toml.NewDecoder(r).Decode(config)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("unknown config key %q", undecoded[0].String())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the configuration for invalid levels and outputs
+func (c *Config) Validate() error {
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			return err
+		}
+	}
+	for name, level := range c.Components {
+		if _, err := ParseLevel(level); err != nil {
+			return fmt.Errorf("component %s: %v", name, err)
+		}
+	}
+	for name := range c.ComponentSampling {
+		if name == "" {
+			return fmt.Errorf("component sampling: empty component")
+		}
+	}
+	for name := range c.LevelSampling {
+		if _, err := ParseLevel(name); err != nil {
+			return fmt.Errorf("level sampling: %v", err)
+		}
+	}
+	for i, out := range c.Outputs {
+		if _, err := parseOutputFormat(out.Format); err != nil {
+			return fmt.Errorf("output %d: %v", i, err)
+		}
+		for _, component := range out.Components {
+			if component == "" {
+				return fmt.Errorf("output %d: empty component", i)
+			}
+		}
+		switch out.Type {
+		case "console":
+			if out.Stream != "" && out.Stream != "stdout" && out.Stream != "stderr" {
+				return fmt.Errorf("output %d: unknown stream %q", i, out.Stream)
+			}
+		case "file":
+			if out.Path == "" {
+				return fmt.Errorf("output %d: file output requires a path", i)
+			}
+			if out.Rotation != "" {
+				if _, err := ParseRotationMode(out.Rotation); err != nil {
+					return fmt.Errorf("output %d: %v", i, err)
+				}
+			}
+		default:
+			return fmt.Errorf("output %d: unknown output type %q", i, out.Type)
+		}
+	}
+	return nil
+}
+
+// scopedSampling returns the component and level sampling policies of the
+// configuration, nil for a missing section. Rates below 1 are left out.
+func (c *Config) scopedSampling() (map[string]SamplingPolicy, map[Level]SamplingPolicy) {
+	var components map[string]SamplingPolicy
+	if c.ComponentSampling != nil {
+		components = make(map[string]SamplingPolicy, len(c.ComponentSampling))
+		for name, rate := range c.ComponentSampling {
+			if rate >= 1 {
+				components[name] = ratePolicy(rate)
+			}
+		}
+	}
+	var levels map[Level]SamplingPolicy
+	if c.LevelSampling != nil {
+		levels = make(map[Level]SamplingPolicy, len(c.LevelSampling))
+		for name, rate := range c.LevelSampling {
+			level, err := ParseLevel(name)
+			if err == nil && rate >= 1 {
+				levels[level] = ratePolicy(rate)
+			}
+		}
+	}
+	return components, levels
+}
+
+// parseOutputFormat converts a format name into an OutputFormat
+func parseOutputFormat(name string) (OutputFormat, error) {
+	switch strings.ToLower(name) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return 0, fmt.Errorf("unknown output format %q", name)
+	}
+}
+
+// buildOutputs creates the configured outputs, closing any already created
+// ones if a later one fails
+func (c *Config) buildOutputs() ([]Output, error) {
+	outputs := make([]Output, 0, len(c.Outputs))
+	for _, out := range c.Outputs {
+		format, err := parseOutputFormat(out.Format)
+		if err != nil {
+			closeOutputs(outputs)
+			return nil, err
+		}
+
+		switch out.Type {
+		case "console":
+			writer := os.Stdout
+			if out.Stream == "stderr" {
+				writer = os.Stderr
+			}
+			outputs = append(outputs, NewConsoleOutput(writer, format))
+		case "file":
+			fileOutput, err := NewFileOutput(out.Path, format, out.MaxSizeMB)
+			if err != nil {
+				closeOutputs(outputs)
+				return nil, err
+			}
+			if out.Rotation != "" {
+				mode, _ := ParseRotationMode(out.Rotation)
+				fileOutput.SetRotationMode(mode)
+			}
+			if out.BatchKB > 0 {
+				fileOutput.SetBatching(out.BatchKB<<10, time.Duration(out.BatchDelayMS)*time.Millisecond)
+			}
+			outputs = append(outputs, fileOutput)
+		default:
+			closeOutputs(outputs)
+			return nil, fmt.Errorf("unknown output type %q", out.Type)
+		}
+	}
+	return outputs, nil
+}
+
+// routeOutputs splits the outputs built for the configuration into the
+// outputs of the logger and the routes of components
+func (c *Config) routeOutputs(outputs []Output) ([]Output, map[string][]Output) {
+	var list []Output
+	var routes map[string][]Output
+	for i, out := range c.Outputs {
+		if len(out.Components) == 0 {
+			list = append(list, outputs[i])
+			continue
+		}
+		if routes == nil {
+			routes = make(map[string][]Output)
+		}
+		for _, component := range out.Components {
+			routes[component] = append(routes[component], outputs[i])
+		}
+	}
+	return list, routes
+}
+
+// closeOutputs closes every output, ignoring errors
+func closeOutputs(outputs []Output) {
+	for _, output := range outputs {
+		output.Close()
+	}
+}
+
+// Build creates a new Logger configured as described
+func (c *Config) Build() (*Logger, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	outputs, err := c.buildOutputs()
+	if err != nil {
+		return nil, err
+	}
+
+	l := NewLogger()
+	if c.Level != "" {
+		level, _ := ParseLevel(c.Level)
+		l.SetLevel(level)
+	}
+	for name, value := range c.Components {
+		level, _ := ParseLevel(value)
+		l.SetComponentLevel(name, level)
+	}
+	for key, rate := range c.Sampling {
+		l.SetSamplingRate(key, rate)
+	}
+	l.sampler.replaceScoped(c.scopedSampling())
+	for key, value := range c.Fields {
+		l.SetDefaultField(key, value)
+	}
+	if c.Caller != nil {
+		l.SetCallerEnabled(*c.Caller)
+	}
+	if c.Development != nil {
+		l.SetDevelopment(*c.Development)
+	}
+	list, routes := c.routeOutputs(outputs)
+	for _, output := range list {
+		l.AddOutput(output)
+	}
+	for component, route := range routes {
+		l.RouteComponent(component, route...)
+	}
+	return l, nil
+}
+
+// NewFromConfig creates a Logger from a configuration file (see LoadConfig)
+func NewFromConfig(path string) (*Logger, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return config.Build()
+}
+
+// NewFromReader creates a Logger from a configuration in the given format
+func NewFromReader(r io.Reader, format ConfigFormat) (*Logger, error) {
+	config, err := ParseConfig(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return config.Build()
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+// contextKey is the type of keys for values this package stores in contexts
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+	minLevelKey
+	tenantKey
+)
+
+// NewContext returns a copy of ctx carrying the given logger
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger if
+// there is none
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return GetLogger()
+}
+
+// ContextExtractor returns fields to add to entries logged with a context,
+// such as the trace and span IDs of the active span. It returns nil if the
+// context holds nothing of interest.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// extractorRegistry holds the context extractors shared by a logger and
+// everything derived from it
+type extractorRegistry struct {
+	mu         sync.RWMutex
+	extractors []ContextExtractor
+}
+
+func newExtractorRegistry() *extractorRegistry {
+	return &extractorRegistry{}
+}
+
+// add appends an extractor
+func (r *extractorRegistry) add(fn ContextExtractor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Copy on write so extract can iterate without holding the lock
+	extractors := make([]ContextExtractor, len(r.extractors), len(r.extractors)+1)
+	copy(extractors, r.extractors)
+	r.extractors = append(extractors, fn)
+}
+
+// extract runs every extractor on ctx and returns the non-empty results
+func (r *extractorRegistry) extract(ctx context.Context) []map[string]interface{} {
+	r.mu.RLock()
+	extractors := r.extractors
+	r.mu.RUnlock()
+
+	var results []map[string]interface{}
+	for _, fn := range extractors {
+		if fields := fn(ctx); len(fields) > 0 {
+			results = append(results, fields)
+		}
+	}
+	return results
+}
+
+// AddContextExtractor registers fn to enrich entries logged via a logger
+// bound to a context (see WithContext). Extractors run when an entry is
+// written, after the level check, and are shared with every derived logger.
+func (l *Logger) AddContextExtractor(fn ContextExtractor) {
+	l.extractors.add(fn)
+}
+
+// WithContext creates a logger bound to ctx. Entries logged through it are
+// enriched by the registered context extractors and carry ctx in
+// LogEntry.Context for hooks and filters, and a minimum level carried by
+// ctx (see WithMinLevel) applies to it.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	newLogger := l.WithFields(nil)
+	newLogger.ctx = ctx
+	newLogger.ctxLevel, newLogger.hasCtxLevel = MinLevelFromContext(ctx)
+	return newLogger
+}
+
+// WithMinLevel returns a copy of ctx that makes loggers bound to it (see
+// WithContext and Ctx) log everything at the given level or more severe,
+// regardless of the global and component levels. This allows debugging a
+// single request at Trace while other traffic stays at Info:
+//
+//	if r.Header.Get("X-Debug") == debugToken {
+//		ctx = logger.WithMinLevel(ctx, logger.LevelTrace)
+//	}
+//
+// It only ever adds verbosity; entries that the configured levels let
+// through are logged as usual.
+func WithMinLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, minLevelKey, level)
+}
+
+// MinLevelFromContext returns the level set by WithMinLevel, if any
+func MinLevelFromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(minLevelKey).(Level)
+	return level, ok
+}
+
+// Ctx returns the logger carried by ctx (see FromContext) bound to ctx, the
+// usual way to log from request handlers:
+//
+//	logger.Ctx(r.Context()).Info("Loading cart")
+func Ctx(ctx context.Context) *Logger {
+	return FromContext(ctx).WithContext(ctx)
+}
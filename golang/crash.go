@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// CrashFlushTimeout bounds how long a crash or Exit waits for the final
+// entries to be written, so a hung output can't keep a dying process alive
+const CrashFlushTimeout = 5 * time.Second
+
+// ExitFunc ends the process with the given status code, like os.Exit
+type ExitFunc func(code int)
+
+// exitFuncBox wraps an ExitFunc so it can be stored in an atomic.Value
+type exitFuncBox struct {
+	fn ExitFunc
+}
+
+// SetExitFunc sets the function Exit and Fatal end the process with, e.g. to
+// run cleanup first or to keep tests alive. It is shared with every logger
+// derived from l. nil restores os.Exit.
+func (l *Logger) SetExitFunc(fn ExitFunc) {
+	l.lifecycle.exit.Store(exitFuncBox{fn})
+}
+
+// Exit writes the queued entries, syncs and closes the outputs, then ends
+// the process with code through the exit function (see SetExitFunc). Writing
+// the final entries may take up to CrashFlushTimeout.
+func (l *Logger) Exit(code int) {
+	l.closeForExit()
+	if box, ok := l.lifecycle.exit.Load().(exitFuncBox); ok && box.fn != nil {
+		box.fn(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// Fatal logs a message at emergency level, then ends the process with
+// status 1 once the entry is written (see Exit)
+func (l *Logger) Fatal(msg string, fields ...map[string]interface{}) {
+	l.log(LevelEmergency, 1, msg, fields...)
+	l.Exit(1)
+}
+
+// Fatalf logs a formatted message at emergency level, then ends the process
+// with status 1 once the entry is written (see Exit)
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.logf(LevelEmergency, 1, format, args...)
+	l.Exit(1)
+}
+
+// closeForExit closes the logger within CrashFlushTimeout, reporting
+// failures to stderr as nothing will log them afterwards
+func (l *Logger) closeForExit() {
+	ctx, cancel := context.WithTimeout(context.Background(), CrashFlushTimeout)
+	defer cancel()
+	if err := l.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Final log entries may be lost: %v\n", err)
+	}
+}
+
+// InstallCrashHandler returns a function that keeps the final entries of l
+// (the default logger if nil) when the process crashes. Defer its result at
+// the top of main and of goroutines that may panic:
+//
+//	func main() {
+//	    defer logger.InstallCrashHandler(l)()
+//	    ...
+//	}
+//
+// On a panic it writes a crash dump if enabled (see SetCrashDumpDir), logs
+// the panic with its stack trace at emergency level, writes the queued
+// entries, syncs and closes the outputs, and panics again with the same
+// value, so the process still crashes as it would have.
+func InstallCrashHandler(l *Logger) func() {
+	if l == nil {
+		l = GetLogger()
+	}
+	return func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			fields := map[string]interface{}{
+				"panic": fmt.Sprint(r),
+				"stack": string(stack),
+			}
+			if err, ok := r.(error); ok {
+				fields[ErrorFieldKey] = err
+			}
+			// The dump comes first, as it doesn't depend on the outputs
+			if dir := l.crashDumpDir(); dir != "" {
+				path, err := l.writeCrashDump(dir, r, stack)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: Failed to write crash dump: %v\n", err)
+				}
+				if path != "" {
+					fields[CrashDumpField] = path
+				}
+			}
+			l.write(LevelEmergency, 1, fmt.Sprintf("Unrecovered panic: %v", r), nil, fields)
+			l.closeForExit()
+			panic(r)
+		}
+	}
+}
+
+// Fatal logs a message to the default logger at emergency level, then ends
+// the process with status 1 (see Logger.Fatal)
+func Fatal(msg string, fields ...map[string]interface{}) {
+	l := GetLogger()
+	l.log(LevelEmergency, 1, msg, fields...)
+	l.Exit(1)
+}
+
+// Fatalf logs a formatted message to the default logger at emergency level,
+// then ends the process with status 1 (see Logger.Fatal)
+func Fatalf(format string, args ...interface{}) {
+	l := GetLogger()
+	l.logf(LevelEmergency, 1, format, args...)
+	l.Exit(1)
+}
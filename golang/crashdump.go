@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// MaxCrashStackBytes bounds the goroutine stacks written to a crash dump
+const MaxCrashStackBytes = 16 << 20
+
+// CrashDumpField is the field of the crash entry holding the path of the
+// crash dump, see SetCrashDumpDir
+const CrashDumpField = "crash_dump"
+
+// SetCrashDumpDir has the crash handler (see InstallCrashHandler) write a
+// crash dump into dir before anything else when it catches a panic, so a
+// postmortem has context even if the final entries never reach the outputs.
+// The dump is a text file named after the program, the time and the process
+// ID, such as
+//
+//	api-crash-20240102T150405.000Z-4242.log
+//
+// holding the panic and its stack, runtime statistics, the entries of the
+// flight recorder (see SetFlightRecorder) and the stacks of every goroutine,
+// up to MaxCrashStackBytes. Its path is logged with the panic in
+// CrashDumpField. The directory is created if needed. It is shared with
+// every logger derived from l; an empty dir, the default, writes no dump.
+func (l *Logger) SetCrashDumpDir(dir string) {
+	l.lifecycle.crashDir.Store(dir)
+}
+
+// crashDumpDir returns the directory crash dumps are written to, if any
+func (l *Logger) crashDumpDir() string {
+	dir, _ := l.lifecycle.crashDir.Load().(string)
+	return dir
+}
+
+// writeCrashDump writes a crash dump for a panic with the given value and
+// stack, returning its path
+func (l *Logger) writeCrashDump(dir string, value interface{}, stack []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-crash-%s-%d.log", filepath.Base(os.Args[0]), now.Format("20060102T150405.000Z"), os.Getpid())
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "Crash of %s (pid %d) at %s\n", os.Args[0], os.Getpid(), now.Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "Go %s %s/%s, instance %s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, l.instanceID)
+	fmt.Fprintf(w, "panic: %v\n\n%s\n", value, stack)
+
+	fmt.Fprintf(w, "== Runtime ==\n")
+	for _, field := range runtimeStats() {
+		fmt.Fprintf(w, "%s: %v\n", field.Key, field.Value)
+	}
+
+	var recorded []byte
+	count := 0
+	l.recorder.each(func(entry *LogEntry) {
+		recorded, _ = AppendEntry(recorded, entry, FormatText)
+		count++
+	})
+	fmt.Fprintf(w, "\n== Flight recorder (%d entries) ==\n", count)
+	w.Write(recorded)
+
+	stacks, truncated := goroutineStacks(MaxCrashStackBytes)
+	fmt.Fprintf(w, "\n== Goroutines ==\n%s\n", stacks)
+	if truncated {
+		fmt.Fprintf(w, "... stacks cut at %d bytes\n", MaxCrashStackBytes)
+	}
+
+	err = w.Flush()
+	if syncErr := f.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return path, err
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RepeatCountField is the field holding how many identical entries were
+// collapsed into a summary entry by deduplication
+const RepeatCountField = "repeat_count"
+
+// deduplicator collapses identical consecutive entries (same level, component
+// and message) seen within a time window. The first entry is passed through
+// immediately; repeats are counted and reported as a single summary entry,
+// a copy of the last repeat carrying RepeatCountField, once a different entry
+// arrives or the window ends.
+type deduplicator struct {
+	active   int32 // Atomic, non-zero when deduplicating
+	mu       sync.Mutex
+	window   time.Duration // Zero disables deduplication
+	emit     func(*LogEntry)
+	last     *LogEntry // Most recent suppressed repeat
+	lastKey  string
+	started  time.Time // Timestamp of the entry that opened the window
+	repeats  int
+	timer    *time.Timer
+	timerGen int // Incremented whenever the window closes, to ignore stale timers
+}
+
+func newDeduplicator(emit func(*LogEntry)) *deduplicator {
+	return &deduplicator{emit: emit}
+}
+
+// setWindow changes the deduplication window, flushing pending repeats
+func (d *deduplicator) setWindow(window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.flushLocked()
+	d.lastKey = ""
+	d.window = window
+	if window > 0 {
+		atomic.StoreInt32(&d.active, 1)
+	} else {
+		atomic.StoreInt32(&d.active, 0)
+	}
+}
+
+// process emits the entry unless it repeats the previous one within the window
+func (d *deduplicator) process(entry *LogEntry) {
+	if atomic.LoadInt32(&d.active) == 0 {
+		d.emit(entry)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.window <= 0 {
+		d.emit(entry)
+		return
+	}
+
+	entry.resolve()
+	key := entry.Level + "\x00" + entry.Component + "\x00" + entry.Message
+	if key == d.lastKey && entry.Timestamp.Sub(d.started) < d.window {
+		releaseEntry(d.last)
+		d.last = entry
+		d.repeats++
+		if d.repeats == 1 {
+			gen := d.timerGen
+			d.timer = time.AfterFunc(d.window-entry.Timestamp.Sub(d.started), func() {
+				d.expire(gen)
+			})
+		}
+		return
+	}
+
+	d.flushLocked()
+	d.lastKey = key
+	d.started = entry.Timestamp
+	d.emit(entry)
+}
+
+// expire closes the window opened for the given generation
+func (d *deduplicator) expire(gen int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if gen != d.timerGen {
+		return
+	}
+	d.flushLocked()
+	d.lastKey = ""
+}
+
+// flush emits the summary of any pending repeats
+func (d *deduplicator) flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.flushLocked()
+	d.lastKey = ""
+}
+
+// flushLocked emits the summary of pending repeats; d.mu must be held
+func (d *deduplicator) flushLocked() {
+	d.timerGen++
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeats == 0 {
+		return
+	}
+
+	summary := *d.last
+	summary.Fields = make(map[string]interface{}, len(d.last.Fields)+1)
+	for k, v := range d.last.Fields {
+		summary.Fields[k] = v
+	}
+	summary.keys = slices.Clone(d.last.keys)
+	summary.Fields[RepeatCountField] = d.repeats
+
+	releaseEntry(d.last)
+	d.last = nil
+	d.repeats = 0
+	d.emit(&summary)
+}
+
+// SetDeduplication enables collapsing of identical consecutive entries (same
+// level, component and message) logged within the given window. The first
+// entry is written immediately and the repeats are reported as one entry with
+// a repeat_count field. A window of zero disables deduplication.
+func (l *Logger) SetDeduplication(window time.Duration) {
+	l.dedup.setWindow(window)
+}
@@ -0,0 +1,181 @@
+package echolog
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// echoLogger adapts a Logger to the echo.Logger interface
+type echoLogger struct {
+	logger *logger.Logger
+	prefix string
+}
+
+// NewLogger returns an echo.Logger writing through l, for use as e.Logger.
+// Echo's levels map onto the closest logger levels; Fatal logs at Emergency
+// and exits, Panic logs at Critical and panics. Output and header settings
+// are ignored as the logger's outputs decide the format.
+func NewLogger(l *logger.Logger) echo.Logger {
+	// Report the callers of the adapter methods
+	return &echoLogger{logger: l.WithCallerSkip(1)}
+}
+
+// Output returns a writer logging each line at Info level
+func (e *echoLogger) Output() io.Writer {
+	return e.logger.Writer(logger.LevelInfo)
+}
+
+// SetOutput is ignored; configure the logger's outputs instead
+func (e *echoLogger) SetOutput(w io.Writer) {}
+
+// SetHeader is ignored; configure the logger's outputs instead
+func (e *echoLogger) SetHeader(h string) {}
+
+func (e *echoLogger) Prefix() string {
+	return e.prefix
+}
+
+func (e *echoLogger) SetPrefix(p string) {
+	e.prefix = p
+}
+
+// Level returns the Echo level closest to the logger's global level
+func (e *echoLogger) Level() log.Lvl {
+	switch level := e.logger.GetLevel(); {
+	case level >= logger.LevelDebug:
+		return log.DEBUG
+	case level >= logger.LevelNotice:
+		return log.INFO
+	case level >= logger.LevelWarning:
+		return log.WARN
+	default:
+		return log.ERROR
+	}
+}
+
+// SetLevel sets the logger's global level to the closest match of an Echo level
+func (e *echoLogger) SetLevel(v log.Lvl) {
+	switch v {
+	case log.DEBUG:
+		e.logger.SetLevel(logger.LevelDebug)
+	case log.INFO:
+		e.logger.SetLevel(logger.LevelInfo)
+	case log.WARN:
+		e.logger.SetLevel(logger.LevelWarning)
+	case log.ERROR:
+		e.logger.SetLevel(logger.LevelError)
+	case log.OFF:
+		e.logger.SetLevel(logger.LevelEmergency)
+	}
+}
+
+// message prepends the prefix, if any, to a message
+func (e *echoLogger) message(msg string) string {
+	if e.prefix == "" {
+		return msg
+	}
+	return e.prefix + " " + msg
+}
+
+func (e *echoLogger) Print(i ...interface{}) {
+	e.logger.Info(e.message(fmt.Sprint(i...)))
+}
+
+func (e *echoLogger) Printf(format string, args ...interface{}) {
+	e.logger.Info(e.message(fmt.Sprintf(format, args...)))
+}
+
+func (e *echoLogger) Printj(j log.JSON) {
+	e.logger.Info(e.message(""), j)
+}
+
+func (e *echoLogger) Debug(i ...interface{}) {
+	e.logger.Debug(e.message(fmt.Sprint(i...)))
+}
+
+func (e *echoLogger) Debugf(format string, args ...interface{}) {
+	e.logger.Debug(e.message(fmt.Sprintf(format, args...)))
+}
+
+func (e *echoLogger) Debugj(j log.JSON) {
+	e.logger.Debug(e.message(""), j)
+}
+
+func (e *echoLogger) Info(i ...interface{}) {
+	e.logger.Info(e.message(fmt.Sprint(i...)))
+}
+
+func (e *echoLogger) Infof(format string, args ...interface{}) {
+	e.logger.Info(e.message(fmt.Sprintf(format, args...)))
+}
+
+func (e *echoLogger) Infoj(j log.JSON) {
+	e.logger.Info(e.message(""), j)
+}
+
+func (e *echoLogger) Warn(i ...interface{}) {
+	e.logger.Warning(e.message(fmt.Sprint(i...)))
+}
+
+func (e *echoLogger) Warnf(format string, args ...interface{}) {
+	e.logger.Warning(e.message(fmt.Sprintf(format, args...)))
+}
+
+func (e *echoLogger) Warnj(j log.JSON) {
+	e.logger.Warning(e.message(""), j)
+}
+
+func (e *echoLogger) Error(i ...interface{}) {
+	e.logger.Error(e.message(fmt.Sprint(i...)))
+}
+
+func (e *echoLogger) Errorf(format string, args ...interface{}) {
+	e.logger.Error(e.message(fmt.Sprintf(format, args...)))
+}
+
+func (e *echoLogger) Errorj(j log.JSON) {
+	e.logger.Error(e.message(""), j)
+}
+
+func (e *echoLogger) Fatal(i ...interface{}) {
+	e.fatal(fmt.Sprint(i...), nil)
+}
+
+func (e *echoLogger) Fatalf(format string, args ...interface{}) {
+	e.fatal(fmt.Sprintf(format, args...), nil)
+}
+
+func (e *echoLogger) Fatalj(j log.JSON) {
+	e.fatal("", j)
+}
+
+func (e *echoLogger) Panic(i ...interface{}) {
+	e.panic(fmt.Sprint(i...), nil)
+}
+
+func (e *echoLogger) Panicf(format string, args ...interface{}) {
+	e.panic(fmt.Sprintf(format, args...), nil)
+}
+
+func (e *echoLogger) Panicj(j log.JSON) {
+	e.panic("", j)
+}
+
+// fatal logs at Emergency, flushes the logger and exits the process
+func (e *echoLogger) fatal(msg string, fields map[string]interface{}) {
+	e.logger.WithCallerSkip(1).Emergency(e.message(msg), fields)
+	e.logger.Close()
+	os.Exit(1)
+}
+
+// panic logs at Critical and panics with the message
+func (e *echoLogger) panic(msg string, fields map[string]interface{}) {
+	msg = e.message(msg)
+	e.logger.WithCallerSkip(1).Critical(msg, fields)
+	panic(msg)
+}
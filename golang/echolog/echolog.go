@@ -0,0 +1,125 @@
+// Package echolog provides Echo middleware that logs requests and recovers
+// panics through a logger, and an echo.Logger adapter, replacing Echo's
+// own logger and its Logger and Recover middleware so a process has a
+// single log format.
+//
+//	e := echo.New()
+//	e.Logger = echolog.NewLogger(l)
+//	e.Use(echolog.Middleware(l), echolog.Recovery(l))
+package echolog
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/labstack/echo/v4"
+)
+
+// ContextKey is the echo context key under which Middleware stores the
+// request-scoped logger
+const ContextKey = "vlog.logger"
+
+// Middleware logs every request once it has been handled, with its method,
+// path, route, status, latency, client IP, response size and any error
+// returned by the handler. Requests answered with 5xx are logged at Error,
+// 4xx at Warning and everything else at Info.
+//
+// The request ID is taken from the incoming headers or generated (see
+// logger.RequestIDFromHeader) and echoed in the X-Request-ID response header.
+// A request-scoped child logger carrying the method, path and request ID is
+// stored in the echo context (see FromContext) and in the request's context
+// (see logger.FromContext) for handlers to log through.
+func Middleware(l *logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+
+			id := logger.RequestIDFromHeader(req.Header)
+			if id == "" {
+				id = logger.NewRequestID()
+			}
+			c.Response().Header().Set(logger.RequestIDHeader, id)
+
+			ctx := logger.NewContext(req.Context(), l.WithFields(map[string]interface{}{
+				"method": req.Method,
+				"path":   req.URL.Path,
+			}))
+			ctx = logger.WithRequestID(ctx, id)
+			reqLogger := logger.FromContext(ctx)
+			c.Set(ContextKey, reqLogger)
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				// Let the error handler write the response so the status is known
+				c.Error(err)
+			}
+
+			res := c.Response()
+			fields := map[string]interface{}{
+				"status":     res.Status,
+				"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+				"client_ip":  c.RealIP(),
+				"bytes":      res.Size,
+			}
+			if route := c.Path(); route != "" {
+				fields["route"] = route
+			}
+			if err != nil {
+				fields[logger.ErrorFieldKey] = err.Error()
+			}
+
+			msg := fmt.Sprintf("%s %s %d", req.Method, req.URL.Path, res.Status)
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				reqLogger.Error(msg, fields)
+			case res.Status >= http.StatusBadRequest:
+				reqLogger.Warning(msg, fields)
+			default:
+				reqLogger.Info(msg, fields)
+			}
+			return nil
+		}
+	}
+}
+
+// Recovery recovers panics in later handlers, logs them at Critical with the
+// panic value and stack trace, and passes an error to Echo's error handler so
+// the client receives a 500. Register it after Middleware so the panic is
+// logged through the request-scoped logger and the request itself is still
+// logged with its 500 status.
+func Recovery(l *logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler {
+					// Deliberate abort of the response, let net/http handle it
+					panic(r)
+				}
+				FromContext(c, l).Critical(fmt.Sprintf("Panic recovered: %v", r), map[string]interface{}{
+					"panic": fmt.Sprint(r),
+					"stack": string(debug.Stack()),
+				})
+				err = echo.NewHTTPError(http.StatusInternalServerError)
+			}()
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns the request-scoped logger stored by Middleware, or
+// fallback if there is none
+func FromContext(c echo.Context, fallback *logger.Logger) *logger.Logger {
+	if l, ok := c.Get(ContextKey).(*logger.Logger); ok {
+		return l
+	}
+	return fallback
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldEncoder converts a field value into the value that is logged, e.g. a
+// time.Duration into milliseconds or a protobuf message into compact JSON
+type FieldEncoder func(value interface{}) interface{}
+
+// fieldEncoders is the set of registered encoders. It is replaced as a whole
+// on registration so formatters can read it without locking.
+type fieldEncoders struct {
+	types      map[reflect.Type]FieldEncoder // Concrete types
+	interfaces []interfaceEncoder            // Interface types, in registration order
+}
+
+// interfaceEncoder is a FieldEncoder for all types implementing an interface
+type interfaceEncoder struct {
+	iface  reflect.Type
+	encode FieldEncoder
+}
+
+var (
+	encodersMu sync.Mutex
+	encoders   atomic.Value // *fieldEncoders
+)
+
+// RegisterFieldEncoder registers an encoder for field values of the given
+// type, used by every output and format. If typ is an interface type, the
+// encoder applies to all values implementing it that have no encoder for
+// their concrete type. Encoders apply to field values and to values nested
+// in maps and slices of fields, but not to struct members.
+//
+//	logger.RegisterFieldEncoder(reflect.TypeOf(time.Duration(0)), func(v interface{}) interface{} {
+//		return v.(time.Duration).Milliseconds()
+//	})
+//	logger.RegisterFieldEncoder(reflect.TypeOf((*proto.Message)(nil)).Elem(), func(v interface{}) interface{} {
+//		data, _ := protojson.Marshal(v.(proto.Message))
+//		return json.RawMessage(data)
+//	})
+func RegisterFieldEncoder(typ reflect.Type, enc FieldEncoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	next := &fieldEncoders{types: make(map[reflect.Type]FieldEncoder)}
+	if current, ok := encoders.Load().(*fieldEncoders); ok {
+		for t, e := range current.types {
+			next.types[t] = e
+		}
+		next.interfaces = append(next.interfaces, current.interfaces...)
+	}
+
+	if typ.Kind() == reflect.Interface {
+		for i, ie := range next.interfaces {
+			if ie.iface == typ {
+				next.interfaces = append(next.interfaces[:i:i], next.interfaces[i+1:]...)
+				break
+			}
+		}
+		next.interfaces = append(next.interfaces, interfaceEncoder{iface: typ, encode: enc})
+	} else {
+		next.types[typ] = enc
+	}
+	encoders.Store(next)
+}
+
+// encoderFor finds the encoder of a value, nil if there is none
+func (e *fieldEncoders) encoderFor(value interface{}) FieldEncoder {
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		return nil
+	}
+	if enc, ok := e.types[typ]; ok {
+		return enc
+	}
+	for _, ie := range e.interfaces {
+		if typ.Implements(ie.iface) {
+			return ie.encode
+		}
+	}
+	return nil
+}
+
+// currentEncoders returns the registered encoders, nil if there are none
+func currentEncoders() *fieldEncoders {
+	e, _ := encoders.Load().(*fieldEncoders)
+	return e
+}
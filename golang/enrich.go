@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	hostFieldsOnce  sync.Once
+	hostFields      map[string]interface{}
+	buildFieldsOnce sync.Once
+	buildFields     map[string]interface{}
+)
+
+// HostFields returns metadata about the host and process: "host" (hostname),
+// "pid", "executable" (binary name), "go_version", "os" and "arch". The
+// values are computed once per process; the returned map is a copy.
+func HostFields() map[string]interface{} {
+	hostFieldsOnce.Do(func() {
+		hostFields = map[string]interface{}{
+			"pid":        os.Getpid(),
+			"go_version": runtime.Version(),
+			"os":         runtime.GOOS,
+			"arch":       runtime.GOARCH,
+		}
+		if host, err := os.Hostname(); err == nil {
+			hostFields["host"] = host
+		}
+		if exe, err := os.Executable(); err == nil {
+			hostFields["executable"] = filepath.Base(exe)
+		} else if len(os.Args) > 0 {
+			hostFields["executable"] = filepath.Base(os.Args[0])
+		}
+	})
+
+	return copyFields(hostFields)
+}
+
+// AddHostFields adds the host and process metadata of HostFields to the
+// default fields of this logger, so every entry identifies the host and
+// binary that produced it
+func (l *Logger) AddHostFields() {
+	for k, v := range HostFields() {
+		l.SetDefaultField(k, v)
+	}
+}
+
+// BuildFields returns information about the build of the running binary, as
+// recorded by the Go toolchain: "version" (main module version),
+// "vcs_revision", "vcs_time" and "vcs_modified" (uncommitted changes at build
+// time). Values that were not recorded, e.g. for binaries built outside a VCS
+// checkout, are omitted. The returned map is a copy.
+func BuildFields() map[string]interface{} {
+	buildFieldsOnce.Do(func() {
+		buildFields = make(map[string]interface{})
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			buildFields["version"] = v
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				buildFields["vcs_revision"] = setting.Value
+			case "vcs.time":
+				buildFields["vcs_time"] = setting.Value
+			case "vcs.modified":
+				buildFields["vcs_modified"] = setting.Value == "true"
+			}
+		}
+	})
+	return copyFields(buildFields)
+}
+
+// AddBuildFields adds the build information of BuildFields to the default
+// fields of this logger, so every entry identifies the exact build that
+// produced it
+func (l *Logger) AddBuildFields() {
+	for k, v := range BuildFields() {
+		l.SetDefaultField(k, v)
+	}
+}
+
+// copyFields returns a shallow copy of a field map
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		c[k] = v
+	}
+	return c
+}
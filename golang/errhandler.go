@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorHandler is called when the logging pipeline fails, such as an output
+// write, a hook or closing the outputs. output is the output that failed, or
+// nil if the failure isn't tied to one. It runs on the goroutine that hit
+// the failure, usually a worker, so it should return quickly. Entries it
+// logs through the same logger are dropped rather than waiting for room in
+// the queue, and may fail again in turn, so it is best not to.
+type ErrorHandler func(output Output, err error)
+
+// errorHandlerBox wraps an ErrorHandler so it can be stored in an
+// atomic.Value
+type errorHandlerBox struct {
+	fn ErrorHandler
+}
+
+// Errors reported by the default handler, at most errorBurst per
+// errorInterval; the rest are counted and summarized
+const (
+	errorBurst    = 5
+	errorInterval = 10 * time.Second
+)
+
+// errorReporting holds the error handler shared by a logger and everything
+// derived from it, since they share the outputs it reports on
+type errorReporting struct {
+	handler  atomic.Value // errorHandlerBox, DefaultErrorHandler if unset
+	fallback ErrorHandler
+}
+
+func newErrorReporting() *errorReporting {
+	return &errorReporting{fallback: DefaultErrorHandler()}
+}
+
+// DefaultErrorHandler returns the handler loggers use unless one is set: it
+// writes failures to stderr, at most 5 every 10 seconds, and reports how many
+// were left out with the next one written. Each call returns a handler with
+// its own limit, so a custom handler can fall back to it:
+//
+//	stderr := logger.DefaultErrorHandler()
+//	l.SetErrorHandler(func(o logger.Output, err error) {
+//	    logFailures.Inc()
+//	    stderr(o, err)
+//	})
+func DefaultErrorHandler() ErrorHandler {
+	var (
+		mu          sync.Mutex
+		windowStart time.Time
+		reported    int
+		suppressed  int
+	)
+	return func(output Output, err error) {
+		mu.Lock()
+		now := time.Now()
+		if now.Sub(windowStart) >= errorInterval {
+			windowStart, reported = now, 0
+		}
+		if reported >= errorBurst {
+			suppressed++
+			mu.Unlock()
+			return
+		}
+		reported++
+		skipped := suppressed
+		suppressed = 0
+		mu.Unlock()
+
+		if skipped > 0 {
+			fmt.Fprintf(os.Stderr, "ERROR: %d more logging errors suppressed\n", skipped)
+		}
+		if output != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Log output %s failed: %v\n", outputName(output), err)
+		} else {
+			fmt.Fprintf(os.Stderr, "ERROR: Logging failed: %v\n", err)
+		}
+	}
+}
+
+// SetErrorHandler sets the function called when writing to an output, a
+// hook or closing the outputs fails, e.g. to count failures or switch to a
+// fallback output. It is shared with every logger derived from l. nil
+// restores the default, which writes rate-limited messages to stderr.
+func (l *Logger) SetErrorHandler(fn ErrorHandler) {
+	l.errs.handler.Store(errorHandlerBox{fn})
+}
+
+// reportError passes a pipeline failure to the error handler
+func (l *Logger) reportError(output Output, err error) {
+	if box, ok := l.errs.handler.Load().(errorHandlerBox); ok && box.fn != nil {
+		box.fn(output, err)
+		return
+	}
+	l.errs.fallback(output, err)
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// ErrorFieldKey is the field name under which WithError records errors
+const ErrorFieldKey = "error"
+
+// ErrorEncoder converts errors found in field values into the value that is
+// logged, e.g. a map of structured details
+type ErrorEncoder func(err error) interface{}
+
+// errorEncoderBox wraps an ErrorEncoder so it can be stored in an atomic.Value
+type errorEncoderBox struct {
+	encode ErrorEncoder
+}
+
+// EncodeError is the default ErrorEncoder. It records the error's message,
+// concrete type, the causes found by unwrapping it (including every branch
+// of joined errors) and, when an error in the chain carries one, the stack
+// trace of where the error originated. Errors implementing a
+// StackTrace() method, such as those of github.com/pkg/errors, provide
+// their stack this way.
+//
+//	{"message": "load config: open app.yaml: no such file or directory",
+//	 "type": "*fmt.wrapError",
+//	 "causes": [{"message": "open app.yaml: ...", "type": "*fs.PathError"}, ...],
+//	 "stack": "..."}
+func EncodeError(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return encodeError(err, "")
+}
+
+// SetErrorEncoder sets how errors in field values are logged by this logger
+// and every logger derived from it. Passing nil logs errors as their message.
+func (l *Logger) SetErrorEncoder(enc ErrorEncoder) {
+	l.settings.errorEncoder.Store(errorEncoderBox{encode: enc})
+}
+
+// errorEncoder returns the configured ErrorEncoder, nil if disabled
+func (l *Logger) errorEncoder() ErrorEncoder {
+	if box, ok := l.settings.errorEncoder.Load().(errorEncoderBox); ok {
+		return box.encode
+	}
+	return EncodeError
+}
+
+// WithError creates a new logger with the given error recorded as a structured field
+func (l *Logger) WithError(err error) *Logger {
+	return l.WithField(ErrorFieldKey, err)
+}
+
+// WithErrorStack is like WithError but also records the stack trace of the caller
+func (l *Logger) WithErrorStack(err error) *Logger {
+	return l.WithField(ErrorFieldKey, encodeError(err, callerStack(1)))
+}
+
+// encodeErrorFields replaces errors among the field values with their encoded
+// form. Nested maps holding errors are copied rather than modified, as they
+// may be shared with the logger's default fields.
+func encodeErrorFields(fields map[string]interface{}, enc ErrorEncoder) {
+	for k, v := range fields {
+		switch v := v.(type) {
+		case error:
+			if enc != nil {
+				fields[k] = enc(v)
+			} else {
+				fields[k] = v.Error()
+			}
+		case map[string]interface{}:
+			if hasErrorValues(v) {
+				nested := make(map[string]interface{}, len(v))
+				for nk, nv := range v {
+					nested[nk] = nv
+				}
+				encodeErrorFields(nested, enc)
+				fields[k] = nested
+			}
+		}
+	}
+}
+
+// hasErrorValues reports whether a field map holds errors at any depth
+func hasErrorValues(fields map[string]interface{}) bool {
+	for _, v := range fields {
+		switch v := v.(type) {
+		case error:
+			return true
+		case map[string]interface{}:
+			if hasErrorValues(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// encodeError converts an error into a structured field value holding its
+// message, concrete type, causes and a stack trace. The given stack is used
+// if non-empty, otherwise the stack carried by the innermost error in the
+// chain that has one.
+func encodeError(err error, stack string) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+
+	fields := map[string]interface{}{
+		"message": err.Error(),
+		"type":    fmt.Sprintf("%T", err),
+	}
+
+	// Walk the wrapped chain so each cause is visible on its own
+	var causes []interface{}
+	errStack := errorStack(err)
+	walkCauses(err, func(cause error) {
+		causes = append(causes, map[string]interface{}{
+			"message": cause.Error(),
+			"type":    fmt.Sprintf("%T", cause),
+		})
+		if s := errorStack(cause); s != "" {
+			errStack = s
+		}
+	})
+	if len(causes) > 0 {
+		fields["causes"] = causes
+	}
+
+	if stack == "" {
+		stack = errStack
+	}
+	if stack != "" {
+		fields["stack"] = stack
+	}
+
+	return fields
+}
+
+// walkCauses calls fn for every error wrapped by err, depth first, following
+// both Unwrap() error and the Unwrap() []error of joined errors
+func walkCauses(err error, fn func(error)) {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			fn(cause)
+			walkCauses(cause, fn)
+		}
+	case interface{ Unwrap() []error }:
+		for _, cause := range e.Unwrap() {
+			if cause != nil {
+				fn(cause)
+				walkCauses(cause, fn)
+			}
+		}
+	}
+}
+
+// errorStack returns the stack trace carried by an error implementing a
+// StackTrace() method, formatted with %+v unless it already is a string.
+// The method is looked up by name since stack trace types differ between
+// error packages.
+func errorStack(err error) string {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+	trace := method.Call(nil)[0].Interface()
+	if s, ok := trace.(string); ok {
+		return s
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", trace), "\n")
+}
+
+// callerStack formats the stack of the calling goroutine, skipping the given
+// number of frames above callerStack itself
+func callerStack(skip int) string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
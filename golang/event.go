@@ -0,0 +1,257 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventField carries the name of the events logged with Logger.Event
+const EventField = "event"
+
+// EventErrorField carries the reason an event doesn't match its schema
+const EventErrorField = "event_error"
+
+// FieldType is the type an event schema requires of a field
+type FieldType int
+
+const (
+	// FieldAny accepts any value but nil
+	FieldAny FieldType = iota
+	// FieldString accepts strings
+	FieldString
+	// FieldInt accepts signed and unsigned integers
+	FieldInt
+	// FieldFloat accepts integers and floating-point numbers
+	FieldFloat
+	// FieldBool accepts booleans
+	FieldBool
+	// FieldTime accepts time.Time values
+	FieldTime
+	// FieldDuration accepts time.Duration values
+	FieldDuration
+)
+
+// String returns the name of the type, as used in schema errors
+func (t FieldType) String() string {
+	switch t {
+	case FieldAny:
+		return "any"
+	case FieldString:
+		return "string"
+	case FieldInt:
+		return "int"
+	case FieldFloat:
+		return "float"
+	case FieldBool:
+		return "bool"
+	case FieldTime:
+		return "time"
+	case FieldDuration:
+		return "duration"
+	default:
+		return fmt.Sprintf("FieldType(%d)", int(t))
+	}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// accepts reports whether value has the type
+func (t FieldType) accepts(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	v := reflect.ValueOf(value)
+	switch t {
+	case FieldAny:
+		return true
+	case FieldString:
+		return v.Kind() == reflect.String
+	case FieldInt:
+		return isInteger(v.Kind()) && v.Type() != durationType
+	case FieldFloat:
+		return (isInteger(v.Kind()) && v.Type() != durationType) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+	case FieldBool:
+		return v.Kind() == reflect.Bool
+	case FieldTime:
+		return v.Type() == timeType
+	case FieldDuration:
+		return v.Type() == durationType
+	}
+	return false
+}
+
+// isInteger reports whether kind is a signed or unsigned integer kind
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// EventSchema describes the fields of a named event, see RegisterEvent
+type EventSchema struct {
+	// Name names the event, e.g. "user.signup"
+	Name string
+	// Required lists the fields every event must carry, with their types
+	Required map[string]FieldType
+	// Optional lists the fields events may carry, with their types
+	Optional map[string]FieldType
+	// Strict rejects fields that are neither required nor optional
+	Strict bool
+}
+
+// Validate checks fields against the schema, returning an *EventError
+// listing every problem found
+func (s *EventSchema) Validate(fields map[string]interface{}) error {
+	var problems []string
+	for key, typ := range s.Required {
+		value, ok := fields[key]
+		if !ok {
+			problems = append(problems, "missing field "+key)
+		} else if !typ.accepts(value) {
+			problems = append(problems, fmt.Sprintf("field %s: want %s, got %T", key, typ, value))
+		}
+	}
+	for key, value := range fields {
+		if _, ok := s.Required[key]; ok {
+			continue
+		}
+		typ, ok := s.Optional[key]
+		if !ok {
+			if s.Strict {
+				problems = append(problems, "unknown field "+key)
+			}
+			continue
+		}
+		if !typ.accepts(value) {
+			problems = append(problems, fmt.Sprintf("field %s: want %s, got %T", key, typ, value))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return &EventError{Event: s.Name, Problems: problems}
+}
+
+// EventError is returned for an event that doesn't match its schema
+type EventError struct {
+	Event    string
+	Problems []string
+}
+
+// Error lists the problems of the event
+func (e *EventError) Error() string {
+	return fmt.Sprintf("event %s: %s", e.Event, strings.Join(e.Problems, "; "))
+}
+
+// ErrUnknownEvent is returned by Event for names without a registered schema
+var ErrUnknownEvent = errors.New("logger: unknown event")
+
+// eventSchemas holds the registered schemas. It is replaced as a whole on
+// registration, so logging reads it without locking.
+var (
+	eventSchemasMu sync.Mutex
+	eventSchemas   atomic.Value // map[string]*EventSchema
+)
+
+// RegisterEvent registers the schema of an event, typically from an init
+// function, so that Logger.Event can check the events logged by its name:
+//
+//	logger.RegisterEvent(logger.EventSchema{
+//		Name: "user.signup",
+//		Required: map[string]logger.FieldType{
+//			"user_id": logger.FieldString,
+//			"plan":    logger.FieldString,
+//		},
+//		Optional: map[string]logger.FieldType{"referrer": logger.FieldString},
+//	})
+//
+// It fails if the name is empty or already registered.
+func RegisterEvent(schema EventSchema) error {
+	if schema.Name == "" {
+		return errors.New("logger: event schema without a name")
+	}
+	eventSchemasMu.Lock()
+	defer eventSchemasMu.Unlock()
+
+	current, _ := eventSchemas.Load().(map[string]*EventSchema)
+	if _, ok := current[schema.Name]; ok {
+		return fmt.Errorf("logger: event %s already registered", schema.Name)
+	}
+	next := make(map[string]*EventSchema, len(current)+1)
+	for name, s := range current {
+		next[name] = s
+	}
+	next[schema.Name] = &schema
+	eventSchemas.Store(next)
+	return nil
+}
+
+// LookupEvent returns the schema registered for an event
+func LookupEvent(name string) (EventSchema, bool) {
+	schemas, _ := eventSchemas.Load().(map[string]*EventSchema)
+	s, ok := schemas[name]
+	if !ok {
+		return EventSchema{}, false
+	}
+	return *s, true
+}
+
+// checkEvent checks fields against the schema registered for name
+func checkEvent(name string, fields map[string]interface{}) error {
+	schemas, _ := eventSchemas.Load().(map[string]*EventSchema)
+	s, ok := schemas[name]
+	if !ok {
+		return fmt.Errorf("%w %s", ErrUnknownEvent, name)
+	}
+	return s.Validate(fields)
+}
+
+// Event logs a named event at Info, with the name as its message and in
+// EventField, after checking fields against the schema registered for it
+// with RegisterEvent. Events give analytics the consistent names and fields
+// free-form messages lack.
+//
+// An event that doesn't match its schema, or has none, is still logged,
+// with the reason in EventErrorField, and the error is returned; in
+// development mode (see SetDevelopment) Event then panics with it, so
+// mismatches are caught before they reach production.
+func (l *Logger) Event(name string, fields map[string]interface{}) error {
+	return l.event(1, name, fields)
+}
+
+// event logs an event and returns why it doesn't match its schema, or, in
+// development mode, panics with it
+func (l *Logger) event(skip int, name string, fields map[string]interface{}) error {
+	err := checkEvent(name, fields)
+	if l.allow(LevelInfo) {
+		list := []Field{{Key: EventField, Value: name}}
+		if err != nil {
+			list = append(list, Field{Key: EventErrorField, Value: err.Error()})
+		}
+		l.write(LevelInfo, skip+1, name, list, fields)
+	}
+	if err != nil && l.Development() {
+		l.Flush()
+		panic(err)
+	}
+	return err
+}
+
+// Event logs a named event to the default logger (see Logger.Event)
+func Event(name string, fields map[string]interface{}) error {
+	l := GetLogger()
+	return l.event(1, name, fields)
+}
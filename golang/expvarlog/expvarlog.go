@@ -0,0 +1,46 @@
+// Package expvarlog publishes the internal metrics of a logger (see
+// logger.Stats) through expvar, so processes that only serve /debug/vars
+// still show whether logging is healthy.
+//
+//	expvarlog.Publish(l) // served under "vlog"
+package expvarlog
+
+import (
+	"expvar"
+	"sync"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// Name is the expvar name Publish uses
+const Name = "vlog"
+
+var (
+	mu        sync.Mutex
+	published = make(map[string]*logger.Logger)
+)
+
+// Publish publishes the metrics of l under Name
+func Publish(l *logger.Logger) {
+	PublishAs(Name, l)
+}
+
+// PublishAs publishes the metrics of l under the given expvar name.
+// Publishing another logger under the same name replaces the first; unlike
+// expvar.Publish it doesn't panic.
+func PublishAs(name string, l *logger.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, exists := published[name]
+	published[name] = l
+	if exists {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		mu.Lock()
+		l := published[name]
+		mu.Unlock()
+		return l.Stats()
+	}))
+}
@@ -0,0 +1,176 @@
+package logger
+
+import "slices"
+
+// Field is a key and value attached to log entries. Fields passed as a list
+// keep their order in the output, unlike fields passed as a map, which are
+// written sorted by key.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F returns a field with the given key and value
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// fieldIndex returns the position of the field with the given key, or -1
+func fieldIndex(fields []Field, key string) int {
+	for i := range fields {
+		if fields[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendFieldList returns fields with extra added in order. A key that is
+// already present keeps its position and takes the new value. fields is
+// never modified, so loggers can share their lists with derived loggers.
+func appendFieldList(fields []Field, extra ...Field) []Field {
+	if len(extra) == 0 {
+		return fields
+	}
+	// Clipping makes append copy rather than write into a shared array
+	out := slices.Clip(fields)
+	copied := false
+	for _, f := range extra {
+		if i := fieldIndex(out, f.Key); i >= 0 {
+			if !copied && i < len(fields) {
+				out = slices.Clone(out)
+				copied = true
+			}
+			out[i].Value = f.Value
+			continue
+		}
+		// The first append copies, as out is clipped
+		out = append(out, f)
+		copied = true
+	}
+	return out
+}
+
+// mapFieldList returns the fields of a map sorted by key
+func mapFieldList(fields map[string]interface{}) []Field {
+	if len(fields) == 0 {
+		return nil
+	}
+	list := make([]Field, 0, len(fields))
+	for k, v := range fields {
+		list = append(list, Field{Key: k, Value: v})
+	}
+	slices.SortFunc(list, func(a, b Field) int {
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	})
+	return list
+}
+
+// nestFieldList is nestFields for field lists: it adds src to fields, nested
+// under the given groups
+func nestFieldList(fields []Field, groups []string, src map[string]interface{}) []Field {
+	if len(src) == 0 {
+		return fields
+	}
+	if len(groups) == 0 {
+		return appendFieldList(fields, mapFieldList(src)...)
+	}
+
+	var existing map[string]interface{}
+	if i := fieldIndex(fields, groups[0]); i >= 0 {
+		existing, _ = fields[i].Value.(map[string]interface{})
+	}
+	nested := make(map[string]interface{}, len(existing)+len(src))
+	for k, v := range existing {
+		nested[k] = v
+	}
+	nestFields(nested, groups[1:], src)
+	return appendFieldList(fields, Field{Key: groups[0], Value: nested})
+}
+
+// setField sets a field of the entry, remembering the order keys were first
+// added in
+func (e *LogEntry) setField(key string, value interface{}) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]interface{})
+	}
+	if _, exists := e.Fields[key]; !exists {
+		e.keys = append(e.keys, key)
+	}
+	e.Fields[key] = value
+}
+
+// addFields sets fields of the entry, nested under the given groups
+func (e *LogEntry) addFields(groups []string, fields map[string]interface{}) {
+	if len(fields) == 0 {
+		return
+	}
+	if len(groups) == 0 {
+		var scratch [32]string
+		keys := scratch[:0]
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			e.setField(k, fields[k])
+		}
+		return
+	}
+	if e.Fields == nil {
+		e.Fields = make(map[string]interface{}, 1)
+	}
+	if _, exists := e.Fields[groups[0]]; !exists {
+		e.keys = append(e.keys, groups[0])
+	}
+	nestFields(e.Fields, groups, fields)
+}
+
+// FieldList returns the fields of the entry in the order they were added.
+// Fields added to the Fields map directly, e.g. by hooks, follow sorted by
+// key.
+func (e *LogEntry) FieldList() []Field {
+	list := make([]Field, 0, len(e.Fields))
+	e.rangeFields(func(k string, v interface{}) bool {
+		list = append(list, Field{Key: k, Value: v})
+		return true
+	})
+	return list
+}
+
+// rangeFields calls fn for each field in the order of FieldList, until fn
+// returns false
+func (e *LogEntry) rangeFields(fn func(key string, value interface{}) bool) {
+	seen := 0
+	for _, k := range e.keys {
+		if v, ok := e.Fields[k]; ok {
+			seen++
+			if !fn(k, v) {
+				return
+			}
+		}
+	}
+	if seen == len(e.Fields) {
+		return
+	}
+
+	var scratch [32]string
+	rest := scratch[:0]
+	for k := range e.Fields {
+		if !slices.Contains(e.keys, k) {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+	for _, k := range rest {
+		if !fn(k, e.Fields[k]) {
+			return
+		}
+	}
+}
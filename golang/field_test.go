@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"io"
+	"testing"
+)
+
+// newBenchmarkLogger returns a logger writing JSON to io.Discard, with a
+// few default fields as services usually have. Producers block rather than
+// drop when the queue is full, so every entry is written.
+func newBenchmarkLogger(b *testing.B) *Logger {
+	b.Helper()
+	l := NewLogger(WithOutputs(NewConsoleOutput(io.Discard, FormatJSON)),
+		WithCaller(false), WithOverflowPolicy(OverflowBlock, 0))
+	b.Cleanup(func() { l.Close() })
+	return l.WithFieldList(F("service", "checkout"), F("region", "eu-west-1"))
+}
+
+func BenchmarkLogFieldList(b *testing.B) {
+	l := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Log(LevelInfo, "Request served", F("status", 200), F("route", "/cart"))
+	}
+}
+
+func BenchmarkLogFieldMap(b *testing.B) {
+	l := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("Request served", map[string]interface{}{"status": 200, "route": "/cart"})
+	}
+}
+
+func BenchmarkWithFieldList(b *testing.B) {
+	l := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithFieldList(F("request_id", i))
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	l := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithFields(map[string]interface{}{"request_id": i})
+	}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import "sync"
+
+// Filter inspects an entry before it is queued. It returns the entry to keep
+// (either the one it was given or a rewritten replacement) and whether the
+// entry should be logged at all. Returning false drops the entry.
+type Filter func(entry *LogEntry) (*LogEntry, bool)
+
+// filterChain holds the filters shared by a logger and everything derived from it
+type filterChain struct {
+	mu      sync.RWMutex
+	filters []Filter
+}
+
+func newFilterChain() *filterChain {
+	return &filterChain{}
+}
+
+// add appends a filter to the end of the chain
+func (c *filterChain) add(f Filter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Copy on write so apply can iterate without holding the lock
+	filters := make([]Filter, len(c.filters), len(c.filters)+1)
+	copy(filters, c.filters)
+	c.filters = append(filters, f)
+}
+
+// apply runs the entry through every filter in order, stopping at the first
+// one that drops it
+func (c *filterChain) apply(entry *LogEntry) (*LogEntry, bool) {
+	c.mu.RLock()
+	filters := c.filters
+	c.mu.RUnlock()
+
+	if len(filters) > 0 {
+		entry.resolve()
+	}
+	for _, f := range filters {
+		var keep bool
+		entry, keep = f(entry)
+		if !keep || entry == nil {
+			return nil, false
+		}
+	}
+	return entry, true
+}
+
+// AddFilter appends a filter to the chain evaluated for every entry before it
+// is queued. Filters run in the order they were added, before hooks, and are
+// shared with every logger derived via With or WithFields.
+func (l *Logger) AddFilter(f Filter) {
+	l.filters.add(f)
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// FormatMode decides which work is done by the goroutine logging an entry
+// and which is left to the queue's workers
+type FormatMode int32
+
+const (
+	// FormatInCaller formats printf-style messages when they are logged and
+	// leaves encoding the entry to the workers. This is the default.
+	FormatInCaller FormatMode = iota
+	// FormatInWorker only captures the format and arguments of printf-style
+	// messages when they are logged; the workers format them. This keeps
+	// Sprintf off request handling goroutines, but arguments must not be
+	// modified after logging. Filters, hooks, redaction and deduplication
+	// still see formatted messages, at the cost of formatting in the caller
+	// while any of them is in use.
+	FormatInWorker
+	// EncodeInCaller formats messages and encodes field values into JSON
+	// when they are logged, so entries are immutable from then on: values
+	// modified after logging are written as they were. Encoded fields reach
+	// outputs as json.RawMessage.
+	EncodeInCaller
+)
+
+// String returns the name of the mode
+func (m FormatMode) String() string {
+	switch m {
+	case FormatInCaller:
+		return "format-in-caller"
+	case FormatInWorker:
+		return "format-in-worker"
+	case EncodeInCaller:
+		return "encode-in-caller"
+	default:
+		return fmt.Sprintf("FormatMode(%d)", int32(m))
+	}
+}
+
+// SetFormatMode chooses where messages are formatted and entries encoded,
+// for this logger and every logger derived from it
+func (l *Logger) SetFormatMode(mode FormatMode) {
+	atomic.StoreInt32(&l.settings.formatMode, int32(mode))
+}
+
+// FormatMode returns where messages are formatted and entries encoded
+func (l *Logger) FormatMode() FormatMode {
+	return FormatMode(atomic.LoadInt32(&l.settings.formatMode))
+}
+
+// resolve formats the message of an entry whose formatting was left to the
+// worker
+func (e *LogEntry) resolve() {
+	if e.args != nil {
+		e.Message = fmt.Sprintf(e.Message, e.args...)
+		e.args = nil
+	}
+}
+
+// encodeFieldValues replaces the field values of an entry that could still
+// change with their JSON encoding
+func encodeFieldValues(entry *LogEntry) {
+	if len(entry.Fields) == 0 {
+		return
+	}
+	encoders := currentEncoders()
+	for k, v := range entry.Fields {
+		if immutable(v) {
+			continue
+		}
+		// The encoder starts without a buffer, so the bytes are the
+		// entry's own
+		e := getJSONEncoder(nil)
+		if err := e.appendValue(encoders, v); err == nil {
+			entry.Fields[k] = json.RawMessage(e.buf)
+		}
+		putJSONEncoder(e)
+	}
+}
+
+// immutable reports whether a field value can't change after logging
+func immutable(value interface{}) bool {
+	switch value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64,
+		time.Time, time.Duration, json.RawMessage:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Package ginlog provides Gin middleware that logs requests and recovers
+// panics through a logger, replacing gin.Logger and gin.Recovery so a
+// process has a single log format.
+//
+//	router := gin.New()
+//	router.Use(ginlog.Middleware(l), ginlog.Recovery(l))
+package ginlog
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// ContextKey is the gin context key under which Middleware stores the
+// request-scoped logger
+const ContextKey = "vlog.logger"
+
+// UseLogger redirects Gin's own debug and error output (route registration,
+// warnings) to the logger at Debug and Error level
+func UseLogger(l *logger.Logger) {
+	gin.DefaultWriter = l.Writer(logger.LevelDebug)
+	gin.DefaultErrorWriter = l.Writer(logger.LevelError)
+}
+
+// Middleware logs every request once it has been handled, with its method,
+// path, route, status, latency, client IP, response size and any errors
+// attached to the gin context. Requests answered with 5xx are logged at Error,
+// 4xx at Warning and everything else at Info.
+//
+// The request ID is taken from the incoming headers or generated (see
+// logger.RequestIDFromHeader) and echoed in the X-Request-ID response header.
+// A request-scoped child logger carrying the method, path and request ID is
+// stored in the gin context (see FromContext) and in the request's context
+// (see logger.FromContext) for handlers to log through.
+func Middleware(l *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		req := c.Request
+
+		id := logger.RequestIDFromHeader(req.Header)
+		if id == "" {
+			id = logger.NewRequestID()
+		}
+		c.Header(logger.RequestIDHeader, id)
+
+		ctx := logger.NewContext(req.Context(), l.WithFields(map[string]interface{}{
+			"method": req.Method,
+			"path":   req.URL.Path,
+		}))
+		ctx = logger.WithRequestID(ctx, id)
+		reqLogger := logger.FromContext(ctx)
+		c.Set(ContextKey, reqLogger)
+		c.Request = req.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0 // Nothing written
+		}
+		fields := map[string]interface{}{
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip":  c.ClientIP(),
+			"bytes":      size,
+		}
+		if route := c.FullPath(); route != "" {
+			fields["route"] = route
+		}
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.Errors()
+		}
+
+		msg := fmt.Sprintf("%s %s %d", req.Method, req.URL.Path, status)
+		switch {
+		case status >= http.StatusInternalServerError:
+			reqLogger.Error(msg, fields)
+		case status >= http.StatusBadRequest:
+			reqLogger.Warning(msg, fields)
+		default:
+			reqLogger.Info(msg, fields)
+		}
+	}
+}
+
+// Recovery recovers panics in later handlers, logs them at Critical with the
+// panic value and stack trace, and answers 500. Register it after Middleware
+// so the panic is logged through the request-scoped logger and the request
+// itself is still logged with its 500 status.
+func Recovery(l *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				// Deliberate abort of the response, let net/http handle it
+				panic(r)
+			}
+			FromContext(c, l).Critical(fmt.Sprintf("Panic recovered: %v", r), map[string]interface{}{
+				"panic": fmt.Sprint(r),
+				"stack": string(debug.Stack()),
+			})
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
+
+// FromContext returns the request-scoped logger stored by Middleware, or
+// fallback if there is none
+func FromContext(c *gin.Context, fallback *logger.Logger) *logger.Logger {
+	if v, ok := c.Get(ContextKey); ok {
+		if l, ok := v.(*logger.Logger); ok {
+			return l
+		}
+	}
+	return fallback
+}
@@ -3,3 +3,8 @@ module github.com/hemant-mann/logger/golang
 go 1.22.0
 
 toolchain go1.22.3
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
+)
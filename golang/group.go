@@ -0,0 +1,35 @@
+package logger
+
+// WithGroup creates a new logger that nests all fields added afterwards,
+// through WithFields or per-message fields, under the given key. Groups can
+// be nested further, so middleware and application code can both record a
+// "duration" field without colliding:
+//
+//	l.WithGroup("http").WithField("duration", d) // {"http": {"duration": d}}
+func (l *Logger) WithGroup(name string) *Logger {
+	newLogger := l.WithFields(nil)
+	// Force a copy so sibling groups never share a backing array
+	newLogger.groups = append(l.groups[:len(l.groups):len(l.groups)], name)
+	return newLogger
+}
+
+// nestFields merges src into dst under the given group path. Maps along the
+// path are copied before being modified, since they may be shared with other
+// loggers or entries.
+func nestFields(dst map[string]interface{}, groups []string, src map[string]interface{}) {
+	if len(src) == 0 {
+		return
+	}
+	for _, group := range groups {
+		existing, _ := dst[group].(map[string]interface{})
+		nested := make(map[string]interface{}, len(existing)+len(src))
+		for k, v := range existing {
+			nested[k] = v
+		}
+		dst[group] = nested
+		dst = nested
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+}
@@ -0,0 +1,592 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: admin.proto
+
+package adminpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStateRequest) Reset() {
+	*x = GetStateRequest{}
+	mi := &file_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStateRequest) ProtoMessage() {}
+
+func (x *GetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStateRequest.ProtoReflect.Descriptor instead.
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type SetLevelRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Component name or glob pattern; empty sets the global level.
+	Component string `protobuf:"bytes,1,opt,name=component,proto3" json:"component,omitempty"`
+	// Level name such as "debug" or "warn".
+	Level         string `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLevelRequest) Reset() {
+	*x = SetLevelRequest{}
+	mi := &file_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLevelRequest) ProtoMessage() {}
+
+func (x *SetLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLevelRequest.ProtoReflect.Descriptor instead.
+func (*SetLevelRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetLevelRequest) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *SetLevelRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+type ClearComponentLevelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Component     string                 `protobuf:"bytes,1,opt,name=component,proto3" json:"component,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearComponentLevelRequest) Reset() {
+	*x = ClearComponentLevelRequest{}
+	mi := &file_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearComponentLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearComponentLevelRequest) ProtoMessage() {}
+
+func (x *ClearComponentLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearComponentLevelRequest.ProtoReflect.Descriptor instead.
+func (*ClearComponentLevelRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ClearComponentLevelRequest) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+type SetSamplingRateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Rate          int32                  `protobuf:"varint,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSamplingRateRequest) Reset() {
+	*x = SetSamplingRateRequest{}
+	mi := &file_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSamplingRateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSamplingRateRequest) ProtoMessage() {}
+
+func (x *SetSamplingRateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSamplingRateRequest.ProtoReflect.Descriptor instead.
+func (*SetSamplingRateRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SetSamplingRateRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SetSamplingRateRequest) GetRate() int32 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type SetComponentSamplingRateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Component     string                 `protobuf:"bytes,1,opt,name=component,proto3" json:"component,omitempty"`
+	Rate          int32                  `protobuf:"varint,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetComponentSamplingRateRequest) Reset() {
+	*x = SetComponentSamplingRateRequest{}
+	mi := &file_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetComponentSamplingRateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetComponentSamplingRateRequest) ProtoMessage() {}
+
+func (x *SetComponentSamplingRateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetComponentSamplingRateRequest.ProtoReflect.Descriptor instead.
+func (*SetComponentSamplingRateRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetComponentSamplingRateRequest) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *SetComponentSamplingRateRequest) GetRate() int32 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type SetLevelSamplingRateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Level name such as "debug" or "warn".
+	Level         string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	Rate          int32  `protobuf:"varint,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLevelSamplingRateRequest) Reset() {
+	*x = SetLevelSamplingRateRequest{}
+	mi := &file_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLevelSamplingRateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLevelSamplingRateRequest) ProtoMessage() {}
+
+func (x *SetLevelSamplingRateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLevelSamplingRateRequest.ProtoReflect.Descriptor instead.
+func (*SetLevelSamplingRateRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SetLevelSamplingRateRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *SetLevelSamplingRateRequest) GetRate() int32 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type QueueStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Length        int64                  `protobuf:"varint,1,opt,name=length,proto3" json:"length,omitempty"`
+	Capacity      int64                  `protobuf:"varint,2,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	Dropped       uint64                 `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueStats) Reset() {
+	*x = QueueStats{}
+	mi := &file_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueStats) ProtoMessage() {}
+
+func (x *QueueStats) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueStats.ProtoReflect.Descriptor instead.
+func (*QueueStats) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *QueueStats) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *QueueStats) GetCapacity() int64 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *QueueStats) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type State struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Global level name.
+	Level string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	// Levels set per component or pattern.
+	Components map[string]string `protobuf:"bytes,2,rep,name=components,proto3" json:"components,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Sampling rates set per key.
+	Sampling map[string]int32 `protobuf:"bytes,3,rep,name=sampling,proto3" json:"sampling,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Queue    *QueueStats      `protobuf:"bytes,4,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Sampling rates set per component.
+	ComponentSampling map[string]int32 `protobuf:"bytes,5,rep,name=component_sampling,json=componentSampling,proto3" json:"component_sampling,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// Sampling rates set per level, by level name.
+	LevelSampling map[string]int32 `protobuf:"bytes,6,rep,name=level_sampling,json=levelSampling,proto3" json:"level_sampling,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *State) Reset() {
+	*x = State{}
+	mi := &file_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *State) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*State) ProtoMessage() {}
+
+func (x *State) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use State.ProtoReflect.Descriptor instead.
+func (*State) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *State) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *State) GetComponents() map[string]string {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *State) GetSampling() map[string]int32 {
+	if x != nil {
+		return x.Sampling
+	}
+	return nil
+}
+
+func (x *State) GetQueue() *QueueStats {
+	if x != nil {
+		return x.Queue
+	}
+	return nil
+}
+
+func (x *State) GetComponentSampling() map[string]int32 {
+	if x != nil {
+		return x.ComponentSampling
+	}
+	return nil
+}
+
+func (x *State) GetLevelSampling() map[string]int32 {
+	if x != nil {
+		return x.LevelSampling
+	}
+	return nil
+}
+
+var File_admin_proto protoreflect.FileDescriptor
+
+const file_admin_proto_rawDesc = "" +
+	"\n" +
+	"\vadmin.proto\x12\rvlog.admin.v1\"\x11\n" +
+	"\x0fGetStateRequest\"E\n" +
+	"\x0fSetLevelRequest\x12\x1c\n" +
+	"\tcomponent\x18\x01 \x01(\tR\tcomponent\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\":\n" +
+	"\x1aClearComponentLevelRequest\x12\x1c\n" +
+	"\tcomponent\x18\x01 \x01(\tR\tcomponent\">\n" +
+	"\x16SetSamplingRateRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x05R\x04rate\"S\n" +
+	"\x1fSetComponentSamplingRateRequest\x12\x1c\n" +
+	"\tcomponent\x18\x01 \x01(\tR\tcomponent\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x05R\x04rate\"G\n" +
+	"\x1bSetLevelSamplingRateRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x05R\x04rate\"Z\n" +
+	"\n" +
+	"QueueStats\x12\x16\n" +
+	"\x06length\x18\x01 \x01(\x03R\x06length\x12\x1a\n" +
+	"\bcapacity\x18\x02 \x01(\x03R\bcapacity\x12\x18\n" +
+	"\adropped\x18\x03 \x01(\x04R\adropped\"\x84\x05\n" +
+	"\x05State\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12D\n" +
+	"\n" +
+	"components\x18\x02 \x03(\v2$.vlog.admin.v1.State.ComponentsEntryR\n" +
+	"components\x12>\n" +
+	"\bsampling\x18\x03 \x03(\v2\".vlog.admin.v1.State.SamplingEntryR\bsampling\x12/\n" +
+	"\x05queue\x18\x04 \x01(\v2\x19.vlog.admin.v1.QueueStatsR\x05queue\x12Z\n" +
+	"\x12component_sampling\x18\x05 \x03(\v2+.vlog.admin.v1.State.ComponentSamplingEntryR\x11componentSampling\x12N\n" +
+	"\x0elevel_sampling\x18\x06 \x03(\v2'.vlog.admin.v1.State.LevelSamplingEntryR\rlevelSampling\x1a=\n" +
+	"\x0fComponentsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a;\n" +
+	"\rSamplingEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1aD\n" +
+	"\x16ComponentSamplingEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a@\n" +
+	"\x12LevelSamplingEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x012\xf2\x03\n" +
+	"\bLogAdmin\x12@\n" +
+	"\bGetState\x12\x1e.vlog.admin.v1.GetStateRequest\x1a\x14.vlog.admin.v1.State\x12@\n" +
+	"\bSetLevel\x12\x1e.vlog.admin.v1.SetLevelRequest\x1a\x14.vlog.admin.v1.State\x12V\n" +
+	"\x13ClearComponentLevel\x12).vlog.admin.v1.ClearComponentLevelRequest\x1a\x14.vlog.admin.v1.State\x12N\n" +
+	"\x0fSetSamplingRate\x12%.vlog.admin.v1.SetSamplingRateRequest\x1a\x14.vlog.admin.v1.State\x12`\n" +
+	"\x18SetComponentSamplingRate\x12..vlog.admin.v1.SetComponentSamplingRateRequest\x1a\x14.vlog.admin.v1.State\x12X\n" +
+	"\x14SetLevelSamplingRate\x12*.vlog.admin.v1.SetLevelSamplingRateRequest\x1a\x14.vlog.admin.v1.StateB8Z6github.com/hemant-mann/logger/golang/grpcadmin/adminpbb\x06proto3"
+
+var (
+	file_admin_proto_rawDescOnce sync.Once
+	file_admin_proto_rawDescData []byte
+)
+
+func file_admin_proto_rawDescGZIP() []byte {
+	file_admin_proto_rawDescOnce.Do(func() {
+		file_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_admin_proto_rawDesc), len(file_admin_proto_rawDesc)))
+	})
+	return file_admin_proto_rawDescData
+}
+
+var file_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_admin_proto_goTypes = []any{
+	(*GetStateRequest)(nil),                 // 0: vlog.admin.v1.GetStateRequest
+	(*SetLevelRequest)(nil),                 // 1: vlog.admin.v1.SetLevelRequest
+	(*ClearComponentLevelRequest)(nil),      // 2: vlog.admin.v1.ClearComponentLevelRequest
+	(*SetSamplingRateRequest)(nil),          // 3: vlog.admin.v1.SetSamplingRateRequest
+	(*SetComponentSamplingRateRequest)(nil), // 4: vlog.admin.v1.SetComponentSamplingRateRequest
+	(*SetLevelSamplingRateRequest)(nil),     // 5: vlog.admin.v1.SetLevelSamplingRateRequest
+	(*QueueStats)(nil),                      // 6: vlog.admin.v1.QueueStats
+	(*State)(nil),                           // 7: vlog.admin.v1.State
+	nil,                                     // 8: vlog.admin.v1.State.ComponentsEntry
+	nil,                                     // 9: vlog.admin.v1.State.SamplingEntry
+	nil,                                     // 10: vlog.admin.v1.State.ComponentSamplingEntry
+	nil,                                     // 11: vlog.admin.v1.State.LevelSamplingEntry
+}
+var file_admin_proto_depIdxs = []int32{
+	8,  // 0: vlog.admin.v1.State.components:type_name -> vlog.admin.v1.State.ComponentsEntry
+	9,  // 1: vlog.admin.v1.State.sampling:type_name -> vlog.admin.v1.State.SamplingEntry
+	6,  // 2: vlog.admin.v1.State.queue:type_name -> vlog.admin.v1.QueueStats
+	10, // 3: vlog.admin.v1.State.component_sampling:type_name -> vlog.admin.v1.State.ComponentSamplingEntry
+	11, // 4: vlog.admin.v1.State.level_sampling:type_name -> vlog.admin.v1.State.LevelSamplingEntry
+	0,  // 5: vlog.admin.v1.LogAdmin.GetState:input_type -> vlog.admin.v1.GetStateRequest
+	1,  // 6: vlog.admin.v1.LogAdmin.SetLevel:input_type -> vlog.admin.v1.SetLevelRequest
+	2,  // 7: vlog.admin.v1.LogAdmin.ClearComponentLevel:input_type -> vlog.admin.v1.ClearComponentLevelRequest
+	3,  // 8: vlog.admin.v1.LogAdmin.SetSamplingRate:input_type -> vlog.admin.v1.SetSamplingRateRequest
+	4,  // 9: vlog.admin.v1.LogAdmin.SetComponentSamplingRate:input_type -> vlog.admin.v1.SetComponentSamplingRateRequest
+	5,  // 10: vlog.admin.v1.LogAdmin.SetLevelSamplingRate:input_type -> vlog.admin.v1.SetLevelSamplingRateRequest
+	7,  // 11: vlog.admin.v1.LogAdmin.GetState:output_type -> vlog.admin.v1.State
+	7,  // 12: vlog.admin.v1.LogAdmin.SetLevel:output_type -> vlog.admin.v1.State
+	7,  // 13: vlog.admin.v1.LogAdmin.ClearComponentLevel:output_type -> vlog.admin.v1.State
+	7,  // 14: vlog.admin.v1.LogAdmin.SetSamplingRate:output_type -> vlog.admin.v1.State
+	7,  // 15: vlog.admin.v1.LogAdmin.SetComponentSamplingRate:output_type -> vlog.admin.v1.State
+	7,  // 16: vlog.admin.v1.LogAdmin.SetLevelSamplingRate:output_type -> vlog.admin.v1.State
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_admin_proto_init() }
+func file_admin_proto_init() {
+	if File_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_admin_proto_rawDesc), len(file_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_admin_proto_goTypes,
+		DependencyIndexes: file_admin_proto_depIdxs,
+		MessageInfos:      file_admin_proto_msgTypes,
+	}.Build()
+	File_admin_proto = out.File
+	file_admin_proto_goTypes = nil
+	file_admin_proto_depIdxs = nil
+}
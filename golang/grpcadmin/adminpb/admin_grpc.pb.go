@@ -0,0 +1,327 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: admin.proto
+
+package adminpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LogAdmin_GetState_FullMethodName                 = "/vlog.admin.v1.LogAdmin/GetState"
+	LogAdmin_SetLevel_FullMethodName                 = "/vlog.admin.v1.LogAdmin/SetLevel"
+	LogAdmin_ClearComponentLevel_FullMethodName      = "/vlog.admin.v1.LogAdmin/ClearComponentLevel"
+	LogAdmin_SetSamplingRate_FullMethodName          = "/vlog.admin.v1.LogAdmin/SetSamplingRate"
+	LogAdmin_SetComponentSamplingRate_FullMethodName = "/vlog.admin.v1.LogAdmin/SetComponentSamplingRate"
+	LogAdmin_SetLevelSamplingRate_FullMethodName     = "/vlog.admin.v1.LogAdmin/SetLevelSamplingRate"
+)
+
+// LogAdminClient is the client API for LogAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LogAdmin queries and changes the levels and sampling rates of a running logger.
+type LogAdminClient interface {
+	// GetState returns the levels, sampling rates and queue statistics.
+	GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*State, error)
+	// SetLevel sets the global level, or the level of a component if one is given.
+	SetLevel(ctx context.Context, in *SetLevelRequest, opts ...grpc.CallOption) (*State, error)
+	// ClearComponentLevel removes the level set for a component so it inherits again.
+	ClearComponentLevel(ctx context.Context, in *ClearComponentLevelRequest, opts ...grpc.CallOption) (*State, error)
+	// SetSamplingRate sets the sampling rate of a key; a rate of 0 restores the call-site rate.
+	SetSamplingRate(ctx context.Context, in *SetSamplingRateRequest, opts ...grpc.CallOption) (*State, error)
+	// SetComponentSamplingRate samples every entry of a component and its descendants; a rate of 0 stops it.
+	SetComponentSamplingRate(ctx context.Context, in *SetComponentSamplingRateRequest, opts ...grpc.CallOption) (*State, error)
+	// SetLevelSamplingRate samples every entry at a level; a rate of 0 stops it.
+	SetLevelSamplingRate(ctx context.Context, in *SetLevelSamplingRateRequest, opts ...grpc.CallOption) (*State, error)
+}
+
+type logAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLogAdminClient(cc grpc.ClientConnInterface) LogAdminClient {
+	return &logAdminClient{cc}
+}
+
+func (c *logAdminClient) GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*State, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(State)
+	err := c.cc.Invoke(ctx, LogAdmin_GetState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logAdminClient) SetLevel(ctx context.Context, in *SetLevelRequest, opts ...grpc.CallOption) (*State, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(State)
+	err := c.cc.Invoke(ctx, LogAdmin_SetLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logAdminClient) ClearComponentLevel(ctx context.Context, in *ClearComponentLevelRequest, opts ...grpc.CallOption) (*State, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(State)
+	err := c.cc.Invoke(ctx, LogAdmin_ClearComponentLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logAdminClient) SetSamplingRate(ctx context.Context, in *SetSamplingRateRequest, opts ...grpc.CallOption) (*State, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(State)
+	err := c.cc.Invoke(ctx, LogAdmin_SetSamplingRate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logAdminClient) SetComponentSamplingRate(ctx context.Context, in *SetComponentSamplingRateRequest, opts ...grpc.CallOption) (*State, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(State)
+	err := c.cc.Invoke(ctx, LogAdmin_SetComponentSamplingRate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logAdminClient) SetLevelSamplingRate(ctx context.Context, in *SetLevelSamplingRateRequest, opts ...grpc.CallOption) (*State, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(State)
+	err := c.cc.Invoke(ctx, LogAdmin_SetLevelSamplingRate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LogAdminServer is the server API for LogAdmin service.
+// All implementations must embed UnimplementedLogAdminServer
+// for forward compatibility.
+//
+// LogAdmin queries and changes the levels and sampling rates of a running logger.
+type LogAdminServer interface {
+	// GetState returns the levels, sampling rates and queue statistics.
+	GetState(context.Context, *GetStateRequest) (*State, error)
+	// SetLevel sets the global level, or the level of a component if one is given.
+	SetLevel(context.Context, *SetLevelRequest) (*State, error)
+	// ClearComponentLevel removes the level set for a component so it inherits again.
+	ClearComponentLevel(context.Context, *ClearComponentLevelRequest) (*State, error)
+	// SetSamplingRate sets the sampling rate of a key; a rate of 0 restores the call-site rate.
+	SetSamplingRate(context.Context, *SetSamplingRateRequest) (*State, error)
+	// SetComponentSamplingRate samples every entry of a component and its descendants; a rate of 0 stops it.
+	SetComponentSamplingRate(context.Context, *SetComponentSamplingRateRequest) (*State, error)
+	// SetLevelSamplingRate samples every entry at a level; a rate of 0 stops it.
+	SetLevelSamplingRate(context.Context, *SetLevelSamplingRateRequest) (*State, error)
+	mustEmbedUnimplementedLogAdminServer()
+}
+
+// UnimplementedLogAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLogAdminServer struct{}
+
+func (UnimplementedLogAdminServer) GetState(context.Context, *GetStateRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+func (UnimplementedLogAdminServer) SetLevel(context.Context, *SetLevelRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLevel not implemented")
+}
+func (UnimplementedLogAdminServer) ClearComponentLevel(context.Context, *ClearComponentLevelRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearComponentLevel not implemented")
+}
+func (UnimplementedLogAdminServer) SetSamplingRate(context.Context, *SetSamplingRateRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSamplingRate not implemented")
+}
+func (UnimplementedLogAdminServer) SetComponentSamplingRate(context.Context, *SetComponentSamplingRateRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetComponentSamplingRate not implemented")
+}
+func (UnimplementedLogAdminServer) SetLevelSamplingRate(context.Context, *SetLevelSamplingRateRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLevelSamplingRate not implemented")
+}
+func (UnimplementedLogAdminServer) mustEmbedUnimplementedLogAdminServer() {}
+func (UnimplementedLogAdminServer) testEmbeddedByValue()                  {}
+
+// UnsafeLogAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LogAdminServer will
+// result in compilation errors.
+type UnsafeLogAdminServer interface {
+	mustEmbedUnimplementedLogAdminServer()
+}
+
+func RegisterLogAdminServer(s grpc.ServiceRegistrar, srv LogAdminServer) {
+	// If the following call pancis, it indicates UnimplementedLogAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LogAdmin_ServiceDesc, srv)
+}
+
+func _LogAdmin_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogAdminServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogAdmin_GetState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogAdminServer).GetState(ctx, req.(*GetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogAdmin_SetLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogAdminServer).SetLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogAdmin_SetLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogAdminServer).SetLevel(ctx, req.(*SetLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogAdmin_ClearComponentLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearComponentLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogAdminServer).ClearComponentLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogAdmin_ClearComponentLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogAdminServer).ClearComponentLevel(ctx, req.(*ClearComponentLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogAdmin_SetSamplingRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSamplingRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogAdminServer).SetSamplingRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogAdmin_SetSamplingRate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogAdminServer).SetSamplingRate(ctx, req.(*SetSamplingRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogAdmin_SetComponentSamplingRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetComponentSamplingRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogAdminServer).SetComponentSamplingRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogAdmin_SetComponentSamplingRate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogAdminServer).SetComponentSamplingRate(ctx, req.(*SetComponentSamplingRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogAdmin_SetLevelSamplingRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLevelSamplingRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogAdminServer).SetLevelSamplingRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogAdmin_SetLevelSamplingRate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogAdminServer).SetLevelSamplingRate(ctx, req.(*SetLevelSamplingRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LogAdmin_ServiceDesc is the grpc.ServiceDesc for LogAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LogAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vlog.admin.v1.LogAdmin",
+	HandlerType: (*LogAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetState",
+			Handler:    _LogAdmin_GetState_Handler,
+		},
+		{
+			MethodName: "SetLevel",
+			Handler:    _LogAdmin_SetLevel_Handler,
+		},
+		{
+			MethodName: "ClearComponentLevel",
+			Handler:    _LogAdmin_ClearComponentLevel_Handler,
+		},
+		{
+			MethodName: "SetSamplingRate",
+			Handler:    _LogAdmin_SetSamplingRate_Handler,
+		},
+		{
+			MethodName: "SetComponentSamplingRate",
+			Handler:    _LogAdmin_SetComponentSamplingRate_Handler,
+		},
+		{
+			MethodName: "SetLevelSamplingRate",
+			Handler:    _LogAdmin_SetLevelSamplingRate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
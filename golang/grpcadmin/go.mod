@@ -0,0 +1,20 @@
+module github.com/hemant-mann/logger/golang/grpcadmin
+
+go 1.23
+
+require (
+	github.com/hemant-mann/logger/golang v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.67.1
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sys v0.24.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/hemant-mann/logger/golang => ../
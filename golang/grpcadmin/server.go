@@ -0,0 +1,138 @@
+// Package grpcadmin exposes runtime log level and sampling control of a
+// logger as a gRPC service, so fleets managed over gRPC control planes can
+// adjust logging uniformly. The service is defined in adminpb/admin.proto.
+package grpcadmin
+
+//go:generate protoc --go_out=adminpb --go_opt=paths=source_relative --go-grpc_out=adminpb --go-grpc_opt=paths=source_relative -I adminpb admin.proto
+
+import (
+	"context"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/hemant-mann/logger/golang/grpcadmin/adminpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements adminpb.LogAdminServer for a logger
+type Server struct {
+	adminpb.UnimplementedLogAdminServer
+	logger *logger.Logger
+}
+
+// NewServer creates a LogAdmin service controlling the given logger
+func NewServer(l *logger.Logger) *Server {
+	return &Server{logger: l}
+}
+
+// Register creates a LogAdmin service for the logger and registers it with s
+func Register(s grpc.ServiceRegistrar, l *logger.Logger) {
+	adminpb.RegisterLogAdminServer(s, NewServer(l))
+}
+
+// GetState returns the levels, sampling rates and queue statistics
+func (s *Server) GetState(ctx context.Context, req *adminpb.GetStateRequest) (*adminpb.State, error) {
+	return s.state(), nil
+}
+
+// SetLevel sets the global level, or the level of a component if one is given
+func (s *Server) SetLevel(ctx context.Context, req *adminpb.SetLevelRequest) (*adminpb.State, error) {
+	level, err := logger.ParseLevel(req.GetLevel())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.GetComponent() == "" {
+		s.logger.SetLevel(level)
+	} else {
+		s.logger.SetComponentLevel(req.GetComponent(), level)
+	}
+	return s.state(), nil
+}
+
+// ClearComponentLevel removes the level set for a component
+func (s *Server) ClearComponentLevel(ctx context.Context, req *adminpb.ClearComponentLevelRequest) (*adminpb.State, error) {
+	if !s.logger.ClearComponentLevel(req.GetComponent()) {
+		return nil, status.Errorf(codes.NotFound, "no level set for component %q", req.GetComponent())
+	}
+	return s.state(), nil
+}
+
+// SetSamplingRate sets the sampling rate of a key; 0 restores the call-site rate
+func (s *Server) SetSamplingRate(ctx context.Context, req *adminpb.SetSamplingRateRequest) (*adminpb.State, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "sampling key is required")
+	}
+	if req.GetRate() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "rate must not be negative")
+	}
+	s.logger.SetSamplingRate(req.GetKey(), int(req.GetRate()))
+	return s.state(), nil
+}
+
+// SetComponentSamplingRate samples every entry of a component; 0 stops it
+func (s *Server) SetComponentSamplingRate(ctx context.Context, req *adminpb.SetComponentSamplingRateRequest) (*adminpb.State, error) {
+	if req.GetComponent() == "" {
+		return nil, status.Error(codes.InvalidArgument, "component is required")
+	}
+	if req.GetRate() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "rate must not be negative")
+	}
+	s.logger.SetComponentSamplingRate(req.GetComponent(), int(req.GetRate()))
+	return s.state(), nil
+}
+
+// SetLevelSamplingRate samples every entry at a level; 0 stops it
+func (s *Server) SetLevelSamplingRate(ctx context.Context, req *adminpb.SetLevelSamplingRateRequest) (*adminpb.State, error) {
+	level, err := logger.ParseLevel(req.GetLevel())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetRate() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "rate must not be negative")
+	}
+	s.logger.SetLevelSamplingRate(level, int(req.GetRate()))
+	return s.state(), nil
+}
+
+// state builds the State message describing the logger
+func (s *Server) state() *adminpb.State {
+	levels := s.logger.ComponentLevels()
+	components := make(map[string]string, len(levels))
+	for name, level := range levels {
+		components[name] = level.String()
+	}
+
+	rates := s.logger.SamplingRates()
+	sampling := make(map[string]int32, len(rates))
+	for key, rate := range rates {
+		sampling[key] = int32(rate)
+	}
+
+	componentRates := s.logger.ComponentSamplingRates()
+	componentSampling := make(map[string]int32, len(componentRates))
+	for name, rate := range componentRates {
+		componentSampling[name] = int32(rate)
+	}
+
+	levelRates := s.logger.LevelSamplingRates()
+	levelSampling := make(map[string]int32, len(levelRates))
+	for level, rate := range levelRates {
+		levelSampling[level.String()] = int32(rate)
+	}
+
+	queue := s.logger.QueueStats()
+	return &adminpb.State{
+		Level:             s.logger.GetLevel().String(),
+		Components:        components,
+		Sampling:          sampling,
+		ComponentSampling: componentSampling,
+		LevelSampling:     levelSampling,
+		Queue: &adminpb.QueueStats{
+			Length:   int64(queue.Length),
+			Capacity: int64(queue.Capacity),
+			Dropped:  queue.Dropped,
+		},
+	}
+}
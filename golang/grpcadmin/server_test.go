@@ -0,0 +1,152 @@
+package grpcadmin
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/hemant-mann/logger/golang/grpcadmin/adminpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a LogAdmin service for l in memory and returns a
+// client of it
+func newTestClient(t *testing.T, l *logger.Logger) adminpb.LogAdminClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	Register(server, l)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return adminpb.NewLogAdminClient(conn)
+}
+
+func TestLevels(t *testing.T) {
+	l := logger.NewLogger()
+	defer l.Close()
+	client := newTestClient(t, l)
+	ctx := context.Background()
+
+	state, err := client.SetLevel(ctx, &adminpb.SetLevelRequest{Level: "warn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.GetLevel() != logger.LevelWarning.String() {
+		t.Errorf("level %q", state.GetLevel())
+	}
+
+	state, err = client.SetLevel(ctx, &adminpb.SetLevelRequest{Component: "db", Level: "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.GetComponents()["db"]; got != logger.LevelDebug.String() {
+		t.Errorf("db level %q", got)
+	}
+
+	if _, err := client.ClearComponentLevel(ctx, &adminpb.ClearComponentLevelRequest{Component: "db"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.ClearComponentLevel(ctx, &adminpb.ClearComponentLevelRequest{Component: "db"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("clearing a level not set: %v", err)
+	}
+	_, err = client.SetLevel(ctx, &adminpb.SetLevelRequest{Level: "loud"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("setting an unknown level: %v", err)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	l := logger.NewLogger()
+	defer l.Close()
+	client := newTestClient(t, l)
+	ctx := context.Background()
+
+	state, err := client.SetSamplingRate(ctx, &adminpb.SetSamplingRateRequest{Key: "cache.miss", Rate: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.GetSampling()["cache.miss"]; got != 100 {
+		t.Errorf("key rate %d", got)
+	}
+
+	state, err = client.SetComponentSamplingRate(ctx, &adminpb.SetComponentSamplingRateRequest{Component: "http", Rate: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.GetComponentSampling()["http"]; got != 10 {
+		t.Errorf("component rate %d", got)
+	}
+	if got := l.ComponentSamplingRates()["http"]; got != 10 {
+		t.Errorf("logger component rate %d", got)
+	}
+
+	state, err = client.SetLevelSamplingRate(ctx, &adminpb.SetLevelSamplingRateRequest{Level: "debug", Rate: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.GetLevelSampling()[logger.LevelDebug.String()]; got != 50 {
+		t.Errorf("level rate %d", got)
+	}
+	if got := l.LevelSamplingRates()[logger.LevelDebug]; got != 50 {
+		t.Errorf("logger level rate %d", got)
+	}
+
+	// GetState reports the same settings, and a rate of 0 removes them
+	state, err = client.GetState(ctx, &adminpb.GetStateRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.GetComponentSampling()) != 1 || len(state.GetLevelSampling()) != 1 {
+		t.Errorf("state %v", state)
+	}
+	if _, err := client.SetComponentSamplingRate(ctx, &adminpb.SetComponentSamplingRateRequest{Component: "http"}); err != nil {
+		t.Fatal(err)
+	}
+	state, err = client.SetLevelSamplingRate(ctx, &adminpb.SetLevelSamplingRateRequest{Level: "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.GetComponentSampling()) != 0 || len(state.GetLevelSampling()) != 0 {
+		t.Errorf("sampling left after removal: %v", state)
+	}
+
+	for _, call := range []func() error{
+		func() error {
+			_, err := client.SetComponentSamplingRate(ctx, &adminpb.SetComponentSamplingRateRequest{Rate: 10})
+			return err
+		},
+		func() error {
+			_, err := client.SetComponentSamplingRate(ctx, &adminpb.SetComponentSamplingRateRequest{Component: "http", Rate: -1})
+			return err
+		},
+		func() error {
+			_, err := client.SetLevelSamplingRate(ctx, &adminpb.SetLevelSamplingRateRequest{Level: "loud", Rate: 10})
+			return err
+		},
+		func() error {
+			_, err := client.SetLevelSamplingRate(ctx, &adminpb.SetLevelSamplingRateRequest{Level: "debug", Rate: -1})
+			return err
+		},
+	} {
+		if err := call(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("invalid request: %v", err)
+		}
+	}
+}
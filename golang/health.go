@@ -0,0 +1,146 @@
+package logger
+
+import "time"
+
+// OutputStatus describes how an output is doing
+type OutputStatus string
+
+const (
+	// OutputHealthy means the last write to the output succeeded
+	OutputHealthy OutputStatus = "healthy"
+	// OutputDegraded means the last write to the output failed
+	OutputDegraded OutputStatus = "degraded"
+	// OutputCircuitOpen means a BreakerOutput is skipping the output
+	OutputCircuitOpen OutputStatus = "circuit-open"
+)
+
+// HealthQueueThreshold is the queue utilization from which a logger is
+// reported unhealthy, as entries are about to be dropped
+const HealthQueueThreshold = 0.9
+
+// Health is a logger's view of its own pipeline, see Logger.Health
+type Health struct {
+	// Healthy is false if the logger is closed, an output is degraded or
+	// its circuit is open, the queue is nearly full or entries were
+	// dropped since the previous call
+	Healthy bool `json:"healthy"`
+	// Closed reports whether the logger was closed
+	Closed bool `json:"closed"`
+	// Outputs holds the status of each output of the logger
+	Outputs []OutputHealth `json:"outputs"`
+	// QueueUtilization is the fraction of the queue in use, from 0 to 1
+	QueueUtilization float64 `json:"queue_utilization"`
+	// Dropped counts the entries dropped because the queue was full
+	Dropped uint64 `json:"dropped"`
+	// DropRate is the number of entries dropped per second since the
+	// previous call, or since the logger was created
+	DropRate float64 `json:"drop_rate"`
+}
+
+// OutputHealth is the status of one output
+type OutputHealth struct {
+	// Name identifies the output, as in OutputStats
+	Name string `json:"name"`
+	// Status tells whether the output is working
+	Status OutputStatus `json:"status"`
+	// LastError is the most recent write error, even if writes have
+	// succeeded since
+	LastError string `json:"last_error,omitempty"`
+	// LastErrorAt is when LastError happened
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+
+	// Output is the output itself
+	Output Output `json:"-"`
+}
+
+// Health reports whether the logger is working: the status of each output,
+// how full the queue is and how fast entries are dropped. It suits readiness
+// probes of services that must not run without their logs:
+//
+//	http.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+//	    if !l.Health().Healthy {
+//	        w.WriteHeader(http.StatusServiceUnavailable)
+//	    }
+//	})
+//
+// Drop rates are measured between calls, so probes of several callers share
+// one measurement.
+func (l *Logger) Health() Health {
+	queue := l.QueueStats()
+	health := Health{
+		Dropped: queue.Dropped,
+	}
+	if queue.Capacity > 0 {
+		health.QueueUtilization = float64(queue.Length) / float64(queue.Capacity)
+	}
+
+	l.lifecycle.mu.RLock()
+	health.Closed = l.lifecycle.closed
+	l.lifecycle.mu.RUnlock()
+
+	now := time.Now()
+	l.stats.rateMu.Lock()
+	if elapsed := now.Sub(l.stats.rateAt); elapsed > 0 {
+		health.DropRate = float64(queue.Dropped-l.stats.rateDropped) / elapsed.Seconds()
+	}
+	l.stats.rateAt, l.stats.rateDropped = now, queue.Dropped
+	l.stats.rateMu.Unlock()
+
+	health.Healthy = !health.Closed &&
+		health.QueueUtilization < HealthQueueThreshold &&
+		health.DropRate == 0
+	for _, o := range l.ListOutputs() {
+		h := l.outputHealth(o)
+		if h.Status != OutputHealthy {
+			health.Healthy = false
+		}
+		health.Outputs = append(health.Outputs, h)
+	}
+	return health
+}
+
+// outputHealth returns the status of an output
+func (l *Logger) outputHealth(o Output) OutputHealth {
+	h := OutputHealth{Name: outputName(o), Status: OutputHealthy, Output: o}
+
+	var lastErr error
+	if m, ok := l.stats.outputs.Load(o); ok {
+		m := m.(*outputMetrics)
+		if m.failing.Load() {
+			h.Status = OutputDegraded
+		}
+		if rec := m.lastErr.Load(); rec != nil {
+			lastErr = rec.err
+			at := rec.at
+			h.LastErrorAt = &at
+		}
+	}
+
+	if b := findBreaker(o); b != nil && b.State() != BreakerClosed {
+		h.Status = OutputCircuitOpen
+		// Failed probes aren't seen by the logger, only by the breaker
+		if err := b.LastError(); err != nil {
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		h.LastError = lastErr.Error()
+	}
+	return h
+}
+
+// findBreaker returns the BreakerOutput among o and the outputs it wraps, if
+// any
+func findBreaker(o Output) *BreakerOutput {
+	for o != nil {
+		if b, ok := o.(*BreakerOutput); ok {
+			return b
+		}
+		wrapper, ok := o.(interface{ Unwrap() Output })
+		if !ok {
+			return nil
+		}
+		o = wrapper.Unwrap()
+	}
+	return nil
+}
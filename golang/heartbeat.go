@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// HeartbeatMessage is the message of the entries StartHeartbeat logs
+const HeartbeatMessage = "heartbeat"
+
+// processStart approximates the start of the process, for uptimes
+var processStart = time.Now()
+
+// StartHeartbeat logs a Notice entry with HeartbeatMessage right away and
+// then every interval, until stop is called or the logger is closed, so that
+// monitoring can alert on a service whose heartbeats stop arriving. Entries
+// carry "uptime" (as a duration string) and "uptime_seconds" of the process,
+// "beat" (counting from 1), the queue's "queue_length", "queue_capacity" and
+// "dropped", and fields. They are written regardless of levels, sampling
+// and rate limits, since a quiet level must not look like a dead service.
+// An interval of zero or less logs no heartbeats.
+func (l *Logger) StartHeartbeat(interval time.Duration, fields map[string]interface{}) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	stopped := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() { close(stopped) })
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for beat := 1; ; beat++ {
+			l.heartbeat(beat, fields)
+			select {
+			case <-ticker.C:
+			case <-stopped:
+				return
+			case <-l.done:
+				return
+			}
+		}
+	}()
+	return stop
+}
+
+// heartbeat logs one heartbeat entry
+func (l *Logger) heartbeat(beat int, fields map[string]interface{}) {
+	uptime := time.Since(processStart)
+	queue := l.QueueStats()
+	l.write(LevelNotice, 1, HeartbeatMessage, []Field{
+		{Key: "uptime", Value: uptime.Round(time.Second).String()},
+		{Key: "uptime_seconds", Value: int64(uptime / time.Second)},
+		{Key: "beat", Value: beat},
+		{Key: "queue_length", Value: queue.Length},
+		{Key: "queue_capacity", Value: queue.Capacity},
+		{Key: "dropped", Value: queue.Dropped},
+	}, fields)
+}
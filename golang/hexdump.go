@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// MaxHexdumpBytes bounds the bytes a hexdump shows; the bytes past it are
+// only counted
+const MaxHexdumpBytes = 4096
+
+// HexdumpField carries the dump of TraceHexdump entries, and SizeField the
+// size of the data dumped
+const (
+	HexdumpField = "hexdump"
+	SizeField    = "size"
+)
+
+// HexdumpValue is a field value rendering binary data as a hex and ASCII
+// dump, the way hexdump -C does:
+//
+//	00000000  47 45 54 20 2f 20 48 54  54 50 2f 31 2e 31 0d 0a  |GET / HTTP/1.1..|
+//
+// The dump is only formatted when an output encodes the entry.
+type HexdumpValue struct {
+	data []byte // At most MaxHexdumpBytes
+	size int    // Size of the data dumped
+}
+
+// Hexdump returns a field value dumping data, up to MaxHexdumpBytes of it.
+// Those bytes are copied, so data may be reused once Hexdump returns.
+func Hexdump(data []byte) HexdumpValue {
+	n := min(len(data), MaxHexdumpBytes)
+	return HexdumpValue{data: append([]byte(nil), data[:n]...), size: len(data)}
+}
+
+// String returns the dump, followed by a line counting the bytes left out
+func (v HexdumpValue) String() string {
+	var b strings.Builder
+	b.WriteString(hex.Dump(v.data))
+	if more := v.size - len(v.data); more > 0 {
+		fmt.Fprintf(&b, "... %d more bytes\n", more)
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes the dump as a JSON string
+func (v HexdumpValue) MarshalJSON() ([]byte, error) {
+	return appendJSONString(nil, v.String()), nil
+}
+
+// TraceHexdump logs a hexdump of data at Trace, with label as the message,
+// the dump in HexdumpField and the size of data in SizeField, for
+// debugging protocols. Nothing is copied or formatted unless Trace is
+// enabled for the logger.
+func (l *Logger) TraceHexdump(label string, data []byte, fields ...map[string]interface{}) {
+	l.hexdump(1, label, data, fields)
+}
+
+// hexdump is TraceHexdump for callers skip frames up
+func (l *Logger) hexdump(skip int, label string, data []byte, fields []map[string]interface{}) {
+	if !l.Enabled(LevelTrace) {
+		return
+	}
+	l.log(LevelTrace, skip+1, label, withFields(fields, map[string]interface{}{
+		HexdumpField: Hexdump(data),
+		SizeField:    len(data),
+	})...)
+}
+
+// TraceHexdump logs a hexdump of data to the default logger at Trace (see
+// Logger.TraceHexdump)
+func TraceHexdump(label string, data []byte, fields ...map[string]interface{}) {
+	GetLogger().hexdump(1, label, data, fields)
+}
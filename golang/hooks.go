@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// HookFunc is called with every entry logged at one of the levels it was
+// registered for. It may modify the entry before it reaches the outputs.
+// Entries a hook logs itself don't run the hooks again.
+type HookFunc func(entry *LogEntry) error
+
+// hook is a registered HookFunc together with the levels it fires for
+type hook struct {
+	levels uint32 // Bit mask of levels, bit n set for Level(n)
+	fn     HookFunc
+}
+
+// hookRegistry holds the hooks shared by a logger and everything derived from it
+type hookRegistry struct {
+	mu    sync.RWMutex
+	hooks []hook
+}
+
+func newHookRegistry() *hookRegistry {
+	return &hookRegistry{}
+}
+
+// add registers fn for the given levels; no levels means all levels
+func (r *hookRegistry) add(levels []Level, fn HookFunc) {
+	var mask uint32
+	if len(levels) == 0 {
+		mask = ^uint32(0)
+	}
+	for _, level := range levels {
+		if level >= 0 && level < 32 {
+			mask |= 1 << uint(level)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Copy on write so fire can iterate without holding the lock
+	hooks := make([]hook, len(r.hooks), len(r.hooks)+1)
+	copy(hooks, r.hooks)
+	r.hooks = append(hooks, hook{levels: mask, fn: fn})
+}
+
+// fire runs the hooks registered for level and returns their errors. Entries
+// logged by the hooks themselves skip them, since they would fire again
+// without end.
+func (r *hookRegistry) fire(level Level, entry *LogEntry, guard *reentryGuard) error {
+	r.mu.RLock()
+	hooks := r.hooks
+	r.mu.RUnlock()
+
+	var errs []error
+	entered := false
+	for _, h := range hooks {
+		if level < 0 || level >= 32 || h.levels&(1<<uint(level)) == 0 {
+			continue
+		}
+		if !entered {
+			id, ok := guard.enterHooks()
+			if !ok {
+				return nil
+			}
+			defer guard.leaveHooks(id)
+			entered = true
+		}
+		entry.resolve()
+		if err := h.fn(entry); err != nil {
+			errs = append(errs, fmt.Errorf("log hook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterHook registers fn to be called for entries at the given levels
+// before they are handed to the outputs. Passing no levels registers the hook
+// for all levels. Hooks run on the logging goroutine and are shared with every
+// logger derived via With or WithFields.
+func (l *Logger) RegisterHook(levels []Level, fn HookFunc) {
+	l.hooks.add(levels, fn)
+}
@@ -0,0 +1,212 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxDumpBody is the number of body bytes HTTP dumps log unless
+// HTTPDumpOptions say otherwise
+const DefaultMaxDumpBody = 2048
+
+// DefaultRedactedHeaders are the headers HTTP dumps always mask
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// HTTPDumpOptions control what HTTP dumps log
+type HTTPDumpOptions struct {
+	// MaxBody is the number of body bytes logged, DefaultMaxDumpBody if 0;
+	// a negative value leaves bodies out
+	MaxBody int
+	// RedactHeaders lists headers masked besides DefaultRedactedHeaders
+	RedactHeaders []string
+}
+
+// maxBody returns the number of body bytes to log
+func (o *HTTPDumpOptions) maxBody() int {
+	if o.MaxBody == 0 {
+		return DefaultMaxDumpBody
+	}
+	return o.MaxBody
+}
+
+// redacted reports whether the values of a header are masked
+func (o *HTTPDumpOptions) redacted(name string) bool {
+	for _, h := range DefaultRedactedHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	for _, h := range o.RedactHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugRequest logs r at Debug with the default HTTPDumpOptions (see
+// DumpRequest)
+func (l *Logger) DebugRequest(r *http.Request) {
+	l.dumpRequest(1, LevelDebug, r, HTTPDumpOptions{})
+}
+
+// DebugResponse logs resp at Debug with the default HTTPDumpOptions (see
+// DumpResponse)
+func (l *Logger) DebugResponse(resp *http.Response) {
+	l.dumpResponse(1, LevelDebug, resp, HTTPDumpOptions{})
+}
+
+// DumpRequest logs an HTTP request at level, as "HTTP request" with its
+// "method", "url", "proto", "headers" and the start of its "body", instead
+// of hand-rolled dumps leaking credentials. Authorization, Cookie and the
+// other DefaultRedactedHeaders are masked, as is the password of the URL.
+// The body is read up to the size limit and put back, so r can still be
+// served or sent; "body_truncated" tells whether more followed. Bodies that
+// aren't text are logged as a Hexdump. Nothing is read unless level
+// is enabled.
+func (l *Logger) DumpRequest(level Level, r *http.Request, opts HTTPDumpOptions) {
+	l.dumpRequest(1, level, r, opts)
+}
+
+// DumpResponse logs an HTTP response at level, as "HTTP response" with its
+// "status", "status_code", "proto", "headers" and the start of its "body",
+// plus the "method" and "url" of its request, if known. Headers and bodies
+// are handled as by DumpRequest; the body is put back for the caller to
+// read.
+func (l *Logger) DumpResponse(level Level, resp *http.Response, opts HTTPDumpOptions) {
+	l.dumpResponse(1, level, resp, opts)
+}
+
+// dumpRequest is DumpRequest for callers skip frames up
+func (l *Logger) dumpRequest(skip int, level Level, r *http.Request, opts HTTPDumpOptions) {
+	if r == nil || !l.Enabled(level) {
+		return
+	}
+	fields := map[string]interface{}{
+		"method":  r.Method,
+		"url":     requestURL(r),
+		"proto":   r.Proto,
+		"headers": dumpHeaders(r.Header, &opts),
+	}
+	r.Body = dumpBody(fields, r.Body, &opts)
+	l.log(level, skip+1, "HTTP request", fields)
+}
+
+// dumpResponse is DumpResponse for callers skip frames up
+func (l *Logger) dumpResponse(skip int, level Level, resp *http.Response, opts HTTPDumpOptions) {
+	if resp == nil || !l.Enabled(level) {
+		return
+	}
+	fields := map[string]interface{}{
+		"status":      resp.Status,
+		"status_code": resp.StatusCode,
+		"proto":       resp.Proto,
+		"headers":     dumpHeaders(resp.Header, &opts),
+	}
+	if resp.Request != nil {
+		fields["method"] = resp.Request.Method
+		fields["url"] = requestURL(resp.Request)
+	}
+	resp.Body = dumpBody(fields, resp.Body, &opts)
+	l.log(level, skip+1, "HTTP response", fields)
+}
+
+// requestURL returns the URL of r with its password masked. Requests
+// received by servers only carry the path in their URL.
+func requestURL(r *http.Request) string {
+	if r.URL == nil {
+		return r.RequestURI
+	}
+	return r.URL.Redacted()
+}
+
+// dumpHeaders returns the headers as fields, joining repeated values and
+// masking redacted headers
+func dumpHeaders(h http.Header, opts *HTTPDumpOptions) map[string]interface{} {
+	fields := make(map[string]interface{}, len(h))
+	for name, values := range h {
+		if opts.redacted(name) {
+			fields[name] = RedactedValue
+			continue
+		}
+		fields[name] = strings.Join(values, ", ")
+	}
+	return fields
+}
+
+// dumpBody adds the start of body to fields and returns a body reading the
+// same bytes as body did
+func dumpBody(fields map[string]interface{}, body io.ReadCloser, opts *HTTPDumpOptions) io.ReadCloser {
+	limit := opts.maxBody()
+	if body == nil || body == http.NoBody || limit < 0 {
+		return body
+	}
+	// One byte past the limit tells whether the body was truncated
+	buf, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	truncated := len(buf) > limit
+	shown := buf[:min(len(buf), limit)]
+	if text, ok := bodyText(shown, truncated); ok {
+		fields["body"] = text
+	} else {
+		fields["body"] = Hexdump(shown)
+	}
+	fields["body_truncated"] = truncated
+	if err != nil {
+		fields["body_error"] = err.Error()
+	}
+	return &replayedBody{Reader: io.MultiReader(bytes.NewReader(buf), body), body: body}
+}
+
+// bodyText returns b as text if it is UTF-8 without control characters
+// other than tabs and line breaks, ignoring a rune cut short by truncation
+func bodyText(b []byte, truncated bool) (string, bool) {
+	for _, c := range b {
+		if (c < 0x20 && c != '\t' && c != '\n' && c != '\r') || c == 0x7f {
+			return "", false
+		}
+	}
+	if utf8.Valid(b) {
+		return string(b), true
+	}
+	if truncated {
+		for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+			if utf8.Valid(b[:len(b)-i]) {
+				return string(b[:len(b)-i]), true
+			}
+		}
+	}
+	return "", false
+}
+
+// replayedBody reads the bytes read for a dump, then the rest of the body
+type replayedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+// Close closes the original body
+func (b *replayedBody) Close() error {
+	return b.body.Close()
+}
+
+// DebugRequest logs r to the default logger at Debug (see
+// Logger.DumpRequest)
+func DebugRequest(r *http.Request) {
+	GetLogger().dumpRequest(1, LevelDebug, r, HTTPDumpOptions{})
+}
+
+// DebugResponse logs resp to the default logger at Debug (see
+// Logger.DumpResponse)
+func DebugResponse(resp *http.Response) {
+	GetLogger().dumpResponse(1, LevelDebug, resp, HTTPDumpOptions{})
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxInterned caps the number of strings the intern table holds, so keys
+// built from unbounded data can't grow it forever. Strings seen after it is
+// full are encoded each time.
+const maxInterned = 4096
+
+// maxInternedLen is the longest string worth interning
+const maxInternedLen = 64
+
+// internTable caches the JSON encoding of strings that recur in nearly every
+// entry, such as field keys, component names and level names, so encoding
+// copies cached bytes instead of scanning and escaping the same strings over
+// and over. Lookups read an immutable map without locking; adding a string
+// copies the map, which stops happening once the working set is cached.
+type internTable struct {
+	mu      sync.Mutex
+	encoded atomic.Pointer[map[string][]byte]
+}
+
+// interned holds the strings of the JSON encoder
+var interned internTable
+
+// appendJSON appends s as a quoted JSON string like appendJSONString,
+// interning it on first use
+func (t *internTable) appendJSON(buf []byte, s string) []byte {
+	if len(s) > maxInternedLen {
+		return appendJSONString(buf, s)
+	}
+	if m := t.encoded.Load(); m != nil {
+		if enc, ok := (*m)[s]; ok {
+			return append(buf, enc...)
+		}
+	}
+
+	start := len(buf)
+	buf = appendJSONString(buf, s)
+	t.add(s, buf[start:])
+	return buf
+}
+
+// add interns the encoding of s unless the table is full
+func (t *internTable) add(s string, enc []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var current map[string][]byte
+	if m := t.encoded.Load(); m != nil {
+		current = *m
+	}
+	if _, ok := current[s]; ok || len(current) >= maxInterned {
+		return
+	}
+
+	next := make(map[string][]byte, len(current)+1)
+	for k, v := range current {
+		next[k] = v
+	}
+	// Clone so the table doesn't pin the caller's memory
+	next[strings.Clone(s)] = append([]byte(nil), enc...)
+	t.encoded.Store(&next)
+}
+
+func init() {
+	// Level names are in every entry
+	for _, level := range AllLevels {
+		interned.appendJSON(nil, level.String())
+	}
+}
@@ -0,0 +1,433 @@
+package logger
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonEncoder appends JSON directly to a byte slice. It implements both
+// ObjectEncoder and ArrayEncoder; separators are inserted as needed.
+type jsonEncoder struct {
+	buf []byte
+}
+
+// jsonEncoders recycles encoders, which escape to the heap since marshalers
+// receive them as interfaces
+var jsonEncoders = sync.Pool{
+	New: func() interface{} {
+		return &jsonEncoder{}
+	},
+}
+
+// getJSONEncoder returns an encoder appending to buf
+func getJSONEncoder(buf []byte) *jsonEncoder {
+	e := jsonEncoders.Get().(*jsonEncoder)
+	e.buf = buf
+	return e
+}
+
+// putJSONEncoder releases an encoder; its buffer is not retained
+func putJSONEncoder(e *jsonEncoder) {
+	e.buf = nil
+	jsonEncoders.Put(e)
+}
+
+// separate adds a comma unless the value is the first in its object or array
+func (e *jsonEncoder) separate() {
+	if n := len(e.buf); n > 0 {
+		switch e.buf[n-1] {
+		case '{', '[', ':':
+		default:
+			e.buf = append(e.buf, ',')
+		}
+	}
+}
+
+// key starts a member of the current object
+func (e *jsonEncoder) key(key string) {
+	e.separate()
+	e.buf = interned.appendJSON(e.buf, key)
+	e.buf = append(e.buf, ':')
+}
+
+func (e *jsonEncoder) AddString(key, value string) {
+	e.key(key)
+	e.buf = appendJSONString(e.buf, value)
+}
+
+func (e *jsonEncoder) AddInt(key string, value int) {
+	e.AddInt64(key, int64(value))
+}
+
+func (e *jsonEncoder) AddInt64(key string, value int64) {
+	e.key(key)
+	e.buf = strconv.AppendInt(e.buf, value, 10)
+}
+
+func (e *jsonEncoder) AddUint64(key string, value uint64) {
+	e.key(key)
+	e.buf = strconv.AppendUint(e.buf, value, 10)
+}
+
+func (e *jsonEncoder) AddFloat64(key string, value float64) {
+	e.key(key)
+	e.buf = appendJSONFloat(e.buf, value)
+}
+
+func (e *jsonEncoder) AddBool(key string, value bool) {
+	e.key(key)
+	e.buf = strconv.AppendBool(e.buf, value)
+}
+
+func (e *jsonEncoder) AddTime(key string, value time.Time) {
+	e.key(key)
+	e.appendTime(value)
+}
+
+func (e *jsonEncoder) AddDuration(key string, value time.Duration) {
+	e.AddInt64(key, int64(value))
+}
+
+func (e *jsonEncoder) AddObject(key string, value ObjectMarshaler) error {
+	e.key(key)
+	return e.appendObject(value)
+}
+
+func (e *jsonEncoder) AddArray(key string, value ArrayMarshaler) error {
+	e.key(key)
+	return e.appendArray(value)
+}
+
+func (e *jsonEncoder) AddReflected(key string, value interface{}) error {
+	e.key(key)
+	return e.appendReflected(value)
+}
+
+func (e *jsonEncoder) AppendString(value string) {
+	e.separate()
+	e.buf = appendJSONString(e.buf, value)
+}
+
+func (e *jsonEncoder) AppendInt(value int) {
+	e.AppendInt64(int64(value))
+}
+
+func (e *jsonEncoder) AppendInt64(value int64) {
+	e.separate()
+	e.buf = strconv.AppendInt(e.buf, value, 10)
+}
+
+func (e *jsonEncoder) AppendUint64(value uint64) {
+	e.separate()
+	e.buf = strconv.AppendUint(e.buf, value, 10)
+}
+
+func (e *jsonEncoder) AppendFloat64(value float64) {
+	e.separate()
+	e.buf = appendJSONFloat(e.buf, value)
+}
+
+func (e *jsonEncoder) AppendBool(value bool) {
+	e.separate()
+	e.buf = strconv.AppendBool(e.buf, value)
+}
+
+func (e *jsonEncoder) AppendTime(value time.Time) {
+	e.separate()
+	e.appendTime(value)
+}
+
+func (e *jsonEncoder) AppendDuration(value time.Duration) {
+	e.AppendInt64(int64(value))
+}
+
+func (e *jsonEncoder) AppendObject(value ObjectMarshaler) error {
+	e.separate()
+	return e.appendObject(value)
+}
+
+func (e *jsonEncoder) AppendArray(value ArrayMarshaler) error {
+	e.separate()
+	return e.appendArray(value)
+}
+
+func (e *jsonEncoder) AppendReflected(value interface{}) error {
+	e.separate()
+	return e.appendReflected(value)
+}
+
+// appendTime appends a time in RFC 3339 format with nanoseconds
+func (e *jsonEncoder) appendTime(t time.Time) {
+	e.buf = append(e.buf, '"')
+	e.buf = t.AppendFormat(e.buf, time.RFC3339Nano)
+	e.buf = append(e.buf, '"')
+}
+
+// appendObject appends an ObjectMarshaler as a JSON object
+func (e *jsonEncoder) appendObject(value ObjectMarshaler) error {
+	e.buf = append(e.buf, '{')
+	err := value.MarshalLogObject(e)
+	e.buf = append(e.buf, '}')
+	return err
+}
+
+// appendArray appends an ArrayMarshaler as a JSON array
+func (e *jsonEncoder) appendArray(value ArrayMarshaler) error {
+	e.buf = append(e.buf, '[')
+	err := value.MarshalLogArray(e)
+	e.buf = append(e.buf, ']')
+	return err
+}
+
+// appendReflected appends any value, using the marshaler interfaces when
+// implemented and encoding/json otherwise
+func (e *jsonEncoder) appendReflected(value interface{}) error {
+	return e.appendValue(currentEncoders(), value)
+}
+
+// appendValue appends a field value the way encoding/json would encode it
+// after applying the registered field encoders. Common types are written
+// directly; only unusual ones fall back to encoding/json.
+func (e *jsonEncoder) appendValue(encoders *fieldEncoders, value interface{}) error {
+	if lazy, ok := value.(*LazyValue); ok {
+		value = lazy.Value()
+	}
+	if encoders != nil {
+		if enc := encoders.encoderFor(value); enc != nil {
+			return e.appendMarshaled(enc(value))
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		e.buf = append(e.buf, "null"...)
+	case string:
+		e.buf = appendJSONString(e.buf, v)
+	case bool:
+		e.buf = strconv.AppendBool(e.buf, v)
+	case int:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int8:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int16:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int32:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int64:
+		e.buf = strconv.AppendInt(e.buf, v, 10)
+	case uint:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint8:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint16:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint32:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint64:
+		e.buf = strconv.AppendUint(e.buf, v, 10)
+	case float64:
+		e.buf = appendJSONFloat(e.buf, v)
+	case time.Time:
+		e.appendTime(v)
+	case time.Duration:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case json.RawMessage:
+		if !json.Valid(v) {
+			return e.appendMarshaled(v)
+		}
+		e.buf = append(e.buf, v...)
+	case map[string]interface{}:
+		return e.appendFields(encoders, v)
+	case []interface{}:
+		e.buf = append(e.buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			if err := e.appendValue(encoders, item); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, ']')
+	case ObjectMarshaler:
+		return e.appendObject(v)
+	case ArrayMarshaler:
+		return e.appendArray(v)
+	default:
+		return e.appendMarshaled(v)
+	}
+	return nil
+}
+
+// appendMarshaled appends a value encoded by encoding/json
+func (e *jsonEncoder) appendMarshaled(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		e.buf = append(e.buf, "null"...)
+		return err
+	}
+	e.buf = append(e.buf, data...)
+	return nil
+}
+
+// appendFields appends fields as a JSON object with sorted keys, matching
+// encoding/json
+func (e *jsonEncoder) appendFields(encoders *fieldEncoders, fields map[string]interface{}) error {
+	var scratch [32]string
+	keys := scratch[:0]
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	e.buf = append(e.buf, '{')
+	for i, k := range keys {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = interned.appendJSON(e.buf, k)
+		e.buf = append(e.buf, ':')
+		if err := e.appendValue(encoders, fields[k]); err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+// appendEntryJSON appends an entry encoded as a JSON object, producing the
+// same output as encoding/json with the registered field encoders applied,
+// but without reflection or intermediate maps
+func appendEntryJSON(buf []byte, entry *LogEntry) ([]byte, error) {
+	e := getJSONEncoder(buf)
+	defer putJSONEncoder(e)
+	e.buf = append(e.buf, `{"timestamp":"`...)
+	e.buf = jsonTimes.appendTime(e.buf, entry.Timestamp)
+	e.buf = append(e.buf, '"')
+	e.buf = append(e.buf, `,"level":`...)
+	e.buf = interned.appendJSON(e.buf, entry.Level)
+	e.buf = append(e.buf, `,"message":`...)
+	e.buf = appendJSONString(e.buf, entry.Message)
+	if entry.Component != "" {
+		e.buf = append(e.buf, `,"component":`...)
+		e.buf = interned.appendJSON(e.buf, entry.Component)
+	}
+	if entry.File != "" {
+		e.buf = append(e.buf, `,"file":`...)
+		e.buf = appendJSONString(e.buf, entry.File)
+	}
+	if entry.Line != 0 {
+		e.buf = append(e.buf, `,"line":`...)
+		e.buf = strconv.AppendInt(e.buf, int64(entry.Line), 10)
+	}
+	if len(entry.Fields) > 0 {
+		e.buf = append(e.buf, `,"fields":`...)
+		if err := e.appendEntryFields(currentEncoders(), entry); err != nil {
+			return buf, err
+		}
+	}
+	if entry.InstanceID != "" {
+		e.buf = append(e.buf, `,"instance_id":`...)
+		e.buf = appendJSONString(e.buf, entry.InstanceID)
+	}
+	e.buf = append(e.buf, '}')
+	return e.buf, nil
+}
+
+// appendEntryFields appends the fields of an entry as a JSON object, in the
+// order they were added (see LogEntry.FieldList)
+func (e *jsonEncoder) appendEntryFields(encoders *fieldEncoders, entry *LogEntry) error {
+	var err error
+	first := true
+	e.buf = append(e.buf, '{')
+	entry.rangeFields(func(k string, v interface{}) bool {
+		if !first {
+			e.buf = append(e.buf, ',')
+		}
+		first = false
+		e.buf = interned.appendJSON(e.buf, k)
+		e.buf = append(e.buf, ':')
+		err = e.appendValue(encoders, v)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+// appendFieldsJSON appends the fields of an entry encoded as a JSON object
+// (see appendEntryJSON)
+func appendFieldsJSON(buf []byte, entry *LogEntry) ([]byte, error) {
+	e := getJSONEncoder(buf)
+	defer putJSONEncoder(e)
+	if err := e.appendEntryFields(currentEncoders(), entry); err != nil {
+		return buf, err
+	}
+	return e.buf, nil
+}
+
+// appendJSONFloat appends a float the way encoding/json does. NaN and
+// infinities, which JSON cannot represent, are written as strings.
+func appendJSONFloat(buf []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	return strconv.AppendFloat(buf, f, format, -1, 64)
+}
+
+// hexDigits are used to escape control characters
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a quoted JSON string, escaping quotes,
+// backslashes and control characters and replacing invalid UTF-8
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `�`...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LazyValue holds a value whose computation is deferred until it is needed.
+//
+// Used as a field value, the function only runs when an output encodes the
+// entry, so entries rejected by level checks, sampling or filters never pay
+// for it. Used as a format argument (e.g. Debugf("state: %v", Lazy(fn))), it
+// only runs once the entry has passed the level and sampling checks.
+// The result is computed at most once and shared by all outputs.
+type LazyValue struct {
+	once  sync.Once
+	fn    func() interface{}
+	value interface{}
+}
+
+// Lazy wraps fn into a value that is evaluated on first use
+func Lazy(fn func() interface{}) *LazyValue {
+	return &LazyValue{fn: fn}
+}
+
+// Value evaluates the wrapped function on first call and returns its result
+func (v *LazyValue) Value() interface{} {
+	v.once.Do(func() {
+		if v.fn != nil {
+			v.value = v.fn()
+		}
+	})
+	return v.value
+}
+
+// String implements fmt.Stringer so lazy values can be used as format arguments
+func (v *LazyValue) String() string {
+	return fmt.Sprint(v.Value())
+}
+
+// MarshalJSON implements json.Marshaler so lazy values are resolved on encoding
+func (v *LazyValue) MarshalJSON() ([]byte, error) {
+	var e jsonEncoder
+	err := e.appendValue(currentEncoders(), v.Value())
+	return e.buf, err
+}
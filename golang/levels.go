@@ -0,0 +1,386 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// MaxLoggerNames bounds the logger names recorded for LoggerNames; names
+// past it, as when components are built from request data, are not recorded
+const MaxLoggerNames = 10000
+
+// levelRegistry tracks named loggers and the levels configured for them.
+// Names are dot-separated paths such as "server.http.handlers"; a level set
+// on a name applies to all of its descendants unless they have their own.
+// Levels may also be set for glob patterns such as "db.*".
+//
+// The configured levels are an immutable snapshot replaced as a whole on
+// every change, so the level check made by every log call never locks.
+type levelRegistry struct {
+	mu       sync.RWMutex // Serializes changes and guards names and watchers
+	current  atomic.Pointer[levelSet]
+	names    map[string]struct{}
+	watchers []LevelChangeFunc // Copy on write
+}
+
+// levelSet is a snapshot of the configured levels. It is never modified
+// once published.
+type levelSet struct {
+	levels   map[string]Level
+	patterns []string // Keys of levels that are glob patterns, longest first
+}
+
+// newLevelSet builds a snapshot from levels, which it takes ownership of
+func newLevelSet(levels map[string]Level) *levelSet {
+	set := &levelSet{levels: levels}
+	for name := range levels {
+		if isPattern(name) {
+			set.patterns = append(set.patterns, name)
+		}
+	}
+	// Try the most specific (longest) pattern first
+	sort.Slice(set.patterns, func(i, j int) bool {
+		if len(set.patterns[i]) != len(set.patterns[j]) {
+			return len(set.patterns[i]) > len(set.patterns[j])
+		}
+		return set.patterns[i] < set.patterns[j]
+	})
+	return set
+}
+
+// levelsCopy returns a copy of the configured levels for building the next
+// snapshot; r.mu must be held
+func (r *levelRegistry) levelsCopy() map[string]Level {
+	current := r.current.Load().levels
+	levels := make(map[string]Level, len(current)+1)
+	for name, level := range current {
+		levels[name] = level
+	}
+	return levels
+}
+
+// LevelChangeFunc is called when the level in effect for a component changes.
+// The component is empty for the global level.
+type LevelChangeFunc func(component string, old, new Level)
+
+// levelChange is a change to report to level watchers
+type levelChange struct {
+	component string
+	old, new  Level
+}
+
+// isPattern reports whether a component name contains glob metacharacters
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+func newLevelRegistry() *levelRegistry {
+	r := &levelRegistry{names: make(map[string]struct{})}
+	r.current.Store(newLevelSet(nil))
+	return r
+}
+
+// register records a logger name so it can be enumerated later. Names
+// already known only take the read lock, as With is called on hot paths.
+func (r *levelRegistry) register(name string) {
+	if name == "" {
+		return
+	}
+	r.mu.RLock()
+	_, known := r.names[name]
+	full := len(r.names) >= MaxLoggerNames
+	r.mu.RUnlock()
+	if known || full {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.names) < MaxLoggerNames {
+		r.names[name] = struct{}{}
+	}
+}
+
+// set configures the level of a name (or pattern) and its descendants
+func (r *levelRegistry) set(name string, level Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	levels := r.levelsCopy()
+	levels[name] = level
+	r.current.Store(newLevelSet(levels))
+}
+
+// get returns the level configured for exactly this name, if any
+func (r *levelRegistry) get(name string) (Level, bool) {
+	level, exists := r.current.Load().levels[name]
+	return level, exists
+}
+
+// clear removes the level configured for a name, reporting whether one was set
+func (r *levelRegistry) clear(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.current.Load().levels[name]; !exists {
+		return false
+	}
+	levels := r.levelsCopy()
+	delete(levels, name)
+	r.current.Store(newLevelSet(levels))
+	return true
+}
+
+// replace swaps all configured levels for the given set
+func (r *levelRegistry) replace(levels map[string]Level) {
+	next := make(map[string]Level, len(levels))
+	for name, level := range levels {
+		next[name] = level
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current.Store(newLevelSet(next))
+}
+
+// watch registers fn to be called on level changes
+func (r *levelRegistry) watch(fn LevelChangeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	watchers := make([]LevelChangeFunc, len(r.watchers), len(r.watchers)+1)
+	copy(watchers, r.watchers)
+	r.watchers = append(watchers, fn)
+}
+
+// notify calls the watchers for every change that actually altered a level.
+// It must be called without holding any logger lock, since watchers may log.
+func (r *levelRegistry) notify(changes ...levelChange) {
+	r.mu.RLock()
+	watchers := r.watchers
+	r.mu.RUnlock()
+
+	for _, change := range changes {
+		if change.old == change.new {
+			continue
+		}
+		for _, fn := range watchers {
+			fn(change.component, change.old, change.new)
+		}
+	}
+}
+
+// lookup finds the level in effect for a name by walking up its ancestors.
+// At each step an exact match wins over patterns, and longer patterns win
+// over shorter ones.
+func (r *levelRegistry) lookup(name string) (Level, bool) {
+	set := r.current.Load()
+	if len(set.levels) == 0 {
+		return 0, false
+	}
+	for name != "" {
+		if level, exists := set.levels[name]; exists {
+			return level, true
+		}
+		for _, pattern := range set.patterns {
+			if matched, _ := path.Match(pattern, name); matched {
+				return set.levels[pattern], true
+			}
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return 0, false
+}
+
+// snapshot returns a copy of the explicitly configured levels
+func (r *levelRegistry) snapshot() map[string]Level {
+	current := r.current.Load().levels
+	levels := make(map[string]Level, len(current))
+	for name, level := range current {
+		levels[name] = level
+	}
+	return levels
+}
+
+// registered returns the sorted names of all known loggers
+func (r *levelRegistry) registered() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Named creates a child logger whose component is this logger's component
+// extended by a dot and the given name, e.g. "server" -> "server.http"
+func (l *Logger) Named(name string) *Logger {
+	if l.component != "" {
+		name = l.component + "." + name
+	}
+	return l.With(name)
+}
+
+// ClearComponentLevel removes the level set for a component so it inherits
+// from its parent again. It reports whether a level was set.
+func (l *Logger) ClearComponentLevel(component string) bool {
+	old := l.ComponentLevel(component)
+	cleared := l.levels.clear(component)
+	l.levels.notify(levelChange{component, old, l.ComponentLevel(component)})
+	return cleared
+}
+
+// OnLevelChange registers fn to be called whenever the global level or a
+// component's level is changed, whether directly, by a level boost, or through
+// the admin endpoints or a configuration reload. Subsystems can use it to
+// refresh a cached "is debug enabled" flag or to log the change. The component
+// is empty for the global level; for component levels old and new are the
+// levels in effect for that component before and after the change. fn is
+// called synchronously by whoever changed the level and must not block.
+func (l *Logger) OnLevelChange(fn LevelChangeFunc) {
+	l.levels.watch(fn)
+}
+
+// ComponentLevel returns the level in effect for a component, taking levels
+// set on its ancestors and the global level into account
+func (l *Logger) ComponentLevel(component string) Level {
+	if level, ok := l.levels.lookup(component); ok {
+		return level
+	}
+	return l.GetLevel()
+}
+
+// ComponentLevels returns the levels explicitly set per component
+func (l *Logger) ComponentLevels() map[string]Level {
+	return l.levels.snapshot()
+}
+
+// ParseLevel converts a level name such as "debug", "WARN" or "error", or its
+// numeric value, into a Level
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "emerg", "emergency":
+		return LevelEmergency, nil
+	case "alert":
+		return LevelAlert, nil
+	case "crit", "critical":
+		return LevelCritical, nil
+	case "err", "error":
+		return LevelError, nil
+	case "warn", "warning":
+		return LevelWarning, nil
+	case "notice":
+		return LevelNotice, nil
+	case "info":
+		return LevelInfo, nil
+	case "debug":
+		return LevelDebug, nil
+	case "verb", "verbose":
+		return LevelVerbose, nil
+	case "trace":
+		return LevelTrace, nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < int(LevelEmergency) || n > int(LevelTrace) {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return Level(n), nil
+}
+
+// levelByName returns the level whose String is name, as found in
+// LogEntry.Level
+func levelByName(name string) (Level, bool) {
+	for _, level := range AllLevels {
+		if level.String() == name {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// ParseLevelSpec parses a comma-separated level specification such as
+// "info,db.*=debug,http=warn". An entry without a component sets the global
+// level, which is returned as nil if the spec does not contain one.
+func ParseLevelSpec(spec string) (*Level, map[string]Level, error) {
+	var global *Level
+	components := make(map[string]Level)
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, value, hasName := strings.Cut(item, "=")
+		if !hasName {
+			level, err := ParseLevel(item)
+			if err != nil {
+				return nil, nil, err
+			}
+			global = &level
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, nil, fmt.Errorf("missing component name in %q", item)
+		}
+		if isPattern(name) {
+			if _, err := path.Match(name, ""); err != nil {
+				return nil, nil, fmt.Errorf("invalid component pattern %q: %v", name, err)
+			}
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		components[name] = level
+	}
+
+	return global, components, nil
+}
+
+// SetLevelSpec applies a level specification such as "info,db.*=debug,http=warn"
+// (see ParseLevelSpec). Nothing is applied if the spec is invalid.
+func (l *Logger) SetLevelSpec(spec string) error {
+	global, components, err := ParseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+	if global != nil {
+		l.SetLevel(*global)
+	}
+	for name, level := range components {
+		l.SetComponentLevel(name, level)
+	}
+	return nil
+}
+
+// SetLevelSpecFromEnv applies the level specification held by the named
+// environment variable, e.g. SetLevelSpecFromEnv("LOG_LEVEL"). An unset or
+// empty variable leaves the levels unchanged.
+func (l *Logger) SetLevelSpecFromEnv(name string) error {
+	spec := os.Getenv(name)
+	if spec == "" {
+		return nil
+	}
+	if err := l.SetLevelSpec(spec); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+// LoggerNames returns the components of all loggers created via With or
+// Named, up to MaxLoggerNames of them
+func (l *Logger) LoggerNames() []string {
+	return l.levels.registered()
+}
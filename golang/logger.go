@@ -3,12 +3,14 @@
 package logger
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,6 +33,12 @@ const (
 	LevelTrace                  // Extremely detailed tracing
 )
 
+// AllLevels lists every log level, from most to least severe
+var AllLevels = []Level{
+	LevelEmergency, LevelAlert, LevelCritical, LevelError, LevelWarning,
+	LevelNotice, LevelInfo, LevelDebug, LevelVerbose, LevelTrace,
+}
+
 // String returns the string representation of the log level
 func (l Level) String() string {
 	switch l {
@@ -40,6 +48,8 @@ func (l Level) String() string {
 		return "ALERT"
 	case LevelCritical:
 		return "CRIT"
+	case LevelError:
+		return "ERROR"
 	case LevelWarning:
 		return "WARN"
 	case LevelNotice:
@@ -57,7 +67,9 @@ func (l Level) String() string {
 	}
 }
 
-// LogEntry represents a structured log entry
+// LogEntry represents a structured log entry. Its fields are held in the
+// Fields map, with the order they were added in kept alongside (see
+// FieldList); pooled entries reuse both.
 type LogEntry struct {
 	Timestamp  time.Time              `json:"timestamp"`
 	Level      string                 `json:"level"`
@@ -67,8 +79,32 @@ type LogEntry struct {
 	Line       int                    `json:"line,omitempty"`
 	Fields     map[string]interface{} `json:"fields,omitempty"`
 	InstanceID string                 `json:"instance_id,omitempty"`
+
+	// Context is the context the entry was logged with (see WithContext), or
+	// nil. Hooks and filters can use it, e.g. to find the active trace span.
+	Context context.Context `json:"-"`
+
+	keys   []string      // Keys of Fields in the order they were added
+	args   []interface{} // Arguments of Message when formatting is left to the worker
+	format string        // Message before formatting, for printf-style messages
+	out    *outputSet    // Set when the logger has its own outputs (see WithOptions)
+	flush  *flushBarrier // Set on flush sentinels
+	pooled bool          // Created by newEntry, returned to the pool once written
 }
 
+// SequenceField is the field holding the sequence number of an entry. Entries
+// are numbered consecutively, across all loggers derived from the same
+// logger, as they are queued for the outputs; entries rejected earlier by
+// levels, sampling, rate limits or filters take no number. A gap therefore
+// means entries were lost, e.g. dropped because the queue was full or in
+// transit downstream. Entries already holding a field by that name keep it
+// and take no number either.
+const SequenceField = "seq"
+
+// EntryIDField is the field holding the unique ID of an entry, when enabled
+// with SetEntryIDs
+const EntryIDField = "log_id"
+
 // OutputFormat defines how logs should be formatted
 type OutputFormat int
 
@@ -79,7 +115,8 @@ const (
 	FormatJSON
 )
 
-// Output defines where logs should be written
+// Output defines where logs should be written. Entries are recycled once
+// written, so Write must not keep the entry or its Fields after returning.
 type Output interface {
 	Write(entry *LogEntry) error
 	Close() error
@@ -94,6 +131,13 @@ type FileOutput struct {
 	maxSize        int64
 	currentSize    int64
 	rotateCallback func(string)
+	rotation       RotationMode
+
+	batchBytes int           // Max size of a coalesced write, 0 when not batching
+	batchDelay time.Duration // Max time entries are held across drains
+	pending    []byte
+	timer      *time.Timer
+	delayedErr error // Failure of a delayed write, returned by EndBatch
 }
 
 // NewFileOutput creates a new file output
@@ -115,6 +159,7 @@ func NewFileOutput(path string, format OutputFormat, maxSizeMB int) (*FileOutput
 		format:      format,
 		maxSize:     int64(maxSizeMB) * 1024 * 1024,
 		currentSize: info.Size(),
+		rotation:    defaultRotationMode,
 	}, nil
 }
 
@@ -128,44 +173,31 @@ func (o *FileOutput) Write(entry *LogEntry) error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	var data []byte
-	var err error
+	buf := getBuffer()
+	defer putBuffer(buf)
 
-	if o.format == FormatJSON {
-		data, err = json.Marshal(entry)
-		if err != nil {
-			return err
-		}
-		data = append(data, '\n')
-	} else {
-		// Text format
-		timeStr := entry.Timestamp.Format("2006-01-02 15:04:05.000")
-		location := ""
-		if entry.File != "" {
-			location = fmt.Sprintf(" [%s:%d]", entry.File, entry.Line)
-		}
-		component := ""
-		if entry.Component != "" {
-			component = " (" + entry.Component + ")"
-		}
-
-		line := fmt.Sprintf("%s [%s]%s%s %s", timeStr, entry.Level, component, location, entry.Message)
-		if len(entry.Fields) > 0 {
-			fieldsData, _ := json.Marshal(entry.Fields)
-			line += " " + string(fieldsData)
-		}
-		line += "\n"
-		data = []byte(line)
+	data, err := AppendEntry(*buf, entry, o.format)
+	if err != nil {
+		return err
 	}
+	*buf = data
 
 	// Check if we need to rotate the log file
-	if o.maxSize > 0 && o.currentSize+int64(len(data)) > o.maxSize {
+	if o.maxSize > 0 && o.currentSize+int64(len(o.pending)+len(data)) > o.maxSize {
+		// The held back entries still belong to the current file
+		if err := o.writePending(); err != nil {
+			return err
+		}
 		err := o.rotate()
 		if err != nil {
 			return err
 		}
 	}
 
+	if o.batchBytes > 0 {
+		return o.hold(data)
+	}
+
 	n, err := o.file.Write(data)
 	if err == nil {
 		o.currentSize += int64(n)
@@ -173,32 +205,31 @@ func (o *FileOutput) Write(entry *LogEntry) error {
 	return err
 }
 
-// rotate performs log rotation
-func (o *FileOutput) rotate() error {
-	if err := o.file.Close(); err != nil {
+// Rotate moves the current file aside with a timestamp suffix, like a
+// rotation on reaching the max size, and continues in a new file
+func (o *FileOutput) Rotate() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.writePending(); err != nil {
 		return err
 	}
+	return o.rotate()
+}
 
+// rotate performs log rotation; o.mu must be held
+func (o *FileOutput) rotate() error {
 	timestamp := time.Now().Format("20060102-150405")
 	rotatedPath := fmt.Sprintf("%s.%s", o.path, timestamp)
 
-	if err := os.Rename(o.path, rotatedPath); err != nil {
-		// Try to reopen the original file
-		var reopenErr error
-		o.file, reopenErr = os.OpenFile(o.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-		if reopenErr != nil {
-			return fmt.Errorf("failed to rotate log: %v and failed to reopen: %v", err, reopenErr)
-		}
-		return err
+	var err error
+	if o.rotation == RotateCopyTruncate {
+		err = o.rotateCopy(rotatedPath)
+	} else {
+		err = o.rotateRename(rotatedPath)
 	}
-
-	// Open a new log file
-	file, err := os.OpenFile(o.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
-
-	o.file = file
 	o.currentSize = 0
 
 	// Call rotation callback if set
@@ -209,10 +240,24 @@ func (o *FileOutput) rotate() error {
 	return nil
 }
 
+// Sync commits the written entries to stable storage
+func (o *FileOutput) Sync() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.writePending(); err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
 // Close closes the file output
 func (o *FileOutput) Close() error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
+	if err := o.writePending(); err != nil {
+		o.file.Close()
+		return err
+	}
 	return o.file.Close()
 }
 
@@ -236,58 +281,20 @@ func (o *ConsoleOutput) Write(entry *LogEntry) error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
+	buf := getBuffer()
+	defer putBuffer(buf)
+
 	if o.format == FormatJSON {
-		data, err := json.Marshal(entry)
+		data, err := appendEntryJSON(*buf, entry)
 		if err != nil {
 			return err
 		}
-		_, err = fmt.Fprintln(o.writer, string(data))
-		return err
-	}
-
-	// Text format with ANSI colors
-	var levelColor string
-	switch entry.Level {
-	case "EMERG", "ALERT", "CRIT":
-		levelColor = "\033[1;31m" // Bold Red
-	case "ERROR":
-		levelColor = "\033[31m" // Red
-	case "WARN":
-		levelColor = "\033[33m" // Yellow
-	case "NOTICE":
-		levelColor = "\033[1;34m" // Bold Blue
-	case "INFO":
-		levelColor = "\033[32m" // Green
-	case "DEBUG":
-		levelColor = "\033[36m" // Cyan
-	case "VERB", "TRACE":
-		levelColor = "\033[35m" // Magenta
-	default:
-		levelColor = "\033[0m" // Reset
-	}
-	resetColor := "\033[0m"
-
-	timeStr := entry.Timestamp.Format("2006-01-02 15:04:05.000")
-	location := ""
-	if entry.File != "" {
-		location = fmt.Sprintf(" \033[90m[%s:%d]\033[0m", entry.File, entry.Line)
-	}
-	component := ""
-	if entry.Component != "" {
-		component = " (" + entry.Component + ")"
-	}
-
-	line := fmt.Sprintf("%s [%s%s%s]%s%s %s",
-		timeStr,
-		levelColor, entry.Level, resetColor,
-		component, location, entry.Message)
-
-	if len(entry.Fields) > 0 {
-		fieldsData, _ := json.Marshal(entry.Fields)
-		line += " \033[90m" + string(fieldsData) + "\033[0m"
+		*buf = append(data, '\n')
+	} else {
+		*buf = appendTextEntry(*buf, entry, true)
 	}
 
-	_, err := fmt.Fprintln(o.writer, line)
+	_, err := o.writer.Write(*buf)
 	return err
 }
 
@@ -296,45 +303,221 @@ func (o *ConsoleOutput) Close() error {
 	return nil
 }
 
-// Logger is the main logging structure
+// Logger is the main logging structure. A logger is a handle over a core
+// shared with every logger derived from it through With, WithFields and
+// the like; the handle only holds what a derived logger may change, so
+// deriving one is cheap and never copies locks or shared maps.
 type Logger struct {
-	level           int32 // Atomic access
-	outputs         []Output
-	defaultFields   map[string]interface{}
-	instanceID      string
-	component       string
-	componentLevels map[string]Level
-	mu              sync.RWMutex
-	asyncQueue      chan *LogEntry
-	wg              sync.WaitGroup
-	done            chan struct{}
-	sampler         *rateSampler
+	*core
+	level         *levelVar  // Shared with derived loggers until detached
+	out           *outputSet // Shared with derived loggers until detached
+	defaultFields []Field
+	instanceID    string
+	component     string
+	groups        []string
+	mu            sync.RWMutex // Guards defaultFields
+	ctx           context.Context
+	callerSkip    int
+	settings      *loggerSettings
+	ctxLevel      Level // Minimum level carried by ctx, if hasCtxLevel
+	hasCtxLevel   bool
+	scope         *Scope       // Innermost scope opened with Begin, if any
+	options       *optionState // Only set while options are being applied
+}
+
+// core holds the state shared by a logger and everything derived from it:
+// the queues and workers, and the registries that apply to every entry
+type core struct {
+	levels     *levelRegistry
+	queues     []entryQueue // One per worker with component ordering, else one shared
+	wg         sync.WaitGroup
+	done       chan struct{}
+	sampler    *rateSampler
+	hooks      *hookRegistry
+	filters    *filterChain
+	redactor   *Redactor
+	stats      *loggerStats
+	dedup      *deduplicator
+	aggregator *errorAggregator
+	recorder   *flightRecorder
+	limiter    *rateLimiter
+	budget     *logBudget
+	audit      *auditTrail
+	extractors *extractorRegistry
+	boosts     *boostRegistry
+	lifecycle  *lifecycle
+	overflow   *overflowHandling
+	errs       *errorReporting
+	guard      *reentryGuard
+	outputSets outputRegistry
+
+	synchronous bool // Entries are written by the caller, see WithSynchronous
+}
+
+// levelVar holds the global level of a logger and the loggers derived from it
+type levelVar struct {
+	level int32 // Atomic access
+}
+
+// outputSet holds the outputs of a logger and the loggers derived from it
+type outputSet struct {
+	mu     sync.RWMutex
+	list   []Output            // Copy-on-write
+	own    bool                // Not the root's, so entries carry them to the workers
+	routes map[string][]Output // Component to outputs, copy-on-write
+	routed int32               // Atomic, whether routes holds any
+}
+
+// loggerStats holds counters shared by a logger and everything derived from it
+type loggerStats struct {
+	dropped  uint64                 // Atomic, entries dropped because the queue was full
+	sequence uint64                 // Atomic, sequence number of the last queued entry
+	levels   [LevelTrace + 1]uint64 // Atomic, entries queued per level
+	queueMax int64                  // Atomic, longest queue seen
+	outputs  sync.Map               // Output to *outputMetrics
+
+	rateMu      sync.Mutex // Guards the drop rate reported by Health
+	rateAt      time.Time  // When the drop rate was last measured
+	rateDropped uint64     // Entries dropped by then
+}
+
+// lifecycle tracks closing a logger shared with everything derived from it
+type lifecycle struct {
+	mu       sync.RWMutex // Held for reading while entries are queued
+	closed   bool
+	once     sync.Once
+	err      error         // Result of Close
+	workers  int32         // Atomic, number of workers started
+	running  int32         // Atomic, workers still running
+	stopped  chan struct{} // Closed once every worker has exited
+	abandon  int32         // Atomic, non-zero once Shutdown gave up draining
+	flushing chan struct{} // Held while a flush is in flight
+	exit     atomic.Value  // exitFuncBox, os.Exit if unset
+	crashDir atomic.Value  // string, directory of crash dumps if set
+}
+
+// loggerSettings holds runtime switches shared by a logger and everything
+// derived from it
+type loggerSettings struct {
+	noCaller     int32        // Atomic, non-zero when caller capture is disabled
+	errorEncoder atomic.Value // errorEncoderBox, EncodeError if unset
+	clock        atomic.Value // clockBox, SystemClock if unset
+	entryIDs     int32        // Atomic, non-zero when entries get unique IDs
+	development  int32        // Atomic, non-zero in development mode
+	formatMode   int32        // Atomic FormatMode
+	runtimeStats int32        // Atomic, non-zero when severe entries get runtime fields
+	ids          ulidGenerator
+}
+
+// QueueStats reports the state of the async queue
+type QueueStats struct {
+	Length   int    `json:"length"`   // Entries currently queued
+	Capacity int    `json:"capacity"` // Maximum number of queued entries
+	Dropped  uint64 `json:"dropped"`  // Entries dropped because the queue was full
+}
+
+// SamplingPolicy describes how entries sharing a sampling key are thinned out.
+// Within each Interval the First entries are all logged; after that only
+// every Thereafter-th entry is. A zero Interval never resets the count, and a
+// Thereafter of zero drops everything after the first entries.
+type SamplingPolicy struct {
+	First      int
+	Thereafter int
+	Interval   time.Duration
+}
+
+// ratePolicy returns the policy for a "1 in rate" sampling rate: the first
+// entry is always logged, then every rate-th one
+func ratePolicy(rate int) SamplingPolicy {
+	if rate < 1 {
+		rate = 1
+	}
+	return SamplingPolicy{First: 1, Thereafter: rate}
+}
+
+// samplerState tracks how many entries a key has seen in the current interval
+type samplerState struct {
+	count       int
+	windowStart time.Time
 }
 
 // rateSampler implements log sampling to reduce volume
 type rateSampler struct {
-	mu            sync.Mutex
-	samplingRates map[string]int
-	counters      map[string]int
+	floor     int32 // Atomic, entries at or above this severity are never sampled
+	scoped    int32 // Atomic, non-zero when component or level policies are set
+	mu        sync.Mutex
+	policies  map[string]SamplingPolicy // Policies requested at call sites
+	overrides map[string]SamplingPolicy // Configured policies, taking precedence over call sites
+	states    map[string]*samplerState
+	now       func() time.Time
+
+	components  map[string]SamplingPolicy // Policies for every entry of a component
+	levels      map[Level]SamplingPolicy  // Policies for every entry at a level
+	scopeStates map[string]*samplerState  // By "c" + component or "l" + level name
 }
 
 func newRateSampler() *rateSampler {
 	return &rateSampler{
-		samplingRates: make(map[string]int),
-		counters:      make(map[string]int),
+		floor:       int32(LevelError),
+		now:         time.Now,
+		policies:    make(map[string]SamplingPolicy),
+		overrides:   make(map[string]SamplingPolicy),
+		states:      make(map[string]*samplerState),
+		components:  make(map[string]SamplingPolicy),
+		levels:      make(map[Level]SamplingPolicy),
+		scopeStates: make(map[string]*samplerState),
 	}
 }
 
 // SetSamplingRate sets how often a log with a given key should be emitted
-// A rate of 1 means every log, 100 means 1 out of every 100 logs
+// A rate of 1 means every log, 100 means the first and then 1 out of every 100 logs
 func (s *rateSampler) SetSamplingRate(key string, rate int) {
-	if rate < 1 {
-		rate = 1
+	s.SetPolicy(key, ratePolicy(rate))
+}
+
+// SetPolicy sets the call-site policy of a key, resetting its state if it changed
+func (s *rateSampler) SetPolicy(key string, policy SamplingPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.policies[key] != policy {
+		s.policies[key] = policy
+		delete(s.states, key) // Reset state when the policy changes
+	}
+}
+
+// SetOverride configures a policy for a key that takes precedence over the
+// policy given at call sites. A nil policy removes the override.
+func (s *rateSampler) SetOverride(key string, policy *SamplingPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if policy == nil {
+		delete(s.overrides, key)
+	} else {
+		s.overrides[key] = *policy
+	}
+	delete(s.states, key)
+}
+
+// ReplaceOverrides swaps all configured policies for the given set
+func (s *rateSampler) ReplaceOverrides(policies map[string]SamplingPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides = make(map[string]SamplingPolicy, len(policies))
+	for key, policy := range policies {
+		s.overrides[key] = policy
 	}
+	s.states = make(map[string]*samplerState)
+}
+
+// Overrides returns a copy of the configured policies
+func (s *rateSampler) Overrides() map[string]SamplingPolicy {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.samplingRates[key] = rate
-	delete(s.counters, key) // Reset counter when rate changes
+	overrides := make(map[string]SamplingPolicy, len(s.overrides))
+	for key, policy := range s.overrides {
+		overrides[key] = policy
+	}
+	return overrides
 }
 
 // ShouldLog determines if a log with the given key should be emitted
@@ -342,144 +525,430 @@ func (s *rateSampler) ShouldLog(key string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	rate, exists := s.samplingRates[key]
-	if !exists || rate <= 1 {
-		return true // Log everything if no sampling rate is set
+	policy, exists := s.overrides[key]
+	if !exists {
+		policy, exists = s.policies[key]
+	}
+	if !exists {
+		return true // Log everything if no sampling policy is set
+	}
+	return policy.admit(s.states, key, s.now())
+}
+
+// admit counts an entry against the state of key and reports whether the
+// policy lets it through
+func (policy SamplingPolicy) admit(states map[string]*samplerState, key string, now time.Time) bool {
+	state := states[key]
+	if state == nil {
+		state = &samplerState{}
+		states[key] = state
 	}
 
-	counter, _ := s.counters[key]
-	counter = (counter + 1) % rate
-	s.counters[key] = counter
+	if state.windowStart.IsZero() || (policy.Interval > 0 && now.Sub(state.windowStart) >= policy.Interval) {
+		state.count = 0
+		state.windowStart = now
+	}
+	state.count++
 
-	return counter == 0 // Only log when counter is 0
+	if state.count <= policy.First {
+		return true
+	}
+	if policy.Thereafter < 1 {
+		return false
+	}
+	return (state.count-policy.First)%policy.Thereafter == 0
 }
 
-// NewLogger creates a new logger
-func NewLogger() *Logger {
+// New creates a new logger configured by the given options. Options are
+// validated and applied before the logger starts, so an invalid option
+// returns an error instead of leaving a partially configured logger.
+//
+//	l, err := logger.New(
+//		logger.WithLevel(logger.LevelDebug),
+//		logger.WithOutputs(logger.NewConsoleOutput(os.Stdout, logger.FormatJSON)),
+//		logger.WithDefaultFields(map[string]interface{}{"service": "checkout"}),
+//	)
+func New(opts ...Option) (*Logger, error) {
 	logger := &Logger{
-		level:           int32(LevelInfo),
-		outputs:         make([]Output, 0),
-		defaultFields:   make(map[string]interface{}),
-		componentLevels: make(map[string]Level),
-		asyncQueue:      make(chan *LogEntry, 1000),
-		done:            make(chan struct{}),
-		sampler:         newRateSampler(),
+		level:    &levelVar{level: int32(LevelInfo)},
+		out:      &outputSet{list: make([]Output, 0)},
+		settings: &loggerSettings{},
+		core: &core{
+			levels:     newLevelRegistry(),
+			done:       make(chan struct{}),
+			sampler:    newRateSampler(),
+			hooks:      newHookRegistry(),
+			filters:    newFilterChain(),
+			redactor:   NewRedactor(),
+			stats:      &loggerStats{rateAt: time.Now()},
+			audit:      newAuditTrail(),
+			extractors: newExtractorRegistry(),
+			boosts:     newBoostRegistry(),
+			errs:       newErrorReporting(),
+			guard:      &reentryGuard{},
+			recorder:   newFlightRecorder(),
+			lifecycle: &lifecycle{
+				stopped:  make(chan struct{}),
+				flushing: make(chan struct{}, 1),
+			},
+		},
+		options: &optionState{
+			creating:  true,
+			batchSize: DefaultBatchSize,
+		},
 	}
 
+	logger.outputSets.add(logger.out)
+	logger.dedup = newDeduplicator(logger.enqueue)
+	logger.aggregator = newErrorAggregator(logger)
+	logger.limiter = newRateLimiter(logger)
+	logger.budget = newLogBudget(logger)
+	logger.overflow = newOverflowHandling(logger)
+	logger.sampler.now = logger.now
+
 	// Generate a unique instance ID
 	logger.instanceID = fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
 
-	// Start background worker for async logging
-	logger.wg.Add(1)
-	go logger.processLogQueue()
+	for _, opt := range opts {
+		if err := opt(logger); err != nil {
+			return nil, err
+		}
+	}
+	logger.startWorkers(logger.options)
+	logger.options = nil
+
+	return logger, nil
+}
 
+// NewLogger creates a new logger configured by the given options, like New,
+// but panics if an option is invalid
+func NewLogger(opts ...Option) *Logger {
+	logger, err := New(opts...)
+	if err != nil {
+		panic(fmt.Sprintf("logger: %v", err))
+	}
 	return logger
 }
 
-// processLogQueue handles asynchronous logging
-func (l *Logger) processLogQueue() {
+// startWorkers creates the queues and starts the background workers
+func (l *Logger) startWorkers(opts *optionState) {
+	if opts.synchronous {
+		l.synchronous = true
+		close(l.lifecycle.stopped)
+		return
+	}
+
+	queueSize, workers := opts.queueSize, opts.workers
+	if queueSize == 0 {
+		queueSize = DefaultQueueSize
+		if !opts.fixed {
+			queueSize = autoQueueSize()
+		}
+	}
+	// Workers are only added to a shared queue, as adding them to ordered
+	// queues would reassign components
+	tuned := !opts.fixed && workers == 0 && !opts.ordered
+	if workers == 0 {
+		workers = DefaultWorkers
+	}
+
+	queues := 1
+	if opts.ordered {
+		// Each worker gets its own queue and its share of the capacity
+		queues = workers
+	}
+	size := (queueSize + queues - 1) / queues
+	l.queues = make([]entryQueue, queues)
+	for i := range l.queues {
+		if opts.channelQueue {
+			l.queues[i] = make(chanQueue, size)
+		} else {
+			l.queues[i] = newRingQueue(size)
+		}
+	}
+
+	l.lifecycle.workers = int32(workers)
+	l.lifecycle.running = int32(workers)
+	l.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go l.processLogQueue(l.queues[i%queues], opts.batchSize)
+	}
+
+	if tuned {
+		maxWorkers := opts.maxWorkers
+		if maxWorkers == 0 {
+			maxWorkers = autoMaxWorkers()
+		}
+		go l.tune(maxWorkers, opts.batchSize)
+	}
+}
+
+// processLogQueue handles asynchronous logging, writing entries taken from
+// the queue in batches of up to batchSize
+func (l *Logger) processLogQueue(queue entryQueue, batchSize int) {
 	defer l.wg.Done()
+	defer func() {
+		if atomic.AddInt32(&l.lifecycle.running, -1) == 0 {
+			close(l.lifecycle.stopped)
+		}
+	}()
+	defer l.guard.leaveWorker(l.guard.enterWorker())
 
+	batch := make([]*LogEntry, 0, batchSize)
 	for {
-		select {
-		case entry := <-l.asyncQueue:
-			l.writeLogEntry(entry)
-		case <-l.done:
+		entry, ok := queue.pop(l.done)
+		if !ok {
 			// Process remaining logs before exiting
 			for {
-				select {
-				case entry := <-l.asyncQueue:
-					l.writeLogEntry(entry)
-				default:
+				entry, ok := queue.tryPop()
+				if !ok {
 					return
 				}
+				l.writeBatch(fillBatch(queue, append(batch[:0], entry)))
 			}
 		}
+		l.writeBatch(fillBatch(queue, append(batch[:0], entry)))
 	}
 }
 
-// writeLogEntry writes a log entry to all outputs
-func (l *Logger) writeLogEntry(entry *LogEntry) {
-	l.mu.RLock()
-	outputs := l.outputs
-	l.mu.RUnlock()
+// fillBatch adds entries already waiting in the queue to batch, up to its
+// capacity. A flush sentinel ends the batch, so a worker never holds two.
+func fillBatch(queue entryQueue, batch []*LogEntry) []*LogEntry {
+	for batch[len(batch)-1].flush == nil && len(batch) < cap(batch) {
+		entry, ok := queue.tryPop()
+		if !ok {
+			break
+		}
+		batch = append(batch, entry)
+	}
+	return batch
+}
+
+// writeBatch writes entries to all outputs. A flush sentinel, which can only
+// end the batch, is handled once the entries before it are written.
+func (l *Logger) writeBatch(batch []*LogEntry) {
+	var sentinel *LogEntry
+	if last := batch[len(batch)-1]; last.flush != nil {
+		sentinel, batch = last, batch[:len(batch)-1]
+	}
+
+	if len(batch) > 0 {
+		// Hold the read lock for the whole batch so outputs being replaced
+		// are never closed while a write to them is in flight. Outputs are
+		// looked up under it, so an entry queued before an output was
+		// removed is not written to it.
+		l.out.mu.RLock()
+		var last []Output
+		var own *outputSet // Outputs of a derived logger, locked as well
+		for _, entry := range batch {
+			if atomic.LoadInt32(&l.lifecycle.abandon) != 0 {
+				// Shutdown ran out of time, drop the rest
+				atomic.AddUint64(&l.stats.dropped, 1)
+				continue
+			}
+			entry.resolve()
+			set := l.out
+			if entry.out != nil {
+				set = entry.out
+			}
+			if set != l.out && set != own {
+				// Entries of derived loggers with outputs of their own
+				// carry them; the batch of the set held so far ends
+				// before its lock is released
+				if last != nil {
+					l.endBatch(last)
+					last = nil
+				}
+				if own != nil {
+					own.mu.RUnlock()
+				}
+				own = set
+				own.mu.RLock()
+			}
+			outputs := set.outputsForLocked(entry.Component)
+			l.writeLogEntry(entry, outputs)
+			// Tell each distinct set of outputs the batch is done
+			if last != nil && !sameOutputs(outputs, last) {
+				l.endBatch(last)
+			}
+			last = outputs
+		}
+		l.endBatch(last)
+		if own != nil {
+			own.mu.RUnlock()
+		}
+		l.out.mu.RUnlock()
 
+		for _, entry := range batch {
+			releaseEntry(entry)
+		}
+	}
+
+	if sentinel != nil {
+		// A flush promises the entries before it are written, including
+		// those buffered outputs hold back
+		l.eachOutput(false, l.flushOutput)
+
+		if len(l.queues) == 1 && atomic.LoadInt32(&l.lifecycle.workers) > 1 {
+			// Workers sharing a queue each take one sentinel and wait for
+			// the others, so none is still writing an earlier entry
+			sentinel.flush.wait()
+		} else {
+			sentinel.flush.arrive()
+		}
+	}
+}
+
+// writeLogEntry writes a log entry to outputs; the lock of the set holding
+// them must be held for reading
+func (l *Logger) writeLogEntry(entry *LogEntry, outputs []Output) {
 	for _, output := range outputs {
-		if err := output.Write(entry); err != nil {
-			// If we can't write to the log, try to write to stderr
-			fmt.Fprintf(os.Stderr, "ERROR: Failed to write log: %v\n", err)
+		start := time.Now()
+		err := output.Write(entry)
+		l.stats.output(output).wrote(time.Since(start), err)
+		if err != nil {
+			l.reportError(output, err)
 		}
 	}
 }
 
+// Outputs are shared by a logger and the loggers derived from it, so adding
+// or removing one on any of them affects all, unless a logger was given its
+// own outputs with WithOptions or Detach. Entries are matched with outputs
+// by the workers as they write them, so changes apply to entries already
+// queued too.
+
 // AddOutput adds a new output destination
 func (l *Logger) AddOutput(output Output) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	l.outputs = append(l.outputs, output)
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.out.list = append(append(make([]Output, 0, len(l.out.list)+1), l.out.list...), output)
+}
+
+// RemoveOutput stops writing to output, including in component routes,
+// and reports whether it was one of the logger's outputs. The output is not
+// closed; once RemoveOutput returns no write to it is in flight, so the
+// caller can close it safely.
+func (l *Logger) RemoveOutput(output Output) bool {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	found := l.out.unrouteLocked(output)
+	for i, o := range l.out.list {
+		if o == output {
+			outputs := make([]Output, 0, len(l.out.list)-1)
+			outputs = append(outputs, l.out.list[:i]...)
+			l.out.list = append(outputs, l.out.list[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if found {
+		l.stats.forget(output)
+	}
+	return found
+}
+
+// SetOutputs replaces all outputs, leaving component routes as they are, and
+// returns the previous ones, e.g. to redirect logging to a new file. The
+// previous outputs are not closed; once SetOutputs returns no write to them
+// is in flight.
+func (l *Logger) SetOutputs(outputs ...Output) []Output {
+	replacement := append(make([]Output, 0, len(outputs)), outputs...)
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	previous := l.out.list
+	l.out.list = replacement
+	routed := l.out.routedLocked()
+	for _, o := range previous {
+		if !slices.Contains(replacement, o) && !slices.Contains(routed, o) {
+			l.stats.forget(o)
+		}
+	}
+	return previous
+}
+
+// ListOutputs returns the outputs entries are currently written to, those
+// of component routes included
+func (l *Logger) ListOutputs() []Output {
+	l.out.mu.RLock()
+	defer l.out.mu.RUnlock()
+	return append([]Output(nil), l.out.allLocked()...)
 }
 
 // SetLevel sets the global log level
 func (l *Logger) SetLevel(level Level) {
-	atomic.StoreInt32((*int32)(&l.level), int32(level))
+	old := l.swapLevel(level)
+	l.levels.notify(levelChange{"", old, level})
+}
+
+// swapLevel sets the global log level without notifying watchers and returns
+// the previous one
+func (l *Logger) swapLevel(level Level) Level {
+	return Level(atomic.SwapInt32(&l.level.level, int32(level)))
 }
 
 // GetLevel gets the current global log level
 func (l *Logger) GetLevel() Level {
-	return Level(atomic.LoadInt32((*int32)(&l.level)))
+	return Level(atomic.LoadInt32(&l.level.level))
 }
 
-// SetComponentLevel sets the log level for a specific component
+// SetComponentLevel sets the log level for a specific component.
+// Components are dot-separated names; the level also applies to all
+// descendants ("server" covers "server.http") unless they have their own.
+// The component may also be a glob pattern such as "db.*".
 func (l *Logger) SetComponentLevel(component string, level Level) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	l.componentLevels[component] = level
+	old := l.ComponentLevel(component)
+	l.levels.set(component, level)
+	l.levels.notify(levelChange{component, old, level})
 }
 
 // isLoggable checks if a message at the given level should be logged
 func (l *Logger) isLoggable(level Level, component string) bool {
-	// Check component-specific level first
-	if component != "" {
-		l.mu.RLock()
-		compLevel, exists := l.componentLevels[component]
-		l.mu.RUnlock()
+	// A level carried by the bound context adds verbosity for its request
+	if l.hasCtxLevel && level <= l.ctxLevel {
+		return true
+	}
 
-		if exists {
+	// Check the component and its ancestors first
+	if component != "" {
+		if compLevel, exists := l.levels.lookup(component); exists {
 			return level <= compLevel
 		}
 	}
 
 	// Fall back to global level
-	return level <= Level(atomic.LoadInt32((*int32)(&l.level)))
+	return level <= l.GetLevel()
 }
 
 // SetDefaultField sets a field that will be included in all log entries
 func (l *Logger) SetDefaultField(key string, value interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.defaultFields[key] = value
+	l.defaultFields = appendFieldList(l.defaultFields, Field{Key: key, Value: value})
 }
 
 // With creates a new logger with the given component
 func (l *Logger) With(component string) *Logger {
+	l.levels.register(component)
+
 	newLogger := &Logger{
-		level:           l.level,
-		outputs:         l.outputs,
-		instanceID:      l.instanceID,
-		component:       component,
-		componentLevels: l.componentLevels,
-		asyncQueue:      l.asyncQueue,
-		done:            l.done,
-		wg:              l.wg,
-		sampler:         l.sampler,
-	}
-
-	// Copy default fields
-	l.mu.RLock()
-	newLogger.defaultFields = make(map[string]interface{}, len(l.defaultFields))
-	for k, v := range l.defaultFields {
-		newLogger.defaultFields[k] = v
+		core:        l.core,
+		level:       l.level,
+		out:         l.out,
+		instanceID:  l.instanceID,
+		component:   component,
+		ctx:         l.ctx,
+		callerSkip:  l.callerSkip,
+		settings:    l.settings,
+		ctxLevel:    l.ctxLevel,
+		hasCtxLevel: l.hasCtxLevel,
+		groups:      l.groups,
+		scope:       l.scope,
 	}
+
+	// Share default fields, lists are copied when appended to
+	l.mu.RLock()
+	newLogger.defaultFields = l.defaultFields
 	l.mu.RUnlock()
 
 	return newLogger
@@ -488,29 +957,99 @@ func (l *Logger) With(component string) *Logger {
 // WithFields creates a new logger with additional default fields
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	newLogger := &Logger{
-		level:           l.level,
-		outputs:         l.outputs,
-		instanceID:      l.instanceID,
-		component:       l.component,
-		componentLevels: l.componentLevels,
-		asyncQueue:      l.asyncQueue,
-		done:            l.done,
-		wg:              l.wg,
-		sampler:         l.sampler,
-	}
-
-	// Copy and merge default fields
-	l.mu.RLock()
-	newLogger.defaultFields = make(map[string]interface{}, len(l.defaultFields)+len(fields))
-	for k, v := range l.defaultFields {
-		newLogger.defaultFields[k] = v
+		core:        l.core,
+		level:       l.level,
+		out:         l.out,
+		instanceID:  l.instanceID,
+		component:   l.component,
+		ctx:         l.ctx,
+		callerSkip:  l.callerSkip,
+		settings:    l.settings,
+		ctxLevel:    l.ctxLevel,
+		hasCtxLevel: l.hasCtxLevel,
+		groups:      l.groups,
+		scope:       l.scope,
 	}
+
+	// Merge default fields, lists are copied when appended to
+	l.mu.RLock()
+	newLogger.defaultFields = nestFieldList(l.defaultFields, l.groups, fields)
 	l.mu.RUnlock()
 
-	for k, v := range fields {
-		newLogger.defaultFields[k] = v
+	return newLogger
+}
+
+// WithFieldList creates a new logger with additional default fields, which
+// keep their order in the output
+func (l *Logger) WithFieldList(fields ...Field) *Logger {
+	newLogger := l.WithFields(nil)
+	if len(l.groups) == 0 {
+		newLogger.defaultFields = appendFieldList(newLogger.defaultFields, fields...)
+		return newLogger
 	}
 
+	nested := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		nested[f.Key] = f.Value
+	}
+	newLogger.defaultFields = nestFieldList(newLogger.defaultFields, l.groups, nested)
+	return newLogger
+}
+
+// Detach returns a logger like l with its own global level and outputs,
+// starting as copies of l's. Loggers created with With, WithFields and the
+// like share these with the logger they come from, so SetLevel or AddOutput
+// on any of them affects all; changes to a detached logger (and the loggers
+// derived from it) don't, and changes to l no longer reach it. Everything
+// else, such as component levels, hooks and the queue, stays shared, and its
+// outputs are flushed and closed along with l's.
+func (l *Logger) Detach() *Logger {
+	detached := l.WithFields(nil)
+	detached.level = &levelVar{level: int32(l.GetLevel())}
+	detached.out = l.out.fork()
+	detached.outputSets.add(detached.out)
+	return detached
+}
+
+// SetCallerEnabled turns capturing the caller's file and line on or off for
+// this logger and every logger derived from it. Capturing the caller costs a
+// stack walk per entry, which deployments that don't need file:line can save.
+// It can be changed at any time; it is enabled by default.
+func (l *Logger) SetCallerEnabled(enabled bool) {
+	var v int32
+	if !enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&l.settings.noCaller, v)
+}
+
+// CallerEnabled reports whether the caller's file and line are captured
+func (l *Logger) CallerEnabled() bool {
+	return atomic.LoadInt32(&l.settings.noCaller) == 0
+}
+
+// SetEntryIDs enables or disables stamping every entry of this logger and of
+// every logger derived from it with a unique, time-sortable ID (a ULID) in
+// the log_id field, so individual entries can be referenced from tickets or
+// error trackers and deduplicated downstream. It is disabled by default.
+func (l *Logger) SetEntryIDs(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&l.settings.entryIDs, v)
+}
+
+// WithCallerSkip creates a new logger that skips n additional stack frames
+// when capturing the caller, so helpers and wrapper packages built on top of
+// the logger report their callers' file and line instead of their own.
+// Skips accumulate, and negative values undo earlier skips.
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	newLogger := l.WithFields(nil)
+	newLogger.callerSkip += n
+	if newLogger.callerSkip < 0 {
+		newLogger.callerSkip = 0
+	}
 	return newLogger
 }
 
@@ -520,87 +1059,288 @@ func (l *Logger) WithField(key string, value interface{}) *Logger {
 }
 
 // log logs a message at the given level
-func (l *Logger) log(level Level, skip int, format string, args ...interface{}) {
+func (l *Logger) log(level Level, skip int, msg string, fields ...map[string]interface{}) {
+	if !l.allow(level) {
+		l.record(level, skip+1, msg, nil, fields)
+		return
+	}
+	l.write(level, skip+1, msg, nil, fields...)
+}
+
+// allow checks levels, component and level sampling, rate limits and the log
+// budget for an entry at the given level, charging the limits if it may be
+// logged
+func (l *Logger) allow(level Level) bool {
 	if !l.isLoggable(level, l.component) {
+		return false
+	}
+
+	exempt := l.exemptFromSampling(level)
+	if !exempt && !l.sampler.allowEntry(level, l.component) {
+		return false
+	}
+	if !exempt && !l.limiter.allow(level, l.component) {
+		return false
+	}
+
+	return l.budget.allow(level, exempt)
+}
+
+// Log logs a message at the given level with fields that keep their order
+// in the output
+func (l *Logger) Log(level Level, msg string, fields ...Field) {
+	if !l.allow(level) {
+		l.record(level, 1, msg, fields, nil)
 		return
 	}
+	l.write(level, 1, msg, fields)
+}
+
+// write builds an entry and passes it through filters, redaction, hooks and
+// deduplication to the queue, without checking levels or rate limits. The
+// per-message fields in list come first, in order, followed by those in
+// fields.
+func (l *Logger) write(level Level, skip int, msg string, list []Field, fields ...map[string]interface{}) {
+	l.writeMessage(level, skip+1, msg, nil, list, fields...)
+}
+
+// writeMessage is write for messages whose formatting is left to the worker,
+// if args is set
+func (l *Logger) writeMessage(level Level, skip int, msg string, args []interface{}, list []Field, fields ...map[string]interface{}) {
+	entry := l.buildEntry(level, skip+1, msg, args, list, fields)
 
-	entry := &LogEntry{
-		Timestamp:  time.Now(),
-		Level:      level.String(),
-		Component:  l.component,
-		InstanceID: l.instanceID,
+	// An output of a synchronous logger logging while it writes would
+	// deadlock on the locks held for the write, or recurse
+	if l.synchronous && l.guard.onWorker() {
+		l.drop(entry)
+		return
 	}
 
-	// Check if the last argument is a fields map
-	var fields map[string]interface{}
-	if len(args) > 0 {
-		lastArg := args[len(args)-1]
-		if fieldsMap, ok := lastArg.(map[string]interface{}); ok {
-			// Create a defensive copy of the fields map
-			fields = make(map[string]interface{}, len(fieldsMap))
-			for k, v := range fieldsMap {
-				fields[k] = v
-			}
-			// Remove the fields map from args for message formatting
-			args = args[:len(args)-1]
-		}
+	// Give filters a chance to drop or rewrite the entry
+	filtered, ok := l.filters.apply(entry)
+	if !ok {
+		releaseEntry(entry)
+		return
+	}
+	if filtered != entry {
+		// A substituted entry may share fields with the original, so
+		// neither goes back to the pool
+		filtered.pooled = false
+		entry = filtered
 	}
 
-	// Format the message
-	if len(args) > 0 {
-		entry.Message = fmt.Sprintf(format, args...)
-	} else {
-		entry.Message = format
+	// Count the entry towards the scopes it is logged in
+	if l.scope != nil {
+		l.scope.count(level)
+	}
+
+	// Stamp a unique ID before hooks run, so they can reference the entry
+	if atomic.LoadInt32(&l.settings.entryIDs) != 0 {
+		entry.setField(EntryIDField, l.settings.ids.next(entry.Timestamp))
+	}
+
+	// Describe the health of the process when something grave happens
+	if level <= LevelCritical && atomic.LoadInt32(&l.settings.runtimeStats) != 0 {
+		addRuntimeFields(entry)
+	}
+
+	// Mask sensitive data before anything leaves the logger
+	l.redactor.Redact(entry)
+
+	// Let hooks enrich or mirror the entry
+	if err := l.hooks.fire(level, entry, l.guard); err != nil {
+		l.reportError(nil, err)
+	}
+
+	// Count errors by fingerprint, dropping repeats if they are suppressed
+	if !l.aggregator.observe(level, entry) {
+		releaseEntry(entry)
+		return
 	}
 
-	// Add source file and line information
-	if pc, file, line, ok := runtime.Caller(skip + 1); ok {
-		entry.File = filepath.Base(file)
-		entry.Line = line
+	// Freeze the entry if it must not change once logged
+	if l.FormatMode() == EncodeInCaller {
+		encodeFieldValues(entry)
+	}
+
+	// Write what led to an error first
+	if level <= LevelError {
+		l.replayRecorded(entry.Component)
+	}
+
+	// Collapse repeats, then send to async queue
+	l.dedup.process(entry)
+
+	// Report earlier drops if the queue has room again
+	l.overflow.report(false)
+}
 
-		// Optionally add function name to fields
-		if l.isLoggable(LevelTrace, l.component) {
-			fn := runtime.FuncForPC(pc)
-			if fn != nil {
-				if entry.Fields == nil {
-					entry.Fields = make(map[string]interface{})
+// buildEntry builds the entry of a message logged skip frames up, with the
+// default fields, context fields and the given fields
+func (l *Logger) buildEntry(level Level, skip int, msg string, args []interface{}, list []Field, fields []map[string]interface{}) *LogEntry {
+	entry := newEntry()
+	entry.Timestamp = l.now()
+	entry.Level = level.String()
+	entry.Message = msg
+	entry.args = args
+	if args != nil {
+		entry.format = msg
+	}
+	entry.Component = l.component
+	entry.InstanceID = l.instanceID
+	entry.Context = l.ctx
+
+	// Add source file and line information unless disabled
+	if l.CallerEnabled() {
+		if pc, file, line, ok := runtime.Caller(skip + 1 + l.callerSkip); ok {
+			entry.File = filepath.Base(file)
+			entry.Line = line
+
+			// Optionally add function name to fields
+			if l.isLoggable(LevelTrace, l.component) {
+				fn := runtime.FuncForPC(pc)
+				if fn != nil {
+					entry.setField("func", filepath.Base(fn.Name()))
 				}
-				entry.Fields["func"] = filepath.Base(fn.Name())
 			}
 		}
 	}
 
+	// Entries of loggers with outputs of their own carry them to the workers
+	if l.out.own {
+		entry.out = l.out
+	}
+
 	// Add default fields
 	l.mu.RLock()
-	if len(l.defaultFields) > 0 {
-		if entry.Fields == nil {
-			entry.Fields = make(map[string]interface{}, len(l.defaultFields))
+	for _, f := range l.defaultFields {
+		entry.setField(f.Key, f.Value)
+	}
+	l.mu.RUnlock()
+
+	// Add fields extracted from the context, such as trace IDs
+	if l.ctx != nil {
+		for _, f := range l.extractors.extract(l.ctx) {
+			entry.addFields(nil, f)
 		}
-		for k, v := range l.defaultFields {
-			entry.Fields[k] = v
+	}
+
+	// Add per-message fields if provided, nested under the logger's groups
+	if len(list) > 0 {
+		if len(l.groups) == 0 {
+			for _, f := range list {
+				entry.setField(f.Key, f.Value)
+			}
+		} else {
+			nested := make(map[string]interface{}, len(list))
+			for _, f := range list {
+				nested[f.Key] = f.Value
+			}
+			entry.addFields(l.groups, nested)
 		}
 	}
-	l.mu.RUnlock()
+	for _, f := range fields {
+		entry.addFields(l.groups, f)
+	}
+
+	// Encode errors held in fields into structured values
+	if len(entry.Fields) > 0 && hasErrorValues(entry.Fields) {
+		encodeErrorFields(entry.Fields, l.errorEncoder())
+	}
+	return entry
+}
+
+// enqueue sends an entry to the async queue, applying the overflow policy if
+// the queue is full
+func (l *Logger) enqueue(entry *LogEntry) {
+	l.submit(entry, true)
+}
+
+// submit hands an entry to the async queue, or writes it in synchronous
+// mode, numbering it first if sequence is set
+func (l *Logger) submit(entry *LogEntry, sequence bool) {
+	// Number entries in the order they are handed to the queue, so gaps
+	// reveal entries lost later on
+	if sequence && entry.Fields == nil {
+		entry.Fields = make(map[string]interface{}, 1)
+	}
+	// Entries logged after Close are discarded
+	l.lifecycle.mu.RLock()
+	if l.lifecycle.closed {
+		l.lifecycle.mu.RUnlock()
+		releaseEntry(entry)
+		return
+	}
 
-	// Add per-message fields if provided
-	if fields != nil {
-		if entry.Fields == nil {
-			entry.Fields = make(map[string]interface{}, len(fields))
+	if sequence {
+		// A field of the caller's own by that name is left alone
+		if _, taken := entry.Fields[SequenceField]; !taken {
+			entry.Fields[SequenceField] = atomic.AddUint64(&l.stats.sequence, 1)
 		}
-		for k, v := range fields {
-			entry.Fields[k] = v
+	}
+
+	l.stats.logged(entry.Level)
+	if l.synchronous {
+		l.writeNow(entry)
+	} else if queue := l.queueFor(entry); !queue.tryPush(entry) {
+		l.queueFull(queue, entry)
+	} else {
+		l.stats.queued(queue.len())
+	}
+	l.lifecycle.mu.RUnlock()
+}
+
+// logf formats a message and logs it at the given level.
+// A trailing map[string]interface{} argument is treated as per-message fields.
+func (l *Logger) logf(level Level, skip int, format string, args ...interface{}) {
+	if !l.isLoggable(level, l.component) {
+		if l.recorder.captures(level) {
+			msg, fields := formatArgs(format, args)
+			l.record(level, skip+1, msg, nil, []map[string]interface{}{fields})
 		}
+		return
 	}
 
-	// Send to async queue
-	select {
-	case l.asyncQueue <- entry:
-		// Successfully queued
-	default:
-		// Queue is full, log to stderr as fallback
-		fmt.Fprintf(os.Stderr, "WARNING: Log queue full, dropping log: %s\n", entry.Message)
+	// Aggregated errors are fingerprinted by their format, so it is kept
+	if l.FormatMode() == FormatInWorker || l.aggregator.aggregates(level) {
+		if !l.allow(level) {
+			return
+		}
+		args, fields := splitArgs(args)
+		if len(args) == 0 {
+			args = nil
+		}
+		l.writeMessage(level, skip+1, format, args, nil, fields)
+		return
+	}
+
+	msg, fields := formatArgs(format, args)
+	l.log(level, skip+1, msg, fields)
+}
+
+// splitArgs separates a trailing map[string]interface{} argument, holding
+// per-message fields, from printf-style arguments
+func splitArgs(args []interface{}) ([]interface{}, map[string]interface{}) {
+	if len(args) > 0 {
+		if fieldsMap, ok := args[len(args)-1].(map[string]interface{}); ok {
+			return args[:len(args)-1], fieldsMap
+		}
+	}
+	return args, nil
+}
+
+// formatArgs formats a message from printf-style arguments. A trailing
+// map[string]interface{} argument is returned as per-message fields.
+func formatArgs(format string, args []interface{}) (string, map[string]interface{}) {
+	args, fields := splitArgs(args)
+
+	// Format the message, skipping format parsing when there is nothing to substitute
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
 	}
+
+	return msg, fields
 }
 
 // logWithSampling logs a message with rate limiting based on the sampling key
@@ -609,61 +1349,161 @@ func (l *Logger) logWithSampling(level Level, samplingKey string, skip int, form
 		return
 	}
 
-	if samplingKey != "" && !l.sampler.ShouldLog(samplingKey) {
+	if samplingKey != "" && !l.exemptFromSampling(level) && !l.sampler.ShouldLog(samplingKey) {
 		return
 	}
 
-	l.log(level, skip+1, format, args...)
+	l.logf(level, skip+1, format, args...)
+}
+
+// Emergency logs a message at emergency level without any formatting
+func (l *Logger) Emergency(msg string, fields ...map[string]interface{}) {
+	l.log(LevelEmergency, 1, msg, fields...)
+}
+
+// Emergencyf logs a formatted message at emergency level
+func (l *Logger) Emergencyf(format string, args ...interface{}) {
+	l.logf(LevelEmergency, 1, format, args...)
+}
+
+// Alert logs a message at alert level without any formatting
+func (l *Logger) Alert(msg string, fields ...map[string]interface{}) {
+	l.log(LevelAlert, 1, msg, fields...)
+}
+
+// Alertf logs a formatted message at alert level
+func (l *Logger) Alertf(format string, args ...interface{}) {
+	l.logf(LevelAlert, 1, format, args...)
 }
 
-// Emergency logs at emergency level
-func (l *Logger) Emergency(format string, args ...interface{}) {
-	l.log(LevelEmergency, 1, format, args...)
+// Critical logs a message at critical level without any formatting
+func (l *Logger) Critical(msg string, fields ...map[string]interface{}) {
+	l.log(LevelCritical, 1, msg, fields...)
 }
 
-// Alert logs at alert level
-func (l *Logger) Alert(format string, args ...interface{}) {
-	l.log(LevelAlert, 1, format, args...)
+// Criticalf logs a formatted message at critical level
+func (l *Logger) Criticalf(format string, args ...interface{}) {
+	l.logf(LevelCritical, 1, format, args...)
 }
 
-// Critical logs at critical level
-func (l *Logger) Critical(format string, args ...interface{}) {
-	l.log(LevelCritical, 1, format, args...)
+// Error logs a message at error level without any formatting
+func (l *Logger) Error(msg string, fields ...map[string]interface{}) {
+	l.log(LevelError, 1, msg, fields...)
 }
 
-// Error logs at error level
-func (l *Logger) Error(format string, args ...interface{}) {
-	l.log(LevelError, 1, format, args...)
+// Errorf logs a formatted message at error level
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, 1, format, args...)
 }
 
-// Warning logs at warning level
-func (l *Logger) Warning(format string, args ...interface{}) {
-	l.log(LevelWarning, 1, format, args...)
+// Warning logs a message at warning level without any formatting
+func (l *Logger) Warning(msg string, fields ...map[string]interface{}) {
+	l.log(LevelWarning, 1, msg, fields...)
 }
 
-// Notice logs at notice level
-func (l *Logger) Notice(format string, args ...interface{}) {
-	l.log(LevelNotice, 1, format, args...)
+// Warningf logs a formatted message at warning level
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.logf(LevelWarning, 1, format, args...)
 }
 
-// Info logs at info level
-func (l *Logger) Info(format string, args ...interface{}) {
-	l.log(LevelInfo, 1, format, args...)
+// Notice logs a message at notice level without any formatting
+func (l *Logger) Notice(msg string, fields ...map[string]interface{}) {
+	l.log(LevelNotice, 1, msg, fields...)
 }
 
-// Debug logs at debug level
-func (l *Logger) Debug(format string, args ...interface{}) {
-	l.log(LevelDebug, 1, format, args...)
+// Noticef logs a formatted message at notice level
+func (l *Logger) Noticef(format string, args ...interface{}) {
+	l.logf(LevelNotice, 1, format, args...)
 }
 
-// Verbose logs at verbose level
-func (l *Logger) Verbose(format string, args ...interface{}) {
-	l.log(LevelVerbose, 1, format, args...)
+// Info logs a message at info level without any formatting
+func (l *Logger) Info(msg string, fields ...map[string]interface{}) {
+	l.log(LevelInfo, 1, msg, fields...)
 }
 
-// Trace logs at trace level
-func (l *Logger) Trace(format string, args ...interface{}) {
-	l.log(LevelTrace, 1, format, args...)
+// Infof logs a formatted message at info level
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, 1, format, args...)
+}
+
+// Debug logs a message at debug level without any formatting
+func (l *Logger) Debug(msg string, fields ...map[string]interface{}) {
+	l.log(LevelDebug, 1, msg, fields...)
+}
+
+// Debugf logs a formatted message at debug level
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, 1, format, args...)
+}
+
+// Verbose logs a message at verbose level without any formatting
+func (l *Logger) Verbose(msg string, fields ...map[string]interface{}) {
+	l.log(LevelVerbose, 1, msg, fields...)
+}
+
+// Verbosef logs a formatted message at verbose level
+func (l *Logger) Verbosef(format string, args ...interface{}) {
+	l.logf(LevelVerbose, 1, format, args...)
+}
+
+// Trace logs a message at trace level without any formatting
+func (l *Logger) Trace(msg string, fields ...map[string]interface{}) {
+	l.log(LevelTrace, 1, msg, fields...)
+}
+
+// Tracef logs a formatted message at trace level
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.logf(LevelTrace, 1, format, args...)
+}
+
+// SetSamplingRate configures how often entries logged with the given sampling
+// key are emitted (the first, then 1 in rate), taking precedence over the rate
+// passed at the Sampled* call sites. A rate below 1 restores the call-site rate.
+func (l *Logger) SetSamplingRate(key string, rate int) {
+	if rate < 1 {
+		l.sampler.SetOverride(key, nil)
+		return
+	}
+	policy := ratePolicy(rate)
+	l.sampler.SetOverride(key, &policy)
+}
+
+// SetSamplingPolicy configures how entries logged with the given sampling key
+// are thinned out, taking precedence over the Sampled* call sites. For
+// example, SamplingPolicy{First: 10, Thereafter: 100, Interval: time.Minute}
+// logs the first 10 entries of every minute in full, then 1 in 100.
+func (l *Logger) SetSamplingPolicy(key string, policy SamplingPolicy) {
+	l.sampler.SetOverride(key, &policy)
+}
+
+// SamplingRates returns the sampling rates configured via SetSamplingRate or
+// SetSamplingPolicy, i.e. the Thereafter value of each configured policy
+func (l *Logger) SamplingRates() map[string]int {
+	policies := l.sampler.Overrides()
+	rates := make(map[string]int, len(policies))
+	for key, policy := range policies {
+		rates[key] = policy.Thereafter
+	}
+	return rates
+}
+
+// SamplingPolicies returns the sampling policies configured per key
+func (l *Logger) SamplingPolicies() map[string]SamplingPolicy {
+	return l.sampler.Overrides()
+}
+
+// SetNeverSampleLevel sets the severity at and above which entries are never
+// subject to sampling or rate limiting, regardless of the configured rates.
+// The default is LevelError; pass a negative level to let sampling and rate
+// limiting apply to every entry.
+func (l *Logger) SetNeverSampleLevel(level Level) {
+	atomic.StoreInt32(&l.sampler.floor, int32(level))
+}
+
+// exemptFromSampling reports whether entries at the given level bypass
+// sampling and rate limiting
+func (l *Logger) exemptFromSampling(level Level) bool {
+	return level <= Level(atomic.LoadInt32(&l.sampler.floor))
 }
 
 // SampledInfo logs at info level with rate limiting
@@ -684,99 +1524,329 @@ func (l *Logger) SampledDebug(key string, rate int, format string, args ...inter
 	l.logWithSampling(LevelDebug, key, 1, format, args...)
 }
 
-// Flush flushes all pending log entries
+// QueueStats returns the current state of the async queue
+func (l *Logger) QueueStats() QueueStats {
+	stats := QueueStats{Dropped: atomic.LoadUint64(&l.stats.dropped)}
+	for _, queue := range l.queues {
+		stats.Length += queue.len()
+		stats.Capacity += queue.cap()
+	}
+	return stats
+}
+
+// Flush waits until every entry queued so far has been written to the
+// outputs (see FlushContext)
 func (l *Logger) Flush() {
-	// Wait for the async queue to empty
-	for len(l.asyncQueue) > 0 {
-		time.Sleep(10 * time.Millisecond)
+	l.FlushContext(context.Background())
+}
+
+// FlushContext waits until every entry queued before the call has been
+// written by every output, or ctx is done, in which case it returns ctx's
+// error. Entries are taken from a queue in order, so FlushContext queues a
+// sentinel per worker behind them and waits until every sentinel has been
+// reached. It returns immediately once the logger is closed.
+func (l *Logger) FlushContext(ctx context.Context) error {
+	select {
+	case <-l.done:
+		return nil
+	default:
+	}
+	if l.guard.onWorker() {
+		// The worker would wait for its own sentinel
+		return ErrReentrant
+	}
+	if l.synchronous {
+		l.eachOutput(false, l.flushOutput)
+		return nil
+	}
+
+	// Run one flush at a time, so workers sharing a queue never wait at
+	// sentinels of different flushes; concurrent flushes wait their turn
+	select {
+	case l.lifecycle.flushing <- struct{}{}:
+	case <-l.lifecycle.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Holding the semaphore until the flush completes also keeps workers
+	// from being added while its sentinels are in flight
+	defer func() { <-l.lifecycle.flushing }()
+
+	workers := int(atomic.LoadInt32(&l.lifecycle.workers))
+	barrier := newFlushBarrier(workers)
+	for i := 0; i < workers; i++ {
+		sentinel := &LogEntry{flush: barrier}
+		if l.queues[i%len(l.queues)].push(sentinel, l.lifecycle.stopped, ctx.Done()) {
+			continue
+		}
+		// Release the workers waiting at the sentinels already sent
+		for ; i < workers; i++ {
+			barrier.arrive()
+		}
+		break
+	}
+
+	select {
+	case <-barrier.done:
+		return nil
+	case <-l.lifecycle.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-// Close closes the logger and all outputs
-func (l *Logger) Close() {
-	// Signal the worker to stop
+// Close writes the entries still queued, then closes the logger and all
+// outputs. Outputs that implement Syncer are synced first, so a nil error
+// means the final entries were committed. The returned error joins every
+// sync and close failure. Close can be called more than once, from any logger
+// derived from the same root, and always returns the first call's result.
+// Entries logged after Close are discarded.
+func (l *Logger) Close() error {
+	return l.Shutdown(context.Background())
+}
+
+// Shutdown is Close bounded by ctx. Producers are stopped from queueing
+// first, then the queue is drained and the outputs are synced and closed.
+// If ctx is done before the queue is drained, the entries still queued are
+// discarded and counted as dropped, the outputs are closed in the
+// background once the workers have stopped, and Shutdown returns an error
+// wrapping ctx's error. The number of entries it gives is a lower bound;
+// the error handler gets the actual number once the workers have stopped.
+// Like Close, only the first call shuts down; later calls, to either,
+// return its result. Called by an output or the error handler, it returns
+// ErrReentrant and shuts down in the background once the write in progress
+// is done.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	if l.guard.onWorker() {
+		// An output or the error handler closed the logger. The write it
+		// is part of has to finish first, so shut down once it has.
+		go func() {
+			if err := l.Shutdown(ctx); err != nil {
+				l.reportError(nil, err)
+			}
+		}()
+		return ErrReentrant
+	}
+	l.lifecycle.once.Do(func() {
+		l.lifecycle.err = l.shutdown(ctx)
+	})
+	return l.lifecycle.err
+}
+
+// shutdown stops the logger (see Shutdown)
+func (l *Logger) shutdown(ctx context.Context) error {
+	// Report entries still held back by deduplication and rate limits, and
+	// errors aggregated since the last summary
+	l.dedup.flush()
+	l.limiter.report()
+	l.aggregator.report()
+	l.budget.flush()
+	l.overflow.report(true)
+
+	// Stop accepting entries. Producers queue while holding the read lock,
+	// so once the write lock is held none is half way through a push.
+	l.lifecycle.mu.Lock()
+	l.lifecycle.closed = true
+	l.lifecycle.mu.Unlock()
+
+	// Let the workers drain the queue and exit
 	close(l.done)
 
-	// Wait for worker to finish
-	l.wg.Wait()
+	select {
+	case <-l.lifecycle.stopped:
+		return l.closeOutputs()
+	case <-ctx.Done():
+	}
 
-	// Close all outputs
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	// Out of time: drop what is left and close the outputs once the workers
+	// are done with them. Entries the workers already took from the queue
+	// are dropped too, so the queue length is only a lower bound; the
+	// actual count is reported once the workers have stopped.
+	dropped := atomic.LoadUint64(&l.stats.dropped)
+	atomic.StoreInt32(&l.lifecycle.abandon, 1)
+	queued := l.QueueStats().Length
+	go func() {
+		<-l.lifecycle.stopped
+		if abandoned := atomic.LoadUint64(&l.stats.dropped) - dropped; abandoned > 0 {
+			l.reportError(nil, fmt.Errorf("logger shutdown: dropped %d entries left unwritten", abandoned))
+		}
+		if err := l.closeOutputs(); err != nil {
+			l.reportError(nil, fmt.Errorf("closing outputs: %w", err))
+		}
+	}()
+	return fmt.Errorf("logger shutdown: %w with at least %d entries left unwritten", ctx.Err(), queued)
+}
+
+// closeOutputs syncs and closes every output once the workers have stopped
+func (l *Logger) closeOutputs() error {
+	// The queue is gone, so drops since the final summary go to stderr
+	if drops := atomic.SwapUint64(&l.overflow.drops, 0); drops > 0 {
+		fmt.Fprintf(os.Stderr, "WARNING: Log queue full, dropped %d entries\n", drops)
+	}
+
+	var errs []error
+
+	// Close all outputs, those of derived loggers included
+	l.eachOutput(true, func(output Output) {
+		errs = append(errs, closeOutput(output)...)
+	})
 
-	for _, output := range l.outputs {
-		output.Close()
+	// Close audit outputs
+	l.audit.mu.Lock()
+	defer l.audit.mu.Unlock()
+
+	for _, output := range l.audit.outputs {
+		errs = append(errs, closeOutput(output)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// closeOutput syncs output if it supports it, then closes it
+func closeOutput(output Output) []error {
+	var errs []error
+	if syncer, ok := output.(Syncer); ok {
+		if err := syncer.Sync(); err != nil {
+			errs = append(errs, fmt.Errorf("sync output: %w", err))
+		}
 	}
+	if err := output.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close output: %w", err))
+	}
+	return errs
 }
 
-// Default logger instance
-var defaultLogger *Logger
-var once sync.Once
+// Default logger instance, swapped atomically so package-level logging may
+// run concurrently with SetDefaultLogger
+var defaultLogger atomic.Pointer[Logger]
 
 // init initializes the default logger
 func init() {
-	once.Do(func() {
-		defaultLogger = NewLogger()
-		defaultLogger.AddOutput(NewConsoleOutput(os.Stdout, FormatText))
-	})
+	l := NewLogger()
+	l.AddOutput(NewConsoleOutput(os.Stdout, FormatText))
+	defaultLogger.Store(l)
 }
 
 // GetLogger returns the default logger
 func GetLogger() *Logger {
-	return defaultLogger
+	return defaultLogger.Load()
 }
 
-// SetDefaultLogger sets the default logger
+// SetDefaultLogger sets the default logger. The previous one stays open, see
+// SwapDefaultLogger to close it.
 func SetDefaultLogger(logger *Logger) {
-	defaultLogger = logger
+	defaultLogger.Store(logger)
+}
+
+// SwapDefaultLogger sets the default logger and returns the previous one,
+// e.g. to close it once nothing logs to it anymore:
+//
+//	if old := logger.SwapDefaultLogger(l); old != nil {
+//	    old.Close()
+//	}
+func SwapDefaultLogger(logger *Logger) *Logger {
+	return defaultLogger.Swap(logger)
+}
+
+// Emergency logs a message to the default logger at emergency level
+func Emergency(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelEmergency, 1, msg, fields...)
+}
+
+// Emergencyf logs a formatted message to the default logger at emergency level
+func Emergencyf(format string, args ...interface{}) {
+	GetLogger().logf(LevelEmergency, 1, format, args...)
+}
+
+// Alert logs a message to the default logger at alert level
+func Alert(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelAlert, 1, msg, fields...)
+}
+
+// Alertf logs a formatted message to the default logger at alert level
+func Alertf(format string, args ...interface{}) {
+	GetLogger().logf(LevelAlert, 1, format, args...)
+}
+
+// Critical logs a message to the default logger at critical level
+func Critical(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelCritical, 1, msg, fields...)
+}
+
+// Criticalf logs a formatted message to the default logger at critical level
+func Criticalf(format string, args ...interface{}) {
+	GetLogger().logf(LevelCritical, 1, format, args...)
+}
+
+// Error logs a message to the default logger at error level
+func Error(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelError, 1, msg, fields...)
+}
+
+// Errorf logs a formatted message to the default logger at error level
+func Errorf(format string, args ...interface{}) {
+	GetLogger().logf(LevelError, 1, format, args...)
+}
+
+// Warning logs a message to the default logger at warning level
+func Warning(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelWarning, 1, msg, fields...)
+}
+
+// Warningf logs a formatted message to the default logger at warning level
+func Warningf(format string, args ...interface{}) {
+	GetLogger().logf(LevelWarning, 1, format, args...)
 }
 
-// Emergency logs to the default logger at emergency level
-func Emergency(format string, args ...interface{}) {
-	defaultLogger.Emergency(format, args...)
+// Notice logs a message to the default logger at notice level
+func Notice(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelNotice, 1, msg, fields...)
 }
 
-// Alert logs to the default logger at alert level
-func Alert(format string, args ...interface{}) {
-	defaultLogger.Alert(format, args...)
+// Noticef logs a formatted message to the default logger at notice level
+func Noticef(format string, args ...interface{}) {
+	GetLogger().logf(LevelNotice, 1, format, args...)
 }
 
-// Critical logs to the default logger at critical level
-func Critical(format string, args ...interface{}) {
-	defaultLogger.Critical(format, args...)
+// Info logs a message to the default logger at info level
+func Info(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelInfo, 1, msg, fields...)
 }
 
-// Error logs to the default logger at error level
-func Error(format string, args ...interface{}) {
-	defaultLogger.Error(format, args...)
+// Infof logs a formatted message to the default logger at info level
+func Infof(format string, args ...interface{}) {
+	GetLogger().logf(LevelInfo, 1, format, args...)
 }
 
-// Warning logs to the default logger at warning level
-func Warning(format string, args ...interface{}) {
-	defaultLogger.Warning(format, args...)
+// Debug logs a message to the default logger at debug level
+func Debug(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelDebug, 1, msg, fields...)
 }
 
-// Notice logs to the default logger at notice level
-func Notice(format string, args ...interface{}) {
-	defaultLogger.Notice(format, args...)
+// Debugf logs a formatted message to the default logger at debug level
+func Debugf(format string, args ...interface{}) {
+	GetLogger().logf(LevelDebug, 1, format, args...)
 }
 
-// Info logs to the default logger at info level
-func Info(format string, args ...interface{}) {
-	defaultLogger.Info(format, args...)
+// Verbose logs a message to the default logger at verbose level
+func Verbose(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelVerbose, 1, msg, fields...)
 }
 
-// Debug logs to the default logger at debug level
-func Debug(format string, args ...interface{}) {
-	defaultLogger.Debug(format, args...)
+// Verbosef logs a formatted message to the default logger at verbose level
+func Verbosef(format string, args ...interface{}) {
+	GetLogger().logf(LevelVerbose, 1, format, args...)
 }
 
-// Verbose logs to the default logger at verbose level
-func Verbose(format string, args ...interface{}) {
-	defaultLogger.Verbose(format, args...)
+// Trace logs a message to the default logger at trace level
+func Trace(msg string, fields ...map[string]interface{}) {
+	GetLogger().log(LevelTrace, 1, msg, fields...)
 }
 
-// Trace logs to the default logger at trace level
-func Trace(format string, args ...interface{}) {
-	defaultLogger.Trace(format, args...)
+// Tracef logs a formatted message to the default logger at trace level
+func Tracef(format string, args ...interface{}) {
+	GetLogger().logf(LevelTrace, 1, format, args...)
 }
@@ -0,0 +1,75 @@
+package logger
+
+import "time"
+
+// ObjectMarshaler is implemented by types that log themselves as an object
+// by adding their fields to an ObjectEncoder, avoiding the reflection and
+// intermediate maps otherwise needed to encode struct values:
+//
+//	func (u User) MarshalLogObject(enc logger.ObjectEncoder) error {
+//		enc.AddInt64("id", u.ID)
+//		enc.AddString("name", u.Name)
+//		return nil
+//	}
+//
+//	l.Info("Signed up", map[string]interface{}{"user": user})
+//
+// The redactor applies to the fields added by marshalers as to any other.
+type ObjectMarshaler interface {
+	MarshalLogObject(enc ObjectEncoder) error
+}
+
+// ArrayMarshaler is implemented by types that log themselves as an array by
+// appending their elements to an ArrayEncoder
+type ArrayMarshaler interface {
+	MarshalLogArray(enc ArrayEncoder) error
+}
+
+// ObjectMarshalerFunc adapts a function to an ObjectMarshaler
+type ObjectMarshalerFunc func(enc ObjectEncoder) error
+
+// MarshalLogObject calls f(enc)
+func (f ObjectMarshalerFunc) MarshalLogObject(enc ObjectEncoder) error {
+	return f(enc)
+}
+
+// ArrayMarshalerFunc adapts a function to an ArrayMarshaler
+type ArrayMarshalerFunc func(enc ArrayEncoder) error
+
+// MarshalLogArray calls f(enc)
+func (f ArrayMarshalerFunc) MarshalLogArray(enc ArrayEncoder) error {
+	return f(enc)
+}
+
+// ObjectEncoder receives the fields of an ObjectMarshaler. Durations are
+// encoded as nanoseconds and times in RFC 3339 format, as for other fields.
+type ObjectEncoder interface {
+	AddString(key, value string)
+	AddInt(key string, value int)
+	AddInt64(key string, value int64)
+	AddUint64(key string, value uint64)
+	AddFloat64(key string, value float64)
+	AddBool(key string, value bool)
+	AddTime(key string, value time.Time)
+	AddDuration(key string, value time.Duration)
+	AddObject(key string, value ObjectMarshaler) error
+	AddArray(key string, value ArrayMarshaler) error
+	// AddReflected encodes any value, falling back to reflection
+	AddReflected(key string, value interface{}) error
+}
+
+// ArrayEncoder receives the elements of an ArrayMarshaler
+type ArrayEncoder interface {
+	AppendString(value string)
+	AppendInt(value int)
+	AppendInt64(value int64)
+	AppendUint64(value uint64)
+	AppendFloat64(value float64)
+	AppendBool(value bool)
+	AppendTime(value time.Time)
+	AppendDuration(value time.Duration)
+	AppendObject(value ObjectMarshaler) error
+	AppendArray(value ArrayMarshaler) error
+	// AppendReflected encodes any value, falling back to reflection
+	AppendReflected(value interface{}) error
+}
@@ -0,0 +1,402 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultQueueSize is the capacity of the async queue when automatic
+	// tuning is disabled and it isn't set with WithQueueSize
+	DefaultQueueSize = 1000
+	// DefaultWorkers is the number of goroutines writing queued entries
+	// unless set with WithWorkers; automatic tuning starts with as many
+	DefaultWorkers = 1
+	// DefaultBatchSize is the most entries a worker writes in one go unless
+	// set with WithBatchSize
+	DefaultBatchSize = 64
+)
+
+// Option configures a logger created by New or NewLogger, or derived with
+// WithOptions
+type Option func(l *Logger) error
+
+// optionState tracks options being applied to a logger
+type optionState struct {
+	creating       bool // New is applying the options
+	queueSize      int  // Zero until set with WithQueueSize
+	workers        int  // Zero until set with WithWorkers
+	maxWorkers     int  // Zero until set with WithMaxWorkers
+	fixed          bool // Automatic tuning is disabled
+	ordered        bool // Entries of a component are written in order
+	batchSize      int
+	channelQueue   bool // Use a channel rather than the lock-free ring
+	synchronous    bool // Write entries on the calling goroutine
+	settingsForked bool // The logger no longer shares its parent's settings
+	levelForked    bool // The logger no longer shares its parent's level
+	outputsForked  bool // The logger no longer shares its parent's outputs
+}
+
+// errConstructionOnly is returned by options that can't reconfigure an
+// existing logger
+func errConstructionOnly(name string) error {
+	return fmt.Errorf("%s can only be set when creating a logger", name)
+}
+
+// errInvalidWorkers is returned for a worker count below one
+func errInvalidWorkers(n int) error {
+	return fmt.Errorf("invalid number of workers %d", n)
+}
+
+// WithLevel sets the global level
+func WithLevel(level Level) Option {
+	return func(l *Logger) error {
+		if level < LevelEmergency || level > LevelTrace {
+			return fmt.Errorf("invalid log level %d", level)
+		}
+		l.forkLevel()
+		l.SetLevel(level)
+		return nil
+	}
+}
+
+// WithOutputs replaces the outputs entries are written to
+func WithOutputs(outputs ...Output) Option {
+	return func(l *Logger) error {
+		for _, output := range outputs {
+			if output == nil {
+				return errors.New("nil output")
+			}
+		}
+		l.forkOutputs()
+		l.out.list = append(make([]Output, 0, len(outputs)), outputs...)
+		return nil
+	}
+}
+
+// AddOutputs adds outputs to the ones entries are written to
+func AddOutputs(outputs ...Output) Option {
+	return func(l *Logger) error {
+		for _, output := range outputs {
+			if output == nil {
+				return errors.New("nil output")
+			}
+		}
+		l.forkOutputs()
+		l.out.list = append(append([]Output(nil), l.out.list...), outputs...)
+		return nil
+	}
+}
+
+// WithQueueSize sets the capacity of the async queue (default
+// DefaultQueueSize)
+func WithQueueSize(size int) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("queue size")
+		}
+		if size < 1 {
+			return fmt.Errorf("invalid queue size %d", size)
+		}
+		l.options.queueSize = size
+		return nil
+	}
+}
+
+// WithWorkers sets the number of goroutines writing queued entries (default
+// DefaultWorkers), so one slow output doesn't hold up all logging. With more
+// than one worker, outputs are written concurrently and must be safe for
+// concurrent use, and entries may be written out of order unless
+// WithComponentOrdering is used.
+func WithWorkers(n int) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("workers")
+		}
+		if n < 1 {
+			return errInvalidWorkers(n)
+		}
+		l.options.workers = n
+		return nil
+	}
+}
+
+// WithComponentOrdering gives each worker its own queue and sends all entries
+// of a component to the same one, so they are written in the order they were
+// logged. The queue capacity is split between the workers.
+func WithComponentOrdering(enabled bool) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("component ordering")
+		}
+		l.options.ordered = enabled
+		return nil
+	}
+}
+
+// WithBatchSize sets the most entries a worker takes from the queue and
+// writes in one go (default DefaultBatchSize)
+func WithBatchSize(n int) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("batch size")
+		}
+		if n < 1 {
+			return fmt.Errorf("invalid batch size %d", n)
+		}
+		l.options.batchSize = n
+		return nil
+	}
+}
+
+// WithChannelQueue queues entries in a buffered channel instead of the
+// default lock-free ring buffer. The ring cuts contention when many
+// goroutines log at once and rounds the queue size up to a power of two; the
+// channel is kept as a fallback.
+func WithChannelQueue(enabled bool) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("queue implementation")
+		}
+		l.options.channelQueue = enabled
+		return nil
+	}
+}
+
+// WithOverflowPolicy sets what happens when the queue is full (see
+// SetOverflowPolicy)
+func WithOverflowPolicy(policy OverflowPolicy, timeout time.Duration) Option {
+	return func(l *Logger) error {
+		if policy < OverflowDropNewest || policy > OverflowBlock {
+			return fmt.Errorf("invalid overflow policy %d", policy)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("invalid block timeout %s", timeout)
+		}
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("overflow policy")
+		}
+		l.SetOverflowPolicy(policy, timeout)
+		return nil
+	}
+}
+
+// WithErrorHandler sets the function called when the logging pipeline fails
+// (see SetErrorHandler)
+func WithErrorHandler(fn ErrorHandler) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("error handler")
+		}
+		l.SetErrorHandler(fn)
+		return nil
+	}
+}
+
+// WithExitFunc sets the function Exit and Fatal end the process with (see
+// SetExitFunc)
+func WithExitFunc(fn ExitFunc) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("exit function")
+		}
+		l.SetExitFunc(fn)
+		return nil
+	}
+}
+
+// WithDropHandler sets a function called with every entry dropped because
+// the queue was full (see OnDrop)
+func WithDropHandler(fn DropFunc) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("drop handler")
+		}
+		l.OnDrop(fn)
+		return nil
+	}
+}
+
+// WithCaller enables or disables capturing the caller's file and line (see
+// SetCallerEnabled)
+func WithCaller(enabled bool) Option {
+	return func(l *Logger) error {
+		l.forkSettings()
+		l.SetCallerEnabled(enabled)
+		return nil
+	}
+}
+
+// AddCallerSkip skips n additional stack frames when capturing the caller
+// (see WithCallerSkip)
+func AddCallerSkip(n int) Option {
+	return func(l *Logger) error {
+		l.callerSkip += n
+		if l.callerSkip < 0 {
+			l.callerSkip = 0
+		}
+		return nil
+	}
+}
+
+// WithClock sets the clock timestamps are taken from (see SetClock)
+func WithClock(c Clock) Option {
+	return func(l *Logger) error {
+		if c == nil {
+			return errors.New("nil clock")
+		}
+		l.forkSettings()
+		l.SetClock(c)
+		return nil
+	}
+}
+
+// WithInstanceID sets the instance ID recorded in every entry, instead of
+// one generated from the process ID and start time
+func WithInstanceID(id string) Option {
+	return func(l *Logger) error {
+		if id == "" {
+			return errors.New("empty instance ID")
+		}
+		l.instanceID = id
+		return nil
+	}
+}
+
+// WithDefaultFields adds fields included in every entry
+func WithDefaultFields(fields map[string]interface{}) Option {
+	return func(l *Logger) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.defaultFields = nestFieldList(l.defaultFields, l.groups, fields)
+		return nil
+	}
+}
+
+// WithDevelopment enables or disables development mode (see SetDevelopment)
+func WithDevelopment(enabled bool) Option {
+	return func(l *Logger) error {
+		l.forkSettings()
+		l.SetDevelopment(enabled)
+		return nil
+	}
+}
+
+// WithEntryIDs enables or disables unique entry IDs (see SetEntryIDs)
+func WithEntryIDs(enabled bool) Option {
+	return func(l *Logger) error {
+		l.forkSettings()
+		l.SetEntryIDs(enabled)
+		return nil
+	}
+}
+
+// WithRuntimeStatsFields enables or disables runtime fields on entries at
+// Critical and above (see SetRuntimeStatsFields)
+func WithRuntimeStatsFields(enabled bool) Option {
+	return func(l *Logger) error {
+		l.forkSettings()
+		l.SetRuntimeStatsFields(enabled)
+		return nil
+	}
+}
+
+// WithFormatMode sets where messages are formatted and entries encoded (see
+// SetFormatMode)
+func WithFormatMode(mode FormatMode) Option {
+	return func(l *Logger) error {
+		l.forkSettings()
+		l.SetFormatMode(mode)
+		return nil
+	}
+}
+
+// WithErrorEncoder sets how errors in field values are logged (see
+// SetErrorEncoder)
+func WithErrorEncoder(enc ErrorEncoder) Option {
+	return func(l *Logger) error {
+		l.forkSettings()
+		l.SetErrorEncoder(enc)
+		return nil
+	}
+}
+
+// WithOptions returns a logger derived from l, like WithFields, with the
+// options applied on top of l's configuration. The derived logger shares l's
+// queue, hooks, filters and other machinery, so it is cheap enough to create
+// per subsystem:
+//
+//	audit, err := l.WithOptions(
+//		logger.WithLevel(logger.LevelDebug),
+//		logger.AddOutputs(auditFile),
+//		logger.AddCallerSkip(1),
+//	)
+//
+// Output options route the derived logger's entries (and those of loggers
+// derived from it) to its own outputs instead of l's, and WithLevel gives it
+// its own global level. Options for settings shared across a logger tree,
+// such as WithCaller or WithClock, give the derived logger its own copy of
+// those settings, so they never affect l.
+// Options that can only be set when creating a logger, such as WithQueueSize,
+// return an error.
+func (l *Logger) WithOptions(opts ...Option) (*Logger, error) {
+	derived := l.WithFields(nil)
+	derived.options = &optionState{}
+	for _, opt := range opts {
+		if err := opt(derived); err != nil {
+			return nil, err
+		}
+	}
+	// Outputs of its own are flushed and closed with l
+	if derived.options.outputsForked {
+		derived.outputSets.add(derived.out)
+	}
+	derived.options = nil
+	return derived, nil
+}
+
+// forkLevel gives a derived logger its own global level before an option
+// changes it
+func (l *Logger) forkLevel() {
+	if l.options == nil || l.options.creating || l.options.levelForked {
+		return
+	}
+	l.level = &levelVar{level: int32(l.GetLevel())}
+	l.options.levelForked = true
+}
+
+// forkOutputs gives a derived logger its own outputs before an option
+// changes them, so its entries (and those of loggers derived from it) are
+// no longer written to the root's
+func (l *Logger) forkOutputs() {
+	if l.options == nil || l.options.creating || l.options.outputsForked {
+		return
+	}
+	l.out = l.out.fork()
+	l.options.outputsForked = true
+}
+
+// forkSettings gives a derived logger its own copy of the shared settings
+// before an option changes them
+func (l *Logger) forkSettings() {
+	if l.options == nil || l.options.creating || l.options.settingsForked {
+		return
+	}
+	settings := &loggerSettings{
+		noCaller:     atomic.LoadInt32(&l.settings.noCaller),
+		entryIDs:     atomic.LoadInt32(&l.settings.entryIDs),
+		development:  atomic.LoadInt32(&l.settings.development),
+		formatMode:   atomic.LoadInt32(&l.settings.formatMode),
+		runtimeStats: atomic.LoadInt32(&l.settings.runtimeStats),
+	}
+	if enc := l.settings.errorEncoder.Load(); enc != nil {
+		settings.errorEncoder.Store(enc)
+	}
+	if c := l.settings.clock.Load(); c != nil {
+		settings.clock.Store(c)
+	}
+	l.settings = settings
+	l.options.settingsForked = true
+}
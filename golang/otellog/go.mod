@@ -0,0 +1,16 @@
+module github.com/hemant-mann/logger/golang/otellog
+
+go 1.22.0
+
+require (
+	github.com/hemant-mann/logger/golang v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/hemant-mann/logger/golang => ../
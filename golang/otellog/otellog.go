@@ -0,0 +1,108 @@
+// Package otellog correlates log entries with OpenTelemetry traces. Entries
+// logged through a logger bound to a context carrying an active span get its
+// trace and span IDs as fields, and entries can be recorded as span events.
+//
+//	otellog.Register(l)
+//	otellog.RecordSpanEvents(l, logger.LevelError)
+//
+//	logger.Ctx(ctx).Error("Payment declined") // trace_id=... span_id=...
+package otellog
+
+import (
+	"context"
+	"fmt"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDField is the field trace IDs are logged under
+	TraceIDField = "trace_id"
+	// SpanIDField is the field span IDs are logged under
+	SpanIDField = "span_id"
+	// TraceSampledField is the field the span's sampled flag is logged under
+	TraceSampledField = "trace_sampled"
+)
+
+// Extract returns the trace ID, span ID and sampled flag of the span carried
+// by ctx, or nil if there is no valid span. It is a logger.ContextExtractor.
+func Extract(ctx context.Context) map[string]interface{} {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return map[string]interface{}{
+		TraceIDField:      sc.TraceID().String(),
+		SpanIDField:       sc.SpanID().String(),
+		TraceSampledField: sc.IsSampled(),
+	}
+}
+
+// Register enriches entries logged via l.WithContext or logger.Ctx with the
+// IDs of the active span (see Extract)
+func Register(l *logger.Logger) {
+	l.AddContextExtractor(Extract)
+}
+
+// RecordSpanEvents records entries at minLevel or more severe as "log"
+// events on the active span of their context, so they show up in traces.
+// Entries are recorded after redaction; entries without a recording span are
+// ignored.
+func RecordSpanEvents(l *logger.Logger, minLevel logger.Level) {
+	var levels []logger.Level
+	for _, level := range logger.AllLevels {
+		if level <= minLevel {
+			levels = append(levels, level)
+		}
+	}
+	l.RegisterHook(levels, func(entry *logger.LogEntry) error {
+		if entry.Context == nil {
+			return nil
+		}
+		span := trace.SpanFromContext(entry.Context)
+		if !span.IsRecording() {
+			return nil
+		}
+		span.AddEvent("log", trace.WithTimestamp(entry.Timestamp), trace.WithAttributes(eventAttributes(entry)...))
+		return nil
+	})
+}
+
+// eventAttributes converts an entry into span event attributes
+func eventAttributes(entry *logger.LogEntry) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", entry.Level),
+		attribute.String("log.message", entry.Message),
+	}
+	if entry.Component != "" {
+		attrs = append(attrs, attribute.String("log.component", entry.Component))
+	}
+
+	for _, f := range entry.FieldList() {
+		if f.Key != TraceIDField && f.Key != SpanIDField && f.Key != TraceSampledField {
+			attrs = append(attrs, fieldAttribute("log.field."+f.Key, f.Value))
+		}
+	}
+	return attrs
+}
+
+// fieldAttribute converts a field value into an attribute, keeping basic
+// types and formatting everything else
+func fieldAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
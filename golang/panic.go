@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// PanicError is returned by CapturePanics for a recovered panic
+type PanicError struct {
+	Value interface{} // Value passed to panic
+	Stack string      // Stack trace of the panicking goroutine
+}
+
+// Error describes the panic value
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RecoverAndLog recovers a panic and logs it at Critical with its stack
+// trace, along with the component and fields of l (the default logger if
+// nil). It must be deferred directly:
+//
+//	defer logger.RecoverAndLog(l)
+func RecoverAndLog(l *Logger) {
+	if r := recover(); r != nil {
+		logPanic(l, r, string(debug.Stack()))
+	}
+}
+
+// RecoverLogAndRepanic is like RecoverAndLog but panics again with the same
+// value once the panic has been logged and the logger flushed, for panics
+// that must still crash the process
+//
+//	defer logger.RecoverLogAndRepanic(l)
+func RecoverLogAndRepanic(l *Logger) {
+	if r := recover(); r != nil {
+		logPanic(l, r, string(debug.Stack()))
+		if l == nil {
+			l = GetLogger()
+		}
+		l.Flush()
+		panic(r)
+	}
+}
+
+// CapturePanics runs fn, logging a panic it raises like RecoverAndLog and
+// returning it as a *PanicError. It returns nil if fn returns normally.
+//
+//	go func() {
+//		logger.CapturePanics(l, worker.Run)
+//	}()
+func CapturePanics(l *Logger, fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+			logPanic(l, r, stack)
+			err = &PanicError{Value: r, Stack: stack}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// logPanic logs a recovered panic value at Critical
+func logPanic(l *Logger, value interface{}, stack string) {
+	if l == nil {
+		l = GetLogger()
+	}
+	fields := map[string]interface{}{
+		"panic": fmt.Sprint(value),
+		"stack": stack,
+	}
+	if err, ok := value.(error); ok {
+		fields[ErrorFieldKey] = err
+	}
+	l.write(LevelCritical, 1, fmt.Sprintf("Panic recovered: %v", value), nil, fields)
+}
+
+// SetDevelopment switches development mode on or off for this logger and
+// every logger derived from it. In development mode DPanic panics after
+// logging, so impossible states are loud during development and tests.
+func (l *Logger) SetDevelopment(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&l.settings.development, v)
+}
+
+// Development reports whether the logger is in development mode
+func (l *Logger) Development() bool {
+	return atomic.LoadInt32(&l.settings.development) != 0
+}
+
+// DPanic logs a message at critical level and, in development mode (see
+// SetDevelopment), then panics with it. Use it for assertions on states
+// that should be impossible: loud locally, merely logged in production.
+func (l *Logger) DPanic(msg string, fields ...map[string]interface{}) {
+	l.log(LevelCritical, 1, msg, fields...)
+	l.dpanic(msg)
+}
+
+// DPanicf logs a formatted message at critical level and, in development
+// mode, then panics with it (see DPanic)
+func (l *Logger) DPanicf(format string, args ...interface{}) {
+	msg, fields := formatArgs(format, args)
+	l.log(LevelCritical, 1, msg, fields)
+	l.dpanic(msg)
+}
+
+// dpanic panics with msg in development mode, once the entry is written
+func (l *Logger) dpanic(msg string) {
+	if l.Development() {
+		l.Flush()
+		panic(msg)
+	}
+}
+
+// DPanic logs a message to the default logger at critical level and, in
+// development mode, then panics with it (see Logger.DPanic)
+func DPanic(msg string, fields ...map[string]interface{}) {
+	l := GetLogger()
+	l.log(LevelCritical, 1, msg, fields...)
+	l.dpanic(msg)
+}
+
+// DPanicf logs a formatted message to the default logger at critical level
+// and, in development mode, then panics with it (see Logger.DPanic)
+func DPanicf(format string, args ...interface{}) {
+	msg, fields := formatArgs(format, args)
+	l := GetLogger()
+	l.log(LevelCritical, 1, msg, fields)
+	l.dpanic(msg)
+}
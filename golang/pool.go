@@ -0,0 +1,45 @@
+package logger
+
+import "sync"
+
+// maxPooledFields caps the size of field maps kept for reuse, so an
+// occasional entry with many fields doesn't pin a large map in the pool
+const maxPooledFields = 64
+
+// entryPool recycles entries and their field maps. The logger owns every
+// entry it creates: filters, hooks and outputs may read and modify an entry
+// while it is handed to them, but must not keep a reference to it, or to its
+// Fields map, after they return. Anything that needs the data later must copy
+// it.
+var entryPool = sync.Pool{
+	New: func() interface{} {
+		return &LogEntry{}
+	},
+}
+
+// newEntry returns an empty entry, reusing a released one if possible
+func newEntry() *LogEntry {
+	entry := entryPool.Get().(*LogEntry)
+	entry.pooled = true
+	return entry
+}
+
+// releaseEntry returns an entry created by newEntry to the pool once nothing
+// uses it any more. Entries created elsewhere, such as ones substituted by a
+// filter, are left to the garbage collector since their fields may be shared.
+func releaseEntry(entry *LogEntry) {
+	if entry == nil || !entry.pooled {
+		return
+	}
+	fields := entry.Fields
+	if len(fields) > maxPooledFields {
+		fields = nil
+	}
+	clear(fields)
+	keys := entry.keys
+	if cap(keys) > maxPooledFields {
+		keys = nil
+	}
+	*entry = LogEntry{Fields: fields, keys: keys[:0]}
+	entryPool.Put(entry)
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProgressInterval is the least time between the entries of a
+// ProgressTracker, unless set with SetInterval
+const DefaultProgressInterval = 10 * time.Second
+
+// ProgressTracker logs the progress of a long-running loop at most once per
+// interval, see Logger.Progress
+type ProgressTracker struct {
+	l     *Logger
+	name  string
+	total int64
+	start time.Time
+
+	count    int64 // Atomic
+	interval int64 // Atomic, nanoseconds
+	next     int64 // Atomic, Unix nanoseconds from which the next entry is due
+	done     int32 // Atomic, non-zero once Done logged
+
+	mu        sync.Mutex // Serializes entries
+	lastCount int64      // Count at the last entry
+	lastAt    time.Time  // Time of the last entry
+}
+
+// Progress returns a tracker logging the progress of a long-running loop
+// at Info, with name as the message, instead of hand-coded modulo checks:
+//
+//	p := l.Progress("migrating rows", total)
+//	for rows.Next() {
+//	    // ...
+//	    p.Inc(1)
+//	}
+//	p.Done()
+//
+// Inc logs an entry at most once per interval (DefaultProgressInterval
+// unless set with SetInterval) carrying "count", "total", "percent", "rate"
+// (per second since the last entry), "eta" and ElapsedField. A total of 0
+// or less means unknown, leaving out "total", "percent" and "eta".
+func (l *Logger) Progress(name string, total int64) *ProgressTracker {
+	now := l.now()
+	return &ProgressTracker{
+		l:        l,
+		name:     name,
+		total:    total,
+		start:    now,
+		interval: int64(DefaultProgressInterval),
+		next:     now.Add(DefaultProgressInterval).UnixNano(),
+		lastAt:   now,
+	}
+}
+
+// Progress returns a tracker logging progress to the default logger (see
+// Logger.Progress)
+func Progress(name string, total int64) *ProgressTracker {
+	return GetLogger().Progress(name, total)
+}
+
+// SetInterval sets the least time between entries
+func (p *ProgressTracker) SetInterval(d time.Duration) {
+	atomic.StoreInt64(&p.interval, int64(d))
+	p.mu.Lock()
+	atomic.StoreInt64(&p.next, p.lastAt.Add(d).UnixNano())
+	p.mu.Unlock()
+}
+
+// Inc adds n to the count, logging an entry if one is due. It is safe for
+// concurrent use.
+func (p *ProgressTracker) Inc(n int64) {
+	atomic.AddInt64(&p.count, n)
+	now := p.l.now()
+	next := atomic.LoadInt64(&p.next)
+	if now.UnixNano() < next || atomic.LoadInt32(&p.done) != 0 {
+		return
+	}
+	// Only the caller moving the deadline logs
+	interval := atomic.LoadInt64(&p.interval)
+	if !atomic.CompareAndSwapInt64(&p.next, next, now.UnixNano()+interval) {
+		return
+	}
+	p.log(now, "")
+}
+
+// Count returns the count so far
+func (p *ProgressTracker) Count() int64 {
+	return atomic.LoadInt64(&p.count)
+}
+
+// Done logs "Finished <name>" with the final count and the average rate.
+// Only the first call logs.
+func (p *ProgressTracker) Done() {
+	if !atomic.CompareAndSwapInt32(&p.done, 0, 1) {
+		return
+	}
+	p.mu.Lock()
+	p.lastCount, p.lastAt = 0, p.start
+	p.mu.Unlock()
+	p.log(p.l.now(), "Finished ")
+}
+
+// log logs the progress, with the rate measured since the last entry
+func (p *ProgressTracker) log(now time.Time, prefix string) {
+	p.mu.Lock()
+	// Read under the lock, so that counts logged never go backwards
+	count := atomic.LoadInt64(&p.count)
+	rate := 0.0
+	if secs := now.Sub(p.lastAt).Seconds(); secs > 0 {
+		rate = float64(count-p.lastCount) / secs
+	}
+	p.lastCount, p.lastAt = count, now
+	p.mu.Unlock()
+
+	fields := map[string]interface{}{
+		"count":      count,
+		"rate":       math.Round(rate*100) / 100,
+		ElapsedField: elapsedSince(p.start, now),
+	}
+	if p.total > 0 {
+		fields["total"] = p.total
+		fields["percent"] = math.Round(float64(count)*10000/float64(p.total)) / 100
+		if remaining := p.total - count; remaining > 0 && rate > 0 && prefix == "" {
+			fields["eta"] = time.Duration(float64(remaining) / rate * float64(time.Second)).Round(time.Second).String()
+		}
+	}
+	p.l.log(LevelInfo, 2, prefix+p.name, fields)
+}
@@ -0,0 +1,100 @@
+// Package promlog exposes the internal metrics of a logger (see
+// logger.Stats) to Prometheus, so the health of logging shows up on the same
+// dashboards as the application's own metrics.
+//
+//	prometheus.MustRegister(promlog.NewCollector(l))
+package promlog
+
+import (
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector reporting the metrics of a logger
+type Collector struct {
+	logger *logger.Logger
+
+	entries       *prometheus.Desc
+	dropped       *prometheus.Desc
+	queueDepth    *prometheus.Desc
+	queueMax      *prometheus.Desc
+	queueCapacity *prometheus.Desc
+	outputWrites  *prometheus.Desc
+	outputErrors  *prometheus.Desc
+	outputLatency *prometheus.Desc
+}
+
+// NewCollector returns a collector for the metrics of l, which are shared
+// by every logger derived from it
+func NewCollector(l *logger.Logger) *Collector {
+	return &Collector{
+		logger: l,
+		entries: prometheus.NewDesc("vlog_entries_total",
+			"Entries queued for the outputs.", []string{"level"}, nil),
+		dropped: prometheus.NewDesc("vlog_dropped_total",
+			"Entries dropped because the queue was full.", nil, nil),
+		queueDepth: prometheus.NewDesc("vlog_queue_depth",
+			"Entries currently queued.", nil, nil),
+		queueMax: prometheus.NewDesc("vlog_queue_max_depth",
+			"Largest number of entries seen waiting in a queue.", nil, nil),
+		queueCapacity: prometheus.NewDesc("vlog_queue_capacity",
+			"Maximum number of queued entries.", nil, nil),
+		outputWrites: prometheus.NewDesc("vlog_output_writes_total",
+			"Entries written to an output, including failed writes.", []string{"output"}, nil),
+		outputErrors: prometheus.NewDesc("vlog_output_errors_total",
+			"Writes to an output that failed.", []string{"output"}, nil),
+		outputLatency: prometheus.NewDesc("vlog_output_write_seconds_max",
+			"Longest time a write to an output took.", []string{"output"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entries
+	ch <- c.dropped
+	ch <- c.queueDepth
+	ch <- c.queueMax
+	ch <- c.queueCapacity
+	ch <- c.outputWrites
+	ch <- c.outputErrors
+	ch <- c.outputLatency
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.logger.Stats()
+
+	for _, level := range logger.AllLevels {
+		name := level.String()
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.CounterValue, float64(stats.Logged[name]), name)
+	}
+	ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stats.Dropped))
+	ch <- prometheus.MustNewConstMetric(c.queueDepth, prometheus.GaugeValue, float64(stats.QueueLength))
+	ch <- prometheus.MustNewConstMetric(c.queueMax, prometheus.GaugeValue, float64(stats.QueueMax))
+	ch <- prometheus.MustNewConstMetric(c.queueCapacity, prometheus.GaugeValue, float64(stats.QueueCapacity))
+
+	// Outputs sharing a name, such as two consoles, are reported together
+	type outputTotals struct {
+		writes, errors uint64
+		max            float64
+	}
+	var names []string
+	totals := make(map[string]*outputTotals, len(stats.Outputs))
+	for _, o := range stats.Outputs {
+		t, ok := totals[o.Name]
+		if !ok {
+			t = &outputTotals{}
+			totals[o.Name] = t
+			names = append(names, o.Name)
+		}
+		t.writes += o.Writes
+		t.errors += o.Errors
+		t.max = max(t.max, o.MaxLatency.Seconds())
+	}
+	for _, name := range names {
+		t := totals[name]
+		ch <- prometheus.MustNewConstMetric(c.outputWrites, prometheus.CounterValue, float64(t.writes), name)
+		ch <- prometheus.MustNewConstMetric(c.outputErrors, prometheus.CounterValue, float64(t.errors), name)
+		ch <- prometheus.MustNewConstMetric(c.outputLatency, prometheus.GaugeValue, t.max, name)
+	}
+}
@@ -0,0 +1,259 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to an entry logged while the async
+// queue is full
+type OverflowPolicy int32
+
+const (
+	// OverflowDropNewest drops the entry being logged, so logging never
+	// waits. This is the default.
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest queued entry to make room, keeping
+	// the most recent entries
+	OverflowDropOldest
+	// OverflowBlock makes the caller wait for room in the queue, up to the
+	// block timeout if one is set, and drops the entry if it expires
+	OverflowBlock
+)
+
+// String returns the name of the policy
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int32(p))
+	}
+}
+
+// DropFunc is called with every entry dropped because the queue was full. It
+// runs on the logging goroutine and must not keep the entry after returning.
+type DropFunc func(entry *LogEntry)
+
+// DefaultDropSummaryInterval is how often dropped entries are reported
+// unless changed with SetDropSummaryInterval
+const DefaultDropSummaryInterval = 10 * time.Second
+
+// overflowHandling holds the overflow policy shared by a logger and everything
+// derived from it, since they share the queue
+type overflowHandling struct {
+	policy       int32 // Atomic OverflowPolicy
+	blockTimeout int64 // Atomic time.Duration, zero blocks indefinitely
+	onDrop       atomic.Value
+
+	drops      uint64  // Atomic, entries dropped since the last summary
+	worst      int32   // Atomic Level, most severe entry dropped since then
+	interval   int64   // Atomic time.Duration between summaries
+	lastReport int64   // Atomic Unix nanoseconds of the last summary
+	logger     *Logger // Root logger the summaries are written to
+}
+
+// noDrops is the worst level recorded while nothing was dropped
+const noDrops = int32(LevelTrace) + 1
+
+func newOverflowHandling(l *Logger) *overflowHandling {
+	return &overflowHandling{
+		worst:      noDrops,
+		interval:   int64(DefaultDropSummaryInterval),
+		lastReport: l.now().UnixNano(),
+		logger:     l,
+	}
+}
+
+// dropped records a dropped entry for the next summary
+func (o *overflowHandling) dropped(entry *LogEntry) {
+	atomic.AddUint64(&o.drops, 1)
+	level, ok := levelByName(entry.Level)
+	if !ok {
+		return
+	}
+	for {
+		worst := atomic.LoadInt32(&o.worst)
+		if int32(level) >= worst || atomic.CompareAndSwapInt32(&o.worst, worst, int32(level)) {
+			return
+		}
+	}
+}
+
+// report writes a Warning summarizing entries dropped since the last
+// summary. Unless forced, it waits until the interval has passed and the
+// queue has room again, so the summary itself isn't dropped.
+func (o *overflowHandling) report(force bool) {
+	if atomic.LoadUint64(&o.drops) == 0 {
+		return
+	}
+	now := o.logger.now().UnixNano()
+	last := atomic.LoadInt64(&o.lastReport)
+	if !force && (now-last < atomic.LoadInt64(&o.interval) || o.logger.queuesFull()) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&o.lastReport, last, now) {
+		return // Another goroutine is reporting
+	}
+
+	drops := atomic.SwapUint64(&o.drops, 0)
+	worst := Level(atomic.SwapInt32(&o.worst, noDrops))
+	if drops == 0 {
+		return
+	}
+	since := time.Duration(now - last).Round(time.Millisecond)
+	msg := fmt.Sprintf("Dropped %d entries in the last %s, highest severity %s", drops, since, worst)
+	o.logger.write(LevelWarning, 1, msg, []Field{F("dropped", drops), F("highest_level", worst.String())})
+}
+
+// SetDropSummaryInterval changes how often entries dropped because the queue
+// was full are reported. Drops are summarized in a single Warning logged
+// once the queue has room again, rather than reported one by one.
+func (l *Logger) SetDropSummaryInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultDropSummaryInterval
+	}
+	atomic.StoreInt64(&l.overflow.interval, int64(interval))
+}
+
+// dropFuncBox lets atomic.Value hold a nil DropFunc
+type dropFuncBox struct {
+	fn DropFunc
+}
+
+// SetOverflowPolicy chooses what happens when an entry is logged while the
+// queue is full. With OverflowBlock, a positive timeout limits how long the
+// caller waits before the entry is dropped; zero waits as long as it takes.
+// The timeout is ignored by the other policies. Under OverflowBlock, outputs
+// must not log through the logger they write for, since the worker would wait
+// on itself.
+func (l *Logger) SetOverflowPolicy(policy OverflowPolicy, timeout time.Duration) {
+	atomic.StoreInt64(&l.overflow.blockTimeout, int64(timeout))
+	atomic.StoreInt32(&l.overflow.policy, int32(policy))
+}
+
+// OverflowPolicy returns the current overflow policy
+func (l *Logger) OverflowPolicy() OverflowPolicy {
+	return OverflowPolicy(atomic.LoadInt32(&l.overflow.policy))
+}
+
+// OnDrop sets a function called with every entry dropped because the queue
+// was full, e.g. to count drops per level. nil removes it.
+func (l *Logger) OnDrop(fn DropFunc) {
+	l.overflow.onDrop.Store(dropFuncBox{fn})
+}
+
+// flushBarrier is shared by the sentinels of one flush, one per worker
+type flushBarrier struct {
+	pending int32         // Atomic, sentinels not yet reached
+	done    chan struct{} // Closed once every sentinel has been reached
+}
+
+func newFlushBarrier(n int) *flushBarrier {
+	return &flushBarrier{pending: int32(n), done: make(chan struct{})}
+}
+
+// arrive marks one sentinel as reached
+func (b *flushBarrier) arrive() {
+	if atomic.AddInt32(&b.pending, -1) == 0 {
+		close(b.done)
+	}
+}
+
+// wait marks a sentinel as reached by a worker and holds the worker until
+// every other worker has reached one too
+func (b *flushBarrier) wait() {
+	b.arrive()
+	<-b.done
+}
+
+// queueFor picks the queue for an entry. With per-component ordering,
+// entries of the same component always go to the same worker.
+func (l *Logger) queueFor(entry *LogEntry) entryQueue {
+	if len(l.queues) == 1 {
+		return l.queues[0]
+	}
+	// FNV-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(entry.Component); i++ {
+		h ^= uint32(entry.Component[i])
+		h *= 16777619
+	}
+	return l.queues[h%uint32(len(l.queues))]
+}
+
+// queuesFull reports whether any queue is full
+func (l *Logger) queuesFull() bool {
+	for _, queue := range l.queues {
+		if queue.len() >= queue.cap() {
+			return true
+		}
+	}
+	return false
+}
+
+// queueFull handles an entry that didn't fit in its queue according to the
+// overflow policy
+func (l *Logger) queueFull(queue entryQueue, entry *LogEntry) {
+	switch OverflowPolicy(atomic.LoadInt32(&l.overflow.policy)) {
+	case OverflowBlock:
+		if l.guard.onWorker() {
+			// An entry logged by an output or the error handler; the
+			// worker would wait for room only it can make
+			break
+		}
+		timeout := time.Duration(atomic.LoadInt64(&l.overflow.blockTimeout))
+		if timeout <= 0 {
+			queue.push(entry, nil, nil)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if queue.push(entry, ctx.Done(), nil) {
+			return
+		}
+
+	case OverflowDropOldest:
+		// Other producers may fill the freed slot first, so retry a few times
+		for i := 0; i < 3; i++ {
+			if oldest, ok := queue.tryPop(); ok {
+				l.evict(oldest)
+			}
+			if queue.tryPush(entry) {
+				return
+			}
+		}
+	}
+
+	l.drop(entry)
+}
+
+// evict drops an entry taken from the head of a queue. Flush sentinels are
+// never dropped: everything queued before one has been dequeued already, so
+// it counts as reached.
+func (l *Logger) evict(entry *LogEntry) {
+	if entry.flush != nil {
+		entry.flush.arrive()
+		return
+	}
+	l.drop(entry)
+}
+
+// drop discards an entry that couldn't be queued. Drops are reported in a
+// periodic summary, since a line per dropped entry would only add to the
+// flood.
+func (l *Logger) drop(entry *LogEntry) {
+	atomic.AddUint64(&l.stats.dropped, 1)
+	l.overflow.dropped(entry)
+	if box, ok := l.overflow.onDrop.Load().(dropFuncBox); ok && box.fn != nil {
+		entry.resolve()
+		box.fn(entry)
+	}
+	releaseEntry(entry)
+}
@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRateLimitSummaryInterval is how often entries suppressed by rate
+// limits are reported unless changed with SetRateLimitSummaryInterval
+const DefaultRateLimitSummaryInterval = 10 * time.Second
+
+// tokenBucket allows up to rate entries per second with bursts of up to burst
+type tokenBucket struct {
+	rate       float64
+	burst      float64
+	tokens     float64
+	last       time.Time
+	suppressed uint64 // Entries rejected since the last summary
+}
+
+func newTokenBucket(perSecond float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// take consumes a token if one is available
+func (b *tokenBucket) take(now time.Time) bool {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		b.suppressed++
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter enforces token-bucket limits per level and per component and
+// periodically reports how many entries it suppressed
+type rateLimiter struct {
+	active     int32 // Atomic, non-zero when any limit is set
+	mu         sync.Mutex
+	levels     map[Level]*tokenBucket
+	components map[string]*tokenBucket
+	interval   time.Duration
+	lastReport time.Time
+	started    bool
+	logger     *Logger // Root logger the summaries are written to
+}
+
+func newRateLimiter(l *Logger) *rateLimiter {
+	return &rateLimiter{
+		levels:     make(map[Level]*tokenBucket),
+		components: make(map[string]*tokenBucket),
+		interval:   DefaultRateLimitSummaryInterval,
+		logger:     l,
+	}
+}
+
+// allow reports whether an entry at the given level and component is within
+// the configured limits. Component limits also apply to descendants, which
+// share the bucket of the closest configured ancestor.
+func (r *rateLimiter) allow(level Level, component string) bool {
+	if atomic.LoadInt32(&r.active) == 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.logger.now()
+	if bucket, exists := r.levels[level]; exists && !bucket.take(now) {
+		return false
+	}
+	for name := component; name != ""; {
+		if bucket, exists := r.components[name]; exists {
+			if !bucket.take(now) {
+				return false
+			}
+			break
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return true
+}
+
+// setLevel configures or, for a non-positive rate, removes a level limit
+func (r *rateLimiter) setLevel(level Level, perSecond float64, burst int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if perSecond <= 0 {
+		delete(r.levels, level)
+	} else {
+		r.levels[level] = newTokenBucket(perSecond, burst)
+	}
+	r.updateLocked()
+}
+
+// setComponent configures or, for a non-positive rate, removes a component limit
+func (r *rateLimiter) setComponent(component string, perSecond float64, burst int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if perSecond <= 0 {
+		delete(r.components, component)
+	} else {
+		r.components[component] = newTokenBucket(perSecond, burst)
+	}
+	r.updateLocked()
+}
+
+// updateLocked recomputes whether any limit is active and starts the summary
+// goroutine the first time one is; r.mu must be held
+func (r *rateLimiter) updateLocked() {
+	active := len(r.levels) > 0 || len(r.components) > 0
+	if active {
+		atomic.StoreInt32(&r.active, 1)
+	} else {
+		atomic.StoreInt32(&r.active, 0)
+	}
+	if active && !r.started {
+		r.started = true
+		r.lastReport = r.logger.now()
+		go r.run()
+	}
+}
+
+// run periodically reports suppressed entries until the logger is closed
+func (r *rateLimiter) run() {
+	r.mu.Lock()
+	interval := r.interval
+	r.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.report()
+			r.mu.Lock()
+			if r.interval != interval {
+				interval = r.interval
+				ticker.Reset(interval)
+			}
+			r.mu.Unlock()
+		case <-r.logger.done:
+			return
+		}
+	}
+}
+
+// report writes a Warning summarizing entries suppressed since the last
+// report, if there were any
+func (r *rateLimiter) report() {
+	r.mu.Lock()
+	suppressed := make(map[string]interface{})
+	var total uint64
+	for level, bucket := range r.levels {
+		if bucket.suppressed > 0 {
+			suppressed["level:"+level.String()] = bucket.suppressed
+			total += bucket.suppressed
+			bucket.suppressed = 0
+		}
+	}
+	for component, bucket := range r.components {
+		if bucket.suppressed > 0 {
+			suppressed["component:"+component] = bucket.suppressed
+			total += bucket.suppressed
+			bucket.suppressed = 0
+		}
+	}
+	now := r.logger.now()
+	since := now.Sub(r.lastReport).Round(time.Millisecond)
+	r.lastReport = now
+	r.mu.Unlock()
+
+	if total == 0 {
+		return
+	}
+	msg := fmt.Sprintf("Rate limits suppressed %d entries in the last %s", total, since)
+	r.logger.write(LevelWarning, 1, msg, []Field{F("suppressed", suppressed)})
+}
+
+// SetLevelRateLimit limits entries at the given level to perSecond entries per
+// second with bursts of up to burst entries. Suppressed entries are counted
+// and reported in a periodic Warning. A rate of zero or less removes the limit.
+func (l *Logger) SetLevelRateLimit(level Level, perSecond float64, burst int) {
+	l.limiter.setLevel(level, perSecond, burst)
+}
+
+// SetComponentRateLimit limits entries of a component and its descendants to
+// perSecond entries per second with bursts of up to burst entries. A rate of
+// zero or less removes the limit.
+func (l *Logger) SetComponentRateLimit(component string, perSecond float64, burst int) {
+	l.limiter.setComponent(component, perSecond, burst)
+}
+
+// SetRateLimitSummaryInterval changes how often suppressed entries are reported
+func (l *Logger) SetRateLimitSummaryInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRateLimitSummaryInterval
+	}
+	l.limiter.mu.Lock()
+	defer l.limiter.mu.Unlock()
+	l.limiter.interval = interval
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// FlightRecorderField marks the entries written by the flight recorder (see
+// SetFlightRecorder)
+const FlightRecorderField = "flight_recorder"
+
+// entryRing holds the last entries recorded for a component
+type entryRing struct {
+	entries []*LogEntry
+	next    int // Index the next entry goes to
+	n       int // Entries held
+}
+
+// add stores an entry, evicting the oldest if the ring is full
+func (r *entryRing) add(entry *LogEntry) {
+	if r.n == len(r.entries) {
+		releaseEntry(r.entries[r.next])
+	} else {
+		r.n++
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+}
+
+// held returns the entries held, oldest first
+func (r *entryRing) held() []*LogEntry {
+	held := make([]*LogEntry, 0, r.n)
+	start := (r.next - r.n + len(r.entries)) % len(r.entries)
+	for i := 0; i < r.n; i++ {
+		held = append(held, r.entries[(start+i)%len(r.entries)])
+	}
+	return held
+}
+
+// take removes the entries held, oldest first
+func (r *entryRing) take() []*LogEntry {
+	taken := r.held()
+	clear(r.entries)
+	r.n = 0
+	return taken
+}
+
+// flightRecorder keeps the last entries of each component that weren't
+// logged because of their level, to write them when an error follows
+type flightRecorder struct {
+	active int32 // Atomic, non-zero when recording
+	level  int32 // Atomic, least severe level recorded
+	mu     sync.Mutex
+	size   int
+	rings  map[string]*entryRing
+}
+
+func newFlightRecorder() *flightRecorder {
+	return &flightRecorder{rings: make(map[string]*entryRing)}
+}
+
+// configure records up to size entries per component at level and above,
+// or stops recording for a size of zero or less. Entries recorded so far
+// are discarded.
+func (r *flightRecorder) configure(level Level, size int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ring := range r.rings {
+		for _, entry := range ring.take() {
+			releaseEntry(entry)
+		}
+	}
+	r.rings = make(map[string]*entryRing)
+	r.size = size
+	atomic.StoreInt32(&r.level, int32(level))
+	if size > 0 {
+		atomic.StoreInt32(&r.active, 1)
+	} else {
+		atomic.StoreInt32(&r.active, 0)
+	}
+}
+
+// captures reports whether entries at level are recorded when not logged
+func (r *flightRecorder) captures(level Level) bool {
+	return atomic.LoadInt32(&r.active) != 0 && int32(level) <= atomic.LoadInt32(&r.level)
+}
+
+// add records an entry
+func (r *flightRecorder) add(entry *LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size <= 0 {
+		releaseEntry(entry)
+		return
+	}
+	ring, exists := r.rings[entry.Component]
+	if !exists {
+		ring = &entryRing{entries: make([]*LogEntry, r.size)}
+		r.rings[entry.Component] = ring
+	}
+	ring.add(entry)
+}
+
+// take removes the entries recorded for component and the components below
+// it, oldest first; the root component takes them all
+func (r *flightRecorder) take(component string) []*LogEntry {
+	if atomic.LoadInt32(&r.active) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	var taken []*LogEntry
+	for name, ring := range r.rings {
+		if component == "" || name == component || strings.HasPrefix(name, component+".") {
+			taken = append(taken, ring.take()...)
+		}
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(taken, func(i, j int) bool {
+		return taken[i].Timestamp.Before(taken[j].Timestamp)
+	})
+	return taken
+}
+
+// each calls fn with every entry recorded, oldest first, without removing
+// them. fn must not keep the entries.
+func (r *flightRecorder) each(fn func(entry *LogEntry)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []*LogEntry
+	for _, ring := range r.rings {
+		entries = append(entries, ring.held()...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	for _, entry := range entries {
+		fn(entry)
+	}
+}
+
+// record keeps a message logged skip frames up in the flight recorder, if it
+// was left out because of its level rather than sampling or limits
+func (l *Logger) record(level Level, skip int, msg string, list []Field, fields []map[string]interface{}) {
+	if !l.recorder.captures(level) || l.isLoggable(level, l.component) {
+		return
+	}
+	entry := l.buildEntry(level, skip+1, msg, nil, list, fields)
+	filtered, ok := l.filters.apply(entry)
+	if !ok {
+		releaseEntry(entry)
+		return
+	}
+	if filtered != entry {
+		filtered.pooled = false
+		entry = filtered
+	}
+	l.redactor.Redact(entry)
+	l.recorder.add(entry)
+}
+
+// replayRecorded queues the entries recorded for component ahead of an
+// error, marked with FlightRecorderField
+func (l *Logger) replayRecorded(component string) {
+	for _, entry := range l.recorder.take(component) {
+		entry.setField(FlightRecorderField, true)
+		l.enqueue(entry)
+	}
+}
+
+// SetFlightRecorder keeps the last size entries of each component that
+// weren't logged because of their level, down to level, and writes them
+// when an entry at Error or above is logged by the component or one below
+// it, just ahead of that entry. Outputs thus get the Debug context of a
+// failure while the logger runs at Info:
+//
+//	l.SetFlightRecorder(logger.LevelDebug, 200)
+//
+// Replayed entries keep their timestamp and carry FlightRecorderField. They
+// have been through filters and redaction, but not hooks. Recording builds
+// every entry down to level, so it costs about as much as logging it
+// without the writing, and field values must not be modified once logged.
+// A size of zero or less stops recording and discards what was recorded.
+func (l *Logger) SetFlightRecorder(level Level, size int) {
+	l.recorder.configure(level, size)
+}
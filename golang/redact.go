@@ -0,0 +1,393 @@
+package logger
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RedactedValue replaces values masked by a Redactor
+const RedactedValue = "[REDACTED]"
+
+// Patterns for common kinds of sensitive data, for use with RedactPatterns
+var (
+	// PatternEmail matches email addresses
+	PatternEmail = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// PatternCreditCard matches 13 to 19 digit card numbers, optionally grouped by spaces or dashes
+	PatternCreditCard = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	// PatternBearerToken matches bearer tokens as found in Authorization headers
+	PatternBearerToken = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// Redactor masks sensitive data in entries before any output sees them.
+// Values of fields with configured names are replaced entirely, while
+// configured patterns and secrets are masked wherever they appear in the
+// message or in string field values, including nested maps and slices.
+type Redactor struct {
+	mu    sync.Mutex   // Serializes rule changes
+	rules atomic.Value // *redactionRules, unset until any rule is configured
+}
+
+// redactionRules is an immutable set of rules. Changes replace the rules as
+// a whole, so values encoded later (lazy values, marshalers) can hold on to
+// the rules in effect without locking.
+type redactionRules struct {
+	fields   map[string]struct{}
+	patterns []*regexp.Regexp
+	secrets  map[string]struct{}
+	replacer *strings.Replacer
+}
+
+// NewRedactor creates a redactor without any rules
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+// load returns the current rules, nil if none are configured
+func (r *Redactor) load() *redactionRules {
+	rules, _ := r.rules.Load().(*redactionRules)
+	return rules
+}
+
+// update applies fn to a copy of the current rules and installs the result
+func (r *Redactor) update(fn func(rules *redactionRules)) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := &redactionRules{
+		fields:  make(map[string]struct{}),
+		secrets: make(map[string]struct{}),
+	}
+	if current := r.load(); current != nil {
+		for name := range current.fields {
+			next.fields[name] = struct{}{}
+		}
+		for secret := range current.secrets {
+			next.secrets[secret] = struct{}{}
+		}
+		next.patterns = append(next.patterns, current.patterns...)
+		next.replacer = current.replacer
+	}
+	fn(next)
+	r.rules.Store(next)
+	return r
+}
+
+// RedactFields masks the values of fields with the given names (case-insensitive)
+func (r *Redactor) RedactFields(names ...string) *Redactor {
+	return r.update(func(rules *redactionRules) {
+		for _, name := range names {
+			rules.fields[strings.ToLower(name)] = struct{}{}
+		}
+	})
+}
+
+// RedactPatterns masks every match of the given patterns
+func (r *Redactor) RedactPatterns(patterns ...*regexp.Regexp) *Redactor {
+	return r.update(func(rules *redactionRules) {
+		rules.patterns = append(rules.patterns, patterns...)
+	})
+}
+
+// RedactSecrets masks every occurrence of the given literal values, such as
+// API keys or passwords loaded at startup. Empty values are ignored.
+func (r *Redactor) RedactSecrets(values ...string) *Redactor {
+	return r.update(func(rules *redactionRules) {
+		for _, value := range values {
+			if value != "" {
+				rules.secrets[value] = struct{}{}
+			}
+		}
+		if len(rules.secrets) == 0 {
+			return
+		}
+
+		// Longest secrets first so a secret containing another is masked whole
+		sorted := make([]string, 0, len(rules.secrets))
+		for secret := range rules.secrets {
+			sorted = append(sorted, secret)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+		pairs := make([]string, 0, 2*len(sorted))
+		for _, secret := range sorted {
+			pairs = append(pairs, secret, RedactedValue)
+		}
+		rules.replacer = strings.NewReplacer(pairs...)
+	})
+}
+
+// RedactSecretsFromEnv masks the current values of the named environment
+// variables, e.g. RedactSecretsFromEnv("DB_PASSWORD", "STRIPE_API_KEY").
+// Unset or empty variables are ignored.
+func (r *Redactor) RedactSecretsFromEnv(names ...string) *Redactor {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, os.Getenv(name))
+	}
+	return r.RedactSecrets(values...)
+}
+
+// Redact masks sensitive data in the entry in place. Nested values are copied
+// before being modified, so values shared with other entries are left intact.
+func (r *Redactor) Redact(entry *LogEntry) {
+	rules := r.load()
+	if rules == nil {
+		return
+	}
+
+	entry.resolve()
+	entry.Message = rules.redactString(entry.Message)
+	for k, v := range entry.Fields {
+		entry.Fields[k] = rules.redactField(k, v)
+	}
+}
+
+// masked reports whether the value of a field is masked entirely
+func (r *redactionRules) masked(key string) bool {
+	_, ok := r.fields[strings.ToLower(key)]
+	return ok
+}
+
+// redactField masks a single field value, taking its key into account
+func (r *redactionRules) redactField(key string, value interface{}) interface{} {
+	if r.masked(key) {
+		return RedactedValue
+	}
+	return r.redactValue(value)
+}
+
+// redactValue masks pattern matches inside a value of any supported type
+func (r *redactionRules) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case []string:
+		redacted := make([]string, len(v))
+		for i, s := range v {
+			redacted[i] = r.redactString(s)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+		return redacted
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			redacted[k] = r.redactField(k, item)
+		}
+		return redacted
+	case error:
+		return r.redactString(v.Error())
+	case ObjectMarshaler:
+		return redactedObject{r: r, value: v}
+	case ArrayMarshaler:
+		return redactedArray{r: r, value: v}
+	case *LazyValue:
+		// Keep the value lazy; it is redacted once it is evaluated
+		return Lazy(func() interface{} {
+			return r.redactValue(v.Value())
+		})
+	default:
+		return value
+	}
+}
+
+// redactString masks every secret and pattern match in s
+func (r *redactionRules) redactString(s string) string {
+	if r.replacer != nil {
+		s = r.replacer.Replace(s)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, RedactedValue)
+	}
+	return s
+}
+
+// Redactor returns the redactor applied to every entry of this logger and of
+// every logger derived from it. Configure it to enable redaction:
+//
+//	l.Redactor().RedactFields("password").RedactPatterns(logger.PatternEmail)
+func (l *Logger) Redactor() *Redactor {
+	return l.redactor
+}
+
+// redactedObject wraps an ObjectMarshaler so the redactor applies to the
+// fields it adds once it is encoded
+type redactedObject struct {
+	r     *redactionRules
+	value ObjectMarshaler
+}
+
+// MarshalLogObject encodes the wrapped object through a redacting encoder
+func (o redactedObject) MarshalLogObject(enc ObjectEncoder) error {
+	return o.value.MarshalLogObject(&redactingEncoder{r: o.r, obj: enc})
+}
+
+// redactedArray wraps an ArrayMarshaler so the redactor applies to the
+// elements it appends once it is encoded
+type redactedArray struct {
+	r     *redactionRules
+	value ArrayMarshaler
+}
+
+// MarshalLogArray encodes the wrapped array through a redacting encoder
+func (a redactedArray) MarshalLogArray(enc ArrayEncoder) error {
+	return a.value.MarshalLogArray(&redactingEncoder{r: a.r, arr: enc})
+}
+
+// redactingEncoder masks values on their way to an ObjectEncoder or
+// ArrayEncoder
+type redactingEncoder struct {
+	r   *redactionRules
+	obj ObjectEncoder
+	arr ArrayEncoder
+}
+
+// masked reports whether the value of a key is masked entirely
+func (e *redactingEncoder) masked(key string) bool {
+	return e.r.masked(key)
+}
+
+func (e *redactingEncoder) AddString(key, value string) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddString(key, e.r.redactString(value))
+}
+
+func (e *redactingEncoder) AddInt(key string, value int) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddInt(key, value)
+}
+
+func (e *redactingEncoder) AddInt64(key string, value int64) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddInt64(key, value)
+}
+
+func (e *redactingEncoder) AddUint64(key string, value uint64) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddUint64(key, value)
+}
+
+func (e *redactingEncoder) AddFloat64(key string, value float64) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddFloat64(key, value)
+}
+
+func (e *redactingEncoder) AddBool(key string, value bool) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddBool(key, value)
+}
+
+func (e *redactingEncoder) AddTime(key string, value time.Time) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddTime(key, value)
+}
+
+func (e *redactingEncoder) AddDuration(key string, value time.Duration) {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return
+	}
+	e.obj.AddDuration(key, value)
+}
+
+func (e *redactingEncoder) AddObject(key string, value ObjectMarshaler) error {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return nil
+	}
+	return e.obj.AddObject(key, ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		return value.MarshalLogObject(&redactingEncoder{r: e.r, obj: enc})
+	}))
+}
+
+func (e *redactingEncoder) AddArray(key string, value ArrayMarshaler) error {
+	if e.masked(key) {
+		e.obj.AddString(key, RedactedValue)
+		return nil
+	}
+	return e.obj.AddArray(key, ArrayMarshalerFunc(func(enc ArrayEncoder) error {
+		return value.MarshalLogArray(&redactingEncoder{r: e.r, arr: enc})
+	}))
+}
+
+func (e *redactingEncoder) AddReflected(key string, value interface{}) error {
+	return e.obj.AddReflected(key, e.r.redactField(key, value))
+}
+
+func (e *redactingEncoder) AppendString(value string) {
+	e.arr.AppendString(e.r.redactString(value))
+}
+
+func (e *redactingEncoder) AppendInt(value int) {
+	e.arr.AppendInt(value)
+}
+
+func (e *redactingEncoder) AppendInt64(value int64) {
+	e.arr.AppendInt64(value)
+}
+
+func (e *redactingEncoder) AppendUint64(value uint64) {
+	e.arr.AppendUint64(value)
+}
+
+func (e *redactingEncoder) AppendFloat64(value float64) {
+	e.arr.AppendFloat64(value)
+}
+
+func (e *redactingEncoder) AppendBool(value bool) {
+	e.arr.AppendBool(value)
+}
+
+func (e *redactingEncoder) AppendTime(value time.Time) {
+	e.arr.AppendTime(value)
+}
+
+func (e *redactingEncoder) AppendDuration(value time.Duration) {
+	e.arr.AppendDuration(value)
+}
+
+func (e *redactingEncoder) AppendObject(value ObjectMarshaler) error {
+	return e.arr.AppendObject(ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		return value.MarshalLogObject(&redactingEncoder{r: e.r, obj: enc})
+	}))
+}
+
+func (e *redactingEncoder) AppendArray(value ArrayMarshaler) error {
+	return e.arr.AppendArray(ArrayMarshalerFunc(func(enc ArrayEncoder) error {
+		return value.MarshalLogArray(&redactingEncoder{r: e.r, arr: enc})
+	}))
+}
+
+func (e *redactingEncoder) AppendReflected(value interface{}) error {
+	return e.arr.AppendReflected(e.r.redactValue(value))
+}
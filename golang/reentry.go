@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ErrReentrant is returned when a logger is flushed or closed from one of
+// its own workers, i.e. by an output or the error handler, which would wait
+// for itself
+var ErrReentrant = errors.New("logger: called from its own output")
+
+// reentryGuard recognizes the goroutines running code called by the logger,
+// so entries they log can't recurse or deadlock: the workers, which call
+// the outputs and the error handler, and goroutines running hooks. Go has
+// no goroutine-local storage, so goroutines are told apart by ID; looking
+// one up costs about a microsecond, so it is only done when a hook is about
+// to run or a caller is about to block.
+type reentryGuard struct {
+	workers sync.Map // Goroutine ID of every running worker
+	hooks   sync.Map // Goroutine ID of every goroutine running hooks
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [running]:" header of its stack trace
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = b[len("goroutine "):]
+	for i, c := range b {
+		if c == ' ' {
+			b = b[:i]
+			break
+		}
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// enterWorker registers the calling goroutine as a worker until leaveWorker
+func (g *reentryGuard) enterWorker() uint64 {
+	id := goroutineID()
+	g.workers.Store(id, struct{}{})
+	return id
+}
+
+// leaveWorker unregisters a worker
+func (g *reentryGuard) leaveWorker(id uint64) {
+	g.workers.Delete(id)
+}
+
+// onWorker reports whether the calling goroutine is a worker, which must not
+// wait for the queue to drain
+func (g *reentryGuard) onWorker() bool {
+	_, ok := g.workers.Load(goroutineID())
+	return ok
+}
+
+// enterHooks marks the calling goroutine as running hooks. It returns false
+// if it already is, i.e. the entry was logged by a hook, whose hooks must be
+// skipped to end the recursion.
+func (g *reentryGuard) enterHooks() (uint64, bool) {
+	id := goroutineID()
+	_, running := g.hooks.LoadOrStore(id, struct{}{})
+	return id, !running
+}
+
+// leaveHooks ends enterHooks
+func (g *reentryGuard) leaveHooks(id uint64) {
+	g.hooks.Delete(id)
+}
@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ApplyConfig reconfigures a running logger from a configuration. Sections
+// missing from the configuration are left unchanged; the ones present replace
+// the current settings as a whole, so removing a component or sampling key
+// reverts it. New outputs are created before anything is changed, so an
+// invalid configuration leaves the logger untouched. Queued entries are kept
+// and written to the new outputs; the replaced outputs are flushed and closed
+// once no write to them is in flight.
+//
+// Default fields are only replaced on this logger, not on loggers previously
+// derived from it via With or WithFields.
+func (l *Logger) ApplyConfig(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	var outputs []Output
+	if c.Outputs != nil {
+		var err error
+		if outputs, err = c.buildOutputs(); err != nil {
+			return err
+		}
+	}
+
+	var components map[string]Level
+	if c.Components != nil {
+		components = make(map[string]Level, len(c.Components))
+		for name, value := range c.Components {
+			components[name], _ = ParseLevel(value)
+		}
+	}
+
+	// Level watchers are notified once the lock is released, since they may log
+	var changes []levelChange
+	var before map[string]Level
+	if components != nil {
+		before = make(map[string]Level)
+		for name := range l.levels.snapshot() {
+			before[name] = l.ComponentLevel(name)
+		}
+		for name := range components {
+			before[name] = l.ComponentLevel(name)
+		}
+	}
+
+	l.mu.Lock()
+	if c.Level != "" {
+		level, _ := ParseLevel(c.Level)
+		changes = append(changes, levelChange{"", l.swapLevel(level), level})
+	}
+	if components != nil {
+		l.levels.replace(components)
+		for name, old := range before {
+			changes = append(changes, levelChange{name, old, l.ComponentLevel(name)})
+		}
+	}
+	if c.Sampling != nil {
+		policies := make(map[string]SamplingPolicy, len(c.Sampling))
+		for key, rate := range c.Sampling {
+			if rate >= 1 {
+				policies[key] = ratePolicy(rate)
+			}
+		}
+		l.sampler.ReplaceOverrides(policies)
+	}
+	if c.ComponentSampling != nil || c.LevelSampling != nil {
+		l.sampler.replaceScoped(c.scopedSampling())
+	}
+	if c.Fields != nil {
+		l.defaultFields = mapFieldList(c.Fields)
+	}
+	if c.Caller != nil {
+		l.SetCallerEnabled(*c.Caller)
+	}
+	if c.Development != nil {
+		l.SetDevelopment(*c.Development)
+	}
+	l.mu.Unlock()
+
+	// Outputs are swapped once l.mu is released, since an output may log,
+	// taking l.mu, while its worker holds the lock of the outputs. Taking
+	// that lock waits for the batches in flight, after which the replaced
+	// outputs are written no more and can be closed.
+	if outputs != nil {
+		list, routes := c.routeOutputs(outputs)
+		l.out.mu.Lock()
+		replaced := l.out.allLocked()
+		l.out.list = list
+		l.out.setRoutesLocked(routes)
+		current := l.out.allLocked()
+		l.out.mu.Unlock()
+		for _, output := range replaced {
+			if slices.Contains(current, output) {
+				continue
+			}
+			l.flushOutput(output)
+			closeOutput(output)
+			l.stats.forget(output)
+		}
+	}
+
+	l.levels.notify(changes...)
+	return nil
+}
+
+// ReloadConfig reads a configuration file and applies it (see ApplyConfig)
+func (l *Logger) ReloadConfig(path string) error {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return l.ApplyConfig(config)
+}
+
+// WatchConfig reloads the configuration file whenever its modification time
+// or size changes, checked every interval, and whenever the process receives
+// SIGHUP. An interval of zero or less disables polling so only SIGHUP
+// triggers a reload. Reload failures are logged and the previous
+// configuration stays in effect. Call the returned function to stop watching.
+func (l *Logger) WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	modTime, size := info.ModTime(), info.Size()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+	}
+
+	reload := func(reason string) {
+		if err := l.ReloadConfig(path); err != nil {
+			l.Errorf("Failed to reload logging configuration from %s: %v", path, err)
+			return
+		}
+		l.Noticef("Reloaded logging configuration from %s (%s)", path, reason)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer signal.Stop(hup)
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+
+		for {
+			select {
+			case <-hup:
+				reload("SIGHUP")
+			case <-tick:
+				info, err := os.Stat(path)
+				if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+					continue
+				}
+				modTime, size = info.ModTime(), info.Size()
+				reload("file changed")
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }, nil
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// hasOutputStats reports whether Stats reports on output
+func hasOutputStats(l *Logger, output Output) bool {
+	for _, s := range l.Stats().Outputs {
+		if s.Output == output {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApplyConfigForgetsReplacedOutputs(t *testing.T) {
+	old := &recordingOutput{}
+	l := NewLogger(WithOutputs(old))
+	defer l.Close()
+	l.Info("before")
+	l.Flush()
+	if !hasOutputStats(l, old) {
+		t.Fatal("no stats for the output written to")
+	}
+
+	config := &Config{Outputs: []OutputConfig{{Type: "file", Format: "json", Path: filepath.Join(t.TempDir(), "app.log")}}}
+	if err := l.ApplyConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after")
+	l.Flush()
+
+	stats := l.Stats()
+	if hasOutputStats(l, old) {
+		t.Errorf("stats still report the replaced output: %+v", stats.Outputs)
+	}
+	if len(stats.Outputs) != 1 || stats.Outputs[0].Writes != 1 {
+		t.Errorf("stats of the new output: %+v", stats.Outputs)
+	}
+}
+
+func TestRouteComponentForgetsUnroutedOutputs(t *testing.T) {
+	shared, routed := &recordingOutput{}, &recordingOutput{}
+	l := NewLogger(WithOutputs(shared))
+	defer l.Close()
+	l.RouteComponent("db", routed)
+	l.With("db").Info("query")
+	l.Flush()
+	if !hasOutputStats(l, routed) {
+		t.Fatal("no stats for the routed output")
+	}
+
+	l.RouteComponent("db")
+	if hasOutputStats(l, routed) {
+		t.Error("stats still report the output no longer routed")
+	}
+}
@@ -0,0 +1,26 @@
+package logger
+
+// Replay writes a recorded entry, such as one read back from a log file by
+// the vlogread package, to the logger's outputs as it was recorded: with its
+// own timestamp, level, component, caller and fields, including its sequence
+// number. Levels, sampling, filters, hooks and deduplication applied when
+// the entry was first logged and are skipped; only redaction applies again,
+// so a new sink never receives what the logger masks.
+//
+// The entry goes through the queue like any other and is subject to the
+// overflow policy; use OverflowBlock or a synchronous logger so a backfill
+// loses nothing. The logger owns the entry once Replay is called.
+func (l *Logger) Replay(entry *LogEntry) {
+	// An output of a synchronous logger replaying while it writes would
+	// deadlock, as when logging
+	if l.synchronous && l.guard.onWorker() {
+		l.drop(entry)
+		return
+	}
+
+	if l.out.own {
+		entry.out = l.out
+	}
+	l.redactor.Redact(entry)
+	l.submit(entry, false)
+}
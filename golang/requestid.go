@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RequestIDField is the field request IDs are logged under
+	RequestIDField = "request_id"
+	// RequestIDHeader is the header request IDs are read from and written to
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader is the W3C trace context header, whose trace ID is
+	// used as request ID when no RequestIDHeader is present
+	TraceparentHeader = "traceparent"
+)
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewUUID returns a random (version 4) UUID such as
+// "0b5c1e6a-3c8f-4f2a-9d1e-7a2b4c6d8e0f"
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// NewULID returns a ULID for the current time, a 26 character identifier
+// such as "01HZX3K6Q8V2M4N7P9R1S3T5W7" that sorts by creation time. IDs
+// created within the same millisecond still sort in creation order.
+func NewULID() string {
+	return ulids.next(time.Now())
+}
+
+// ulids generates the IDs returned by NewULID
+var ulids ulidGenerator
+
+// ulidGenerator creates ULIDs that increase strictly monotonically, even
+// for IDs created within the same millisecond
+type ulidGenerator struct {
+	mu   sync.Mutex
+	last [16]byte
+}
+
+// next returns a ULID for time t, greater than any previously returned
+func (g *ulidGenerator) next(t time.Time) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(t.UnixMilli())<<16)
+	if bytes.Compare(b[:6], g.last[:6]) <= 0 {
+		// Same (or an earlier) millisecond: increment the previous ID
+		b = g.last
+		for i := 15; i >= 0; i-- {
+			b[i]++
+			if b[i] != 0 {
+				break
+			}
+		}
+	} else {
+		rand.Read(b[6:])
+	}
+	g.last = b
+	return encodeULID(b)
+}
+
+// encodeULID encodes 128 bits as 26 Crockford base32 characters
+func encodeULID(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
+
+// NewRequestID generates a request ID. It is a variable so applications can
+// switch to another scheme, e.g. logger.NewRequestID = logger.NewUUID.
+var NewRequestID = NewULID
+
+// RequestIDFromHeader returns the request ID carried by incoming headers:
+// the X-Request-ID header, or else the trace ID of a W3C traceparent header.
+// It returns "" if there is neither.
+func RequestIDFromHeader(h http.Header) string {
+	if id := strings.TrimSpace(h.Get(RequestIDHeader)); id != "" {
+		return id
+	}
+	// traceparent is "version-traceid-parentid-flags"
+	parts := strings.Split(strings.TrimSpace(h.Get(TraceparentHeader)), "-")
+	if len(parts) == 4 && len(parts[1]) == 32 && parts[1] != strings.Repeat("0", 32) {
+		if _, err := hex.DecodeString(parts[1]); err == nil {
+			return parts[1]
+		}
+	}
+	return ""
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID and a logger
+// derived from the context's logger (see FromContext) that logs the ID in
+// the request_id field
+func WithRequestID(ctx context.Context, id string) context.Context {
+	l := FromContext(ctx).WithFields(map[string]interface{}{RequestIDField: id})
+	return NewContext(context.WithValue(ctx, requestIDKey, id), l)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// RequestIDMiddleware returns HTTP middleware that takes the request ID from
+// the incoming headers (see RequestIDFromHeader) or generates a new one,
+// echoes it in the X-Request-ID response header, and stores it in the
+// request context together with a child of l logging it (see WithRequestID)
+func RequestIDMiddleware(l *Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := RequestIDFromHeader(r.Header)
+			if id == "" {
+				id = NewRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			ctx := r.Context()
+			if _, ok := ctx.Value(loggerKey).(*Logger); !ok {
+				ctx = NewContext(ctx, l)
+			}
+			next.ServeHTTP(w, r.WithContext(WithRequestID(ctx, id)))
+		})
+	}
+}
+
+// requestIDTransport sets the X-Request-ID header of outgoing requests
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+// RequestIDTransport wraps an http.RoundTripper (nil for the default
+// transport) so outgoing requests carry the request ID of their context in
+// the X-Request-ID header, propagating it to downstream services
+func RequestIDTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &requestIDTransport{base: base}
+}
+
+// RoundTrip sets the request ID header, unless already set, and sends the request
+func (t *requestIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	id := RequestIDFromContext(r.Context())
+	if id != "" && r.Header.Get(RequestIDHeader) == "" {
+		// RoundTrippers must not modify the caller's request
+		r = r.Clone(r.Context())
+		r.Header.Set(RequestIDHeader, id)
+	}
+	return t.base.RoundTrip(r)
+}
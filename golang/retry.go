@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Retry defaults used for zero fields of a RetryPolicy
+const (
+	DefaultRetryAttempts   = 3
+	DefaultRetryBackoff    = 50 * time.Millisecond
+	DefaultRetryMaxBackoff = 2 * time.Second
+)
+
+// RetryPolicy describes how RetryOutput retries a failed write
+type RetryPolicy struct {
+	// Attempts is the maximum number of writes per entry, including the
+	// first (default DefaultRetryAttempts)
+	Attempts int
+	// Backoff is the wait before the first retry, doubled for each retry
+	// after it (default DefaultRetryBackoff)
+	Backoff time.Duration
+	// MaxBackoff caps the wait between retries (default
+	// DefaultRetryMaxBackoff)
+	MaxBackoff time.Duration
+	// Budget caps the total time spent retrying one entry; no retry is
+	// started that would wait past it. Zero only limits the attempts.
+	Budget time.Duration
+	// Retryable reports whether a write error is worth retrying; nil
+	// retries every error
+	Retryable func(err error) bool
+}
+
+// RetryOutput wraps an output to retry failed writes with exponential
+// backoff and jitter, for outputs whose errors are often transient, such as
+// network sinks. Retries happen on the worker writing the entry, so the
+// queue backs up while an output is retried; keep the budget short, or
+// combine with a circuit breaker for outputs that may be down for long.
+type RetryOutput struct {
+	output Output
+	policy RetryPolicy
+}
+
+// NewRetryOutput returns output retrying failed writes according to policy
+func NewRetryOutput(output Output, policy RetryPolicy) *RetryOutput {
+	if policy.Attempts < 1 {
+		policy.Attempts = DefaultRetryAttempts
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = DefaultRetryBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	return &RetryOutput{output: output, policy: policy}
+}
+
+// Write writes the entry, retrying until it succeeds, the error isn't
+// retryable, or the attempts or budget run out. The error returned is the
+// last write's.
+func (o *RetryOutput) Write(entry *LogEntry) error {
+	err := o.output.Write(entry)
+	if err == nil {
+		return nil
+	}
+
+	start := time.Now()
+	backoff := o.policy.Backoff
+	attempt := 1
+	for ; attempt < o.policy.Attempts; attempt++ {
+		if o.policy.Retryable != nil && !o.policy.Retryable(err) {
+			break
+		}
+		// Wait between half and all of the backoff, so outputs failing
+		// together don't retry in lockstep
+		wait := backoff/2 + rand.N(backoff/2+1)
+		if o.policy.Budget > 0 && time.Since(start)+wait > o.policy.Budget {
+			break
+		}
+		time.Sleep(wait)
+		if err = o.output.Write(entry); err == nil {
+			return nil
+		}
+		backoff = min(2*backoff, o.policy.MaxBackoff)
+	}
+	if attempt == 1 {
+		return err
+	}
+	return fmt.Errorf("write failed after %d attempts: %w", attempt, err)
+}
+
+// Close closes the wrapped output
+func (o *RetryOutput) Close() error {
+	return o.output.Close()
+}
+
+// EndBatch ends a batch of the wrapped output if it buffers writes
+func (o *RetryOutput) EndBatch() error {
+	if b, ok := o.output.(BufferedOutput); ok {
+		return b.EndBatch()
+	}
+	return nil
+}
+
+// Flush flushes the wrapped output if it buffers writes
+func (o *RetryOutput) Flush() error {
+	if b, ok := o.output.(BufferedOutput); ok {
+		return b.Flush()
+	}
+	return nil
+}
+
+// Sync syncs the wrapped output if it implements Syncer
+func (o *RetryOutput) Sync() error {
+	if s, ok := o.output.(Syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped output
+func (o *RetryOutput) Unwrap() Output {
+	return o.output
+}
+
+// String names the output after the one it wraps
+func (o *RetryOutput) String() string {
+	return outputName(o.output)
+}
@@ -0,0 +1,207 @@
+package logger
+
+import "sync/atomic"
+
+// entryQueue holds entries waiting for the workers
+type entryQueue interface {
+	// tryPush adds an entry unless the queue is full
+	tryPush(entry *LogEntry) bool
+	// push adds an entry, waiting for room until either cancel channel is
+	// closed (a nil channel never is). It reports whether the entry was added.
+	push(entry *LogEntry, cancel1, cancel2 <-chan struct{}) bool
+	// tryPop removes the oldest entry unless the queue is empty
+	tryPop() (*LogEntry, bool)
+	// pop removes the oldest entry, waiting for one until done is closed
+	pop(done <-chan struct{}) (*LogEntry, bool)
+	len() int
+	cap() int
+}
+
+// chanQueue is an entryQueue backed by a buffered channel
+type chanQueue chan *LogEntry
+
+func (q chanQueue) tryPush(entry *LogEntry) bool {
+	select {
+	case q <- entry:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q chanQueue) push(entry *LogEntry, cancel1, cancel2 <-chan struct{}) bool {
+	select {
+	case q <- entry:
+		return true
+	case <-cancel1:
+		return false
+	case <-cancel2:
+		return false
+	}
+}
+
+func (q chanQueue) tryPop() (*LogEntry, bool) {
+	select {
+	case entry := <-q:
+		return entry, true
+	default:
+		return nil, false
+	}
+}
+
+func (q chanQueue) pop(done <-chan struct{}) (*LogEntry, bool) {
+	select {
+	case entry := <-q:
+		return entry, true
+	case <-done:
+		return nil, false
+	}
+}
+
+func (q chanQueue) len() int { return len(q) }
+func (q chanQueue) cap() int { return cap(q) }
+
+// ringQueue is a bounded lock-free ring buffer (Dmitry Vyukov's algorithm).
+// Each slot carries a sequence number telling producers and consumers whose
+// turn it is, so pushing and popping only take a compare-and-swap on the
+// position counters. Any number of goroutines may push and pop, which lets
+// producers evict the oldest entry and several workers share one queue.
+type ringQueue struct {
+	_     [64]byte // Keep the hot counters on their own cache lines
+	tail  atomic.Uint64
+	_     [56]byte
+	head  atomic.Uint64
+	_     [56]byte
+	mask  uint64
+	slots []ringSlot
+	ready chan struct{} // Signalled after a push, wakes a waiting consumer
+	space chan struct{} // Signalled after a pop, wakes a waiting producer
+}
+
+// ringSlot holds one entry and the position it is valid for
+type ringSlot struct {
+	seq   atomic.Uint64
+	entry atomic.Pointer[LogEntry]
+}
+
+// newRingQueue creates a ring holding at least size entries; the capacity is
+// rounded up to a power of two
+func newRingQueue(size int) *ringQueue {
+	capacity := 1
+	for capacity < size {
+		capacity <<= 1
+	}
+	q := &ringQueue{
+		mask:  uint64(capacity - 1),
+		slots: make([]ringSlot, capacity),
+		ready: make(chan struct{}, 1),
+		space: make(chan struct{}, 1),
+	}
+	for i := range q.slots {
+		q.slots[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// wake wakes one goroutine waiting on c, if any
+func wake(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+func (q *ringQueue) tryPush(entry *LogEntry) bool {
+	pos := q.tail.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq - pos); {
+		case diff == 0:
+			// The slot is free for this position; claim it
+			if q.tail.CompareAndSwap(pos, pos+1) {
+				slot.entry.Store(entry)
+				slot.seq.Store(pos + 1)
+				wake(q.ready)
+				return true
+			}
+			pos = q.tail.Load()
+		case diff < 0:
+			// The slot still holds the entry from one lap ago
+			return false
+		default:
+			// Another producer claimed the position first
+			pos = q.tail.Load()
+		}
+	}
+}
+
+func (q *ringQueue) push(entry *LogEntry, cancel1, cancel2 <-chan struct{}) bool {
+	for !q.tryPush(entry) {
+		select {
+		case <-q.space:
+		case <-cancel1:
+			return false
+		case <-cancel2:
+			return false
+		}
+	}
+	// The pop that woke us may have freed room for other waiting producers
+	wake(q.space)
+	return true
+}
+
+func (q *ringQueue) tryPop() (*LogEntry, bool) {
+	pos := q.head.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq - (pos + 1)); {
+		case diff == 0:
+			// The slot holds the entry for this position; claim it
+			if q.head.CompareAndSwap(pos, pos+1) {
+				entry := slot.entry.Swap(nil)
+				slot.seq.Store(pos + q.mask + 1)
+				wake(q.space)
+				if q.len() > 0 {
+					// Let another consumer take the rest
+					wake(q.ready)
+				}
+				return entry, true
+			}
+			pos = q.head.Load()
+		case diff < 0:
+			// Nothing has been pushed at this position yet
+			return nil, false
+		default:
+			// Another consumer claimed the position first
+			pos = q.head.Load()
+		}
+	}
+}
+
+func (q *ringQueue) pop(done <-chan struct{}) (*LogEntry, bool) {
+	for {
+		if entry, ok := q.tryPop(); ok {
+			return entry, true
+		}
+		select {
+		case <-q.ready:
+		case <-done:
+			return nil, false
+		}
+	}
+}
+
+func (q *ringQueue) len() int {
+	n := int64(q.tail.Load() - q.head.Load())
+	if n < 0 {
+		return 0
+	}
+	if n > int64(len(q.slots)) {
+		return len(q.slots)
+	}
+	return int(n)
+}
+
+func (q *ringQueue) cap() int { return len(q.slots) }
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// RotationMode selects how a FileOutput moves a full file aside
+type RotationMode int32
+
+const (
+	// RotateRename renames the file and opens a new one in its place. It
+	// is the default except on Windows, where an open file can't be
+	// renamed while other processes, such as log shippers, hold it.
+	RotateRename RotationMode = iota
+	// RotateCopyTruncate copies the file aside and truncates it, keeping
+	// it open. It works wherever the file can be read, at the cost of
+	// copying it, and is the default on Windows.
+	RotateCopyTruncate
+)
+
+// String returns the name of the mode
+func (m RotationMode) String() string {
+	switch m {
+	case RotateRename:
+		return "rename"
+	case RotateCopyTruncate:
+		return "copytruncate"
+	default:
+		return fmt.Sprintf("RotationMode(%d)", int32(m))
+	}
+}
+
+// ParseRotationMode converts a mode name, as returned by String, into a
+// RotationMode
+func ParseRotationMode(name string) (RotationMode, error) {
+	switch strings.ToLower(name) {
+	case "rename":
+		return RotateRename, nil
+	case "copytruncate", "copy-truncate":
+		return RotateCopyTruncate, nil
+	default:
+		return 0, fmt.Errorf("unknown rotation mode %q", name)
+	}
+}
+
+// SetRotationMode sets how the file is moved aside when it rotates
+func (o *FileOutput) SetRotationMode(mode RotationMode) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rotation = mode
+}
+
+// rotateRename moves the file to rotatedPath and opens a new one; o.mu must
+// be held
+func (o *FileOutput) rotateRename(rotatedPath string) error {
+	if err := o.file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(o.path, rotatedPath); err != nil {
+		// Try to reopen the original file
+		var reopenErr error
+		o.file, reopenErr = os.OpenFile(o.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if reopenErr != nil {
+			return fmt.Errorf("failed to rotate log: %v and failed to reopen: %v", err, reopenErr)
+		}
+		return err
+	}
+
+	// Open a new log file
+	file, err := os.OpenFile(o.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	o.file = file
+	return nil
+}
+
+// rotateCopy copies the file to rotatedPath and truncates it; o.mu must be
+// held
+func (o *FileOutput) rotateCopy(rotatedPath string) error {
+	src, err := os.Open(o.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(rotatedPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(rotatedPath)
+		return fmt.Errorf("failed to rotate log: %v", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(rotatedPath)
+		return fmt.Errorf("failed to rotate log: %v", err)
+	}
+
+	// The file is opened for appending, so writes continue at the start
+	return o.file.Truncate(0)
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package logger
+
+// defaultRotationMode is the rotation mode of new file outputs
+const defaultRotationMode = RotateRename
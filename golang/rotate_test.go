@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestFileOutput opens a JSON file output in a temporary directory,
+// closed when the test ends
+func newTestFileOutput(t *testing.T, mode RotationMode) *FileOutput {
+	t.Helper()
+	o, err := NewFileOutput(filepath.Join(t.TempDir(), "app.log"), FormatJSON, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.SetRotationMode(mode)
+	t.Cleanup(func() { o.Close() })
+	return o
+}
+
+// writeTestEntry writes an entry whose message is msg
+func writeTestEntry(t *testing.T, o *FileOutput, msg string) {
+	if err := o.Write(&LogEntry{Timestamp: time.Now(), Level: "INFO", Message: msg}); err != nil {
+		t.Error(err)
+	}
+}
+
+// readTestEntries returns the messages of the entries in a file, failing on
+// any line that isn't a whole entry
+func readTestEntries(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var messages []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("%s: bad line %q: %v", filepath.Base(path), scanner.Text(), err)
+		}
+		messages = append(messages, entry.Message)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+// rotatedFiles returns the files a FileOutput rotated its file to
+func rotatedFiles(t *testing.T, o *FileOutput) []string {
+	t.Helper()
+	matches, err := filepath.Glob(o.path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestDefaultRotationMode(t *testing.T) {
+	want := RotateRename
+	if runtime.GOOS == "windows" {
+		want = RotateCopyTruncate
+	}
+	if defaultRotationMode != want {
+		t.Errorf("default rotation mode on %s is %v, want %v", runtime.GOOS, defaultRotationMode, want)
+	}
+}
+
+func TestParseRotationMode(t *testing.T) {
+	for _, mode := range []RotationMode{RotateRename, RotateCopyTruncate} {
+		parsed, err := ParseRotationMode(mode.String())
+		if err != nil || parsed != mode {
+			t.Errorf("ParseRotationMode(%q) = %v, %v", mode.String(), parsed, err)
+		}
+	}
+	if mode, err := ParseRotationMode("copy-truncate"); err != nil || mode != RotateCopyTruncate {
+		t.Errorf("ParseRotationMode(\"copy-truncate\") = %v, %v", mode, err)
+	}
+	if _, err := ParseRotationMode("move"); err == nil {
+		t.Error("ParseRotationMode(\"move\") succeeded")
+	}
+}
+
+func TestCopyTruncateRotation(t *testing.T) {
+	o := newTestFileOutput(t, RotateCopyTruncate)
+	rotated := make(chan string, 1)
+	o.SetRotateCallback(func(path string) { rotated <- path })
+
+	before, err := os.Stat(o.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestEntry(t, o, "before 1")
+	writeTestEntry(t, o, "before 2")
+	if err := o.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	writeTestEntry(t, o, "after")
+	if err := o.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	var path string
+	select {
+	case path = <-rotated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotate callback not called")
+	}
+	if got := readTestEntries(t, path); !slices.Equal(got, []string{"before 1", "before 2"}) {
+		t.Errorf("rotated file holds %q", got)
+	}
+	if got := readTestEntries(t, o.path); !slices.Equal(got, []string{"after"}) {
+		t.Errorf("current file holds %q", got)
+	}
+
+	// The file is truncated in place rather than replaced, which is what
+	// lets rotation work while other processes hold it open
+	after, err := os.Stat(o.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("file replaced by copy-truncate rotation")
+	}
+}
+
+func TestCopyTruncateRotationWithConcurrentWrites(t *testing.T) {
+	const (
+		writers = 8
+		entries = 500
+	)
+	o := newTestFileOutput(t, RotateCopyTruncate)
+
+	// The first writer rotates the file halfway through, while the others
+	// keep writing. Rotations are named by the second, so a single one keeps
+	// the count of rotated files certain.
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			<-start
+			for i := 0; i < entries; i++ {
+				if w == 0 && i == entries/2 {
+					if err := o.Rotate(); err != nil {
+						t.Error(err)
+					}
+				}
+				writeTestEntry(t, o, fmt.Sprintf("%d-%d", w, i))
+			}
+		}(w)
+	}
+	close(start)
+	wg.Wait()
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := rotatedFiles(t, o)
+	if len(files) != 1 {
+		t.Fatalf("rotated files: %q", files)
+	}
+	// Every entry written lands whole in exactly one of the files
+	seen := make(map[string]int)
+	for _, path := range append(files, o.path) {
+		for _, msg := range readTestEntries(t, path) {
+			seen[msg]++
+		}
+	}
+	for w := 0; w < writers; w++ {
+		for i := 0; i < entries; i++ {
+			if msg := fmt.Sprintf("%d-%d", w, i); seen[msg] != 1 {
+				t.Errorf("entry %s found %d times", msg, seen[msg])
+			}
+		}
+	}
+	if len(seen) != writers*entries {
+		t.Errorf("found %d entries, want %d", len(seen), writers*entries)
+	}
+}
+
+func TestCopyTruncateRotationOnSize(t *testing.T) {
+	o := newTestFileOutput(t, RotateCopyTruncate)
+	writeTestEntry(t, o, "first")
+	o.mu.Lock()
+	o.maxSize = o.currentSize + 1 // The next entry goes past it
+	o.mu.Unlock()
+	writeTestEntry(t, o, "second")
+	if err := o.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := rotatedFiles(t, o)
+	if len(files) != 1 {
+		t.Fatalf("rotated files: %q", files)
+	}
+	if got := readTestEntries(t, files[0]); !slices.Equal(got, []string{"first"}) {
+		t.Errorf("rotated file holds %q", got)
+	}
+	if got := readTestEntries(t, o.path); !slices.Equal(got, []string{"second"}) {
+		t.Errorf("current file holds %q", got)
+	}
+}
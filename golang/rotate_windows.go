@@ -0,0 +1,4 @@
+package logger
+
+// defaultRotationMode is the rotation mode of new file outputs
+const defaultRotationMode = RotateCopyTruncate
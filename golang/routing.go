@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RouteComponent writes the entries of a component, and of its descendants
+// without a route of their own, to the given outputs only, instead of the
+// logger's outputs. One logger can thus keep the classic separate files:
+//
+//	l.RouteComponent("access", accessLog)
+//	l.RouteComponent("db.slowquery", slowLog, appLog) // in both files
+//
+// Routes are shared by the loggers sharing outputs, like AddOutput. Routed
+// outputs are flushed and closed with the logger, and listed by
+// ListOutputs. Calling RouteComponent without outputs removes the route; as
+// with RemoveOutput, the outputs are not closed.
+func (l *Logger) RouteComponent(component string, outputs ...Output) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+
+	// Copy on write, like the outputs
+	routes := make(map[string][]Output, len(l.out.routes)+1)
+	for name, route := range l.out.routes {
+		routes[name] = route
+	}
+	previous := routes[component]
+	if len(outputs) == 0 {
+		delete(routes, component)
+	} else {
+		routes[component] = append(make([]Output, 0, len(outputs)), outputs...)
+	}
+	l.out.setRoutesLocked(routes)
+
+	// Outputs no longer written to at all drop out of Stats, as with
+	// SetOutputs
+	current := l.out.allLocked()
+	for _, o := range previous {
+		if !slices.Contains(current, o) {
+			l.stats.forget(o)
+		}
+	}
+}
+
+// ComponentRoutes returns the outputs each routed component is written to
+// (see RouteComponent)
+func (l *Logger) ComponentRoutes() map[string][]Output {
+	l.out.mu.RLock()
+	defer l.out.mu.RUnlock()
+	routes := make(map[string][]Output, len(l.out.routes))
+	for name, route := range l.out.routes {
+		routes[name] = append([]Output(nil), route...)
+	}
+	return routes
+}
+
+// fork returns a copy of the set for a logger given outputs of its own
+func (s *outputSet) fork() *outputSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	forked := &outputSet{list: append([]Output(nil), s.list...), own: true}
+	forked.setRoutesLocked(s.routes)
+	return forked
+}
+
+// outputRegistry lists the output sets of a logger and of the loggers
+// derived from it with outputs of their own, so flushing and closing the
+// logger reach every output
+type outputRegistry struct {
+	mu   sync.Mutex
+	sets []*outputSet
+}
+
+// add registers a set
+func (r *outputRegistry) add(set *outputSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sets = append(r.sets, set)
+}
+
+// all returns the sets registered
+func (r *outputRegistry) all() []*outputSet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.sets)
+}
+
+// eachOutput calls fn with every output of the logger and of the loggers
+// derived from it, routed outputs included, each once. The lock of the set
+// holding an output is held during the call, for writing if exclusive.
+func (l *Logger) eachOutput(exclusive bool, fn func(output Output)) {
+	var seen []Output
+	for _, set := range l.outputSets.all() {
+		if exclusive {
+			set.mu.Lock()
+		} else {
+			set.mu.RLock()
+		}
+		for _, output := range set.allLocked() {
+			if !slices.Contains(seen, output) {
+				seen = append(seen, output)
+				fn(output)
+			}
+		}
+		if exclusive {
+			set.mu.Unlock()
+		} else {
+			set.mu.RUnlock()
+		}
+	}
+}
+
+// setRoutesLocked replaces the routes; s.mu must be held
+func (s *outputSet) setRoutesLocked(routes map[string][]Output) {
+	s.routes = routes
+	routed := int32(0)
+	if len(routes) > 0 {
+		routed = 1
+	}
+	atomic.StoreInt32(&s.routed, routed)
+}
+
+// outputsForLocked returns the outputs entries of a component are written
+// to: its route, or the set's outputs; s.mu must be held for reading
+func (s *outputSet) outputsForLocked(component string) []Output {
+	if atomic.LoadInt32(&s.routed) == 0 {
+		return s.list
+	}
+	for name := component; name != ""; {
+		if route, ok := s.routes[name]; ok {
+			return route
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return s.list
+}
+
+// allLocked returns the outputs and the routed outputs, each once; s.mu
+// must be held
+func (s *outputSet) allLocked() []Output {
+	if len(s.routes) == 0 {
+		return s.list
+	}
+	all := append([]Output(nil), s.list...)
+	for _, o := range s.routedLocked() {
+		if !slices.Contains(all, o) {
+			all = append(all, o)
+		}
+	}
+	return all
+}
+
+// routedLocked returns the routed outputs, each once, in the order of their
+// components; s.mu must be held
+func (s *outputSet) routedLocked() []Output {
+	names := make([]string, 0, len(s.routes))
+	for name := range s.routes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var routed []Output
+	for _, name := range names {
+		for _, o := range s.routes[name] {
+			if !slices.Contains(routed, o) {
+				routed = append(routed, o)
+			}
+		}
+	}
+	return routed
+}
+
+// unrouteLocked removes an output from every route, dropping the routes
+// left empty, and reports whether it was routed; s.mu must be held
+func (s *outputSet) unrouteLocked(output Output) bool {
+	found := false
+	routes := make(map[string][]Output, len(s.routes))
+	for name, route := range s.routes {
+		if !slices.Contains(route, output) {
+			routes[name] = route
+			continue
+		}
+		found = true
+		kept := make([]Output, 0, len(route)-1)
+		for _, o := range route {
+			if o != output {
+				kept = append(kept, o)
+			}
+		}
+		if len(kept) > 0 {
+			routes[name] = kept
+		}
+	}
+	if found {
+		s.setRoutesLocked(routes)
+	}
+	return found
+}
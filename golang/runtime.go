@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaxGoroutineDumpBytes bounds the stacks logged by DumpGoroutines
+const MaxGoroutineDumpBytes = 1 << 20
+
+// DumpGoroutines logs the stacks of every goroutine at level, as "Goroutine
+// stacks" with the "goroutines" count and one string per goroutine in
+// "stacks", up to MaxGoroutineDumpBytes; "stacks_truncated" is set when the
+// stacks were cut there. Nothing is collected unless level is enabled.
+func (l *Logger) DumpGoroutines(level Level) {
+	l.dumpGoroutines(1, level)
+}
+
+// LogRuntimeStats logs the memory, GC and goroutine statistics of the
+// process at level, as "Runtime stats" with "goroutines", "heap_alloc",
+// "heap_inuse", "num_gc", "gc_pause_last" and so on. Reading them stops the
+// world briefly, so it suits incidents and periodic checks rather than hot
+// paths.
+func (l *Logger) LogRuntimeStats(level Level) {
+	l.logRuntimeStats(1, level)
+}
+
+// dumpGoroutines is DumpGoroutines for callers skip frames up
+func (l *Logger) dumpGoroutines(skip int, level Level) {
+	if !l.Enabled(level) {
+		return
+	}
+	buf, truncated := goroutineStacks(MaxGoroutineDumpBytes)
+	stacks := strings.Split(strings.TrimSpace(string(buf)), "\n\n")
+	fields := map[string]interface{}{
+		"goroutines": runtime.NumGoroutine(),
+		"stacks":     stacks,
+	}
+	if truncated {
+		fields["stacks_truncated"] = true
+	}
+	l.log(level, skip+1, "Goroutine stacks", fields)
+}
+
+// logRuntimeStats is LogRuntimeStats for callers skip frames up
+func (l *Logger) logRuntimeStats(skip int, level Level) {
+	if !l.allow(level) {
+		return
+	}
+	l.write(level, skip+1, "Runtime stats", runtimeStats())
+}
+
+// DumpGoroutines logs the stacks of every goroutine to the default logger
+// (see Logger.DumpGoroutines)
+func DumpGoroutines(level Level) {
+	GetLogger().dumpGoroutines(1, level)
+}
+
+// LogRuntimeStats logs the runtime statistics of the process to the default
+// logger (see Logger.LogRuntimeStats)
+func LogRuntimeStats(level Level) {
+	GetLogger().logRuntimeStats(1, level)
+}
+
+// HandleDiagnosticSignals logs diagnostics at level when the process gets
+// a signal, instead of copying pprof snippets around during incidents:
+//
+//   - SIGQUIT logs the goroutine stacks and the runtime statistics (see
+//     DumpGoroutines and LogRuntimeStats). The process keeps running,
+//     rather than dumping its stacks to stderr and exiting as Go programs
+//     do by default.
+//   - SIGUSR2 logs the runtime statistics. With HandleSignals, which logs
+//     the logger's state on SIGUSR2, both are logged.
+//
+// The signals are only handled where they exist. The returned function
+// stops handling them, restoring the default behavior of SIGQUIT.
+//
+//	stop := logger.HandleDiagnosticSignals(l, logger.LevelNotice)
+//	defer stop()
+func HandleDiagnosticSignals(l *Logger, level Level) (stop func()) {
+	if len(diagnosticSignals) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, diagnosticSignals...)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				if sig == quitSignal {
+					l.dumpGoroutines(0, level)
+				}
+				l.logRuntimeStats(0, level)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// SetRuntimeStatsFields enables or disables adding "goroutines",
+// "heap_inuse" (bytes), "num_gc" and "gc_pause_last" to every entry at
+// Critical and above of this logger and of every logger derived from it, so
+// failures come with the health of the process at that moment. The
+// statistics are read without stopping the world, unlike LogRuntimeStats.
+// It is disabled by default.
+func (l *Logger) SetRuntimeStatsFields(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&l.settings.runtimeStats, v)
+}
+
+// addRuntimeFields adds the runtime statistics that are cheap to read to an
+// entry
+func addRuntimeFields(entry *LogEntry) {
+	heap := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(heap)
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+	var lastPause time.Duration
+	if len(gc.Pause) > 0 {
+		lastPause = gc.Pause[0]
+	}
+
+	entry.setField("goroutines", runtime.NumGoroutine())
+	if heap[0].Value.Kind() == metrics.KindUint64 {
+		entry.setField("heap_inuse", heap[0].Value.Uint64())
+	}
+	entry.setField("num_gc", gc.NumGC)
+	entry.setField("gc_pause_last", lastPause.String())
+}
+
+// runtimeStats returns the memory, GC and goroutine statistics of the
+// process. It stops the world briefly to read them.
+func runtimeStats() []Field {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	var lastPause time.Duration
+	if m.NumGC > 0 {
+		lastPause = time.Duration(m.PauseNs[(m.NumGC+255)%256])
+	}
+	return []Field{
+		F("goroutines", runtime.NumGoroutine()),
+		F("heap_alloc", m.HeapAlloc),
+		F("heap_inuse", m.HeapInuse),
+		F("heap_sys", m.HeapSys),
+		F("heap_objects", m.HeapObjects),
+		F("stack_inuse", m.StackInuse),
+		F("sys", m.Sys),
+		F("num_gc", m.NumGC),
+		F("gc_pause_total", time.Duration(m.PauseTotalNs).String()),
+		F("gc_pause_last", lastPause.String()),
+		F("gc_cpu_fraction", m.GCCPUFraction),
+		F("gomaxprocs", runtime.GOMAXPROCS(0)),
+	}
+}
+
+// goroutineStacks returns the stacks of every goroutine, up to limit bytes,
+// and whether they were cut
+func goroutineStacks(limit int) ([]byte, bool) {
+	size := min(64<<10, limit)
+	for {
+		buf := make([]byte, size)
+		n := runtime.Stack(buf, true)
+		if n < size {
+			return buf[:n], false
+		}
+		if size >= limit {
+			return buf[:n], true
+		}
+		size = min(size*2, limit)
+	}
+}
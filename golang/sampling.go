@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Sampling by key thins out the entries of Sampled* call sites; sampling by
+// component and by level thins out every entry of a component or at a
+// level, so operators can quiet a noisy part of a program without a code
+// change. Entries at or above the level set with SetNeverSampleLevel are
+// never sampled.
+
+// SetComponentSamplingRate samples every entry of a component, and of its
+// descendants without a policy of their own: the first, then 1 in rate. A
+// rate below 1 removes the policy.
+func (l *Logger) SetComponentSamplingRate(component string, rate int) {
+	if rate < 1 {
+		l.sampler.setComponent(component, nil)
+		return
+	}
+	policy := ratePolicy(rate)
+	l.sampler.setComponent(component, &policy)
+}
+
+// SetComponentSamplingPolicy samples every entry of a component, and of its
+// descendants without a policy of their own, as policy describes
+func (l *Logger) SetComponentSamplingPolicy(component string, policy SamplingPolicy) {
+	l.sampler.setComponent(component, &policy)
+}
+
+// SetLevelSamplingRate samples every entry at a level: the first, then 1 in
+// rate. A rate below 1 removes the policy.
+func (l *Logger) SetLevelSamplingRate(level Level, rate int) {
+	if rate < 1 {
+		l.sampler.setLevel(level, nil)
+		return
+	}
+	policy := ratePolicy(rate)
+	l.sampler.setLevel(level, &policy)
+}
+
+// SetLevelSamplingPolicy samples every entry at a level as policy describes
+func (l *Logger) SetLevelSamplingPolicy(level Level, policy SamplingPolicy) {
+	l.sampler.setLevel(level, &policy)
+}
+
+// ComponentSamplingRates returns the sampling rates set per component, i.e.
+// the Thereafter value of each policy
+func (l *Logger) ComponentSamplingRates() map[string]int {
+	policies := l.ComponentSamplingPolicies()
+	rates := make(map[string]int, len(policies))
+	for component, policy := range policies {
+		rates[component] = policy.Thereafter
+	}
+	return rates
+}
+
+// ComponentSamplingPolicies returns the sampling policies set per component
+func (l *Logger) ComponentSamplingPolicies() map[string]SamplingPolicy {
+	s := l.sampler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	policies := make(map[string]SamplingPolicy, len(s.components))
+	for component, policy := range s.components {
+		policies[component] = policy
+	}
+	return policies
+}
+
+// LevelSamplingRates returns the sampling rates set per level, i.e. the
+// Thereafter value of each policy
+func (l *Logger) LevelSamplingRates() map[Level]int {
+	policies := l.LevelSamplingPolicies()
+	rates := make(map[Level]int, len(policies))
+	for level, policy := range policies {
+		rates[level] = policy.Thereafter
+	}
+	return rates
+}
+
+// LevelSamplingPolicies returns the sampling policies set per level
+func (l *Logger) LevelSamplingPolicies() map[Level]SamplingPolicy {
+	s := l.sampler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	policies := make(map[Level]SamplingPolicy, len(s.levels))
+	for level, policy := range s.levels {
+		policies[level] = policy
+	}
+	return policies
+}
+
+// setComponent sets or, for a nil policy, removes the policy of a component
+func (s *rateSampler) setComponent(component string, policy *SamplingPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if policy == nil {
+		delete(s.components, component)
+	} else {
+		s.components[component] = *policy
+	}
+	delete(s.scopeStates, "c"+component)
+	s.updateScopedLocked()
+}
+
+// setLevel sets or, for a nil policy, removes the policy of a level
+func (s *rateSampler) setLevel(level Level, policy *SamplingPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if policy == nil {
+		delete(s.levels, level)
+	} else {
+		s.levels[level] = *policy
+	}
+	delete(s.scopeStates, "l"+level.String())
+	s.updateScopedLocked()
+}
+
+// replaceScoped swaps the component policies, unless components is nil, and
+// the level policies, unless levels is nil
+func (s *rateSampler) replaceScoped(components map[string]SamplingPolicy, levels map[Level]SamplingPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if components != nil {
+		s.components = components
+	}
+	if levels != nil {
+		s.levels = levels
+	}
+	s.scopeStates = make(map[string]*samplerState)
+	s.updateScopedLocked()
+}
+
+// updateScopedLocked records whether any component or level policy is set;
+// s.mu must be held
+func (s *rateSampler) updateScopedLocked() {
+	scoped := int32(0)
+	if len(s.components) > 0 || len(s.levels) > 0 {
+		scoped = 1
+	}
+	atomic.StoreInt32(&s.scoped, scoped)
+}
+
+// allowEntry reports whether the level and component policies let an entry
+// through. Components share the policy, and its count, of their closest
+// ancestor with one.
+func (s *rateSampler) allowEntry(level Level, component string) bool {
+	if atomic.LoadInt32(&s.scoped) == 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if policy, ok := s.levels[level]; ok && !policy.admit(s.scopeStates, "l"+level.String(), now) {
+		return false
+	}
+	for name := component; name != ""; {
+		if policy, ok := s.components[name]; ok {
+			return policy.admit(s.scopeStates, "c"+name, now)
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Fields carried by the entries logged in a scope, see Begin
+const (
+	ScopeField       = "scope"        // Name of the innermost scope
+	ScopeIDField     = "scope_id"     // ID of the innermost scope
+	ScopeParentField = "scope_parent" // ID of the scope enclosing it, if any
+	ScopeDepthField  = "scope_depth"  // Nesting depth, 0 for an outermost scope
+)
+
+// Scope is a logger for the entries of one operation, opened with Begin and
+// closed with End. Entries logged through it, or through loggers derived
+// from it, carry the fields of the scope; scopes begun from it nest in it.
+type Scope struct {
+	*Logger
+	name   string
+	id     string
+	parent *Scope
+	depth  int
+	start  time.Time
+
+	entries  int64 // Atomic, entries logged in the scope and nested ones
+	warnings int64 // Atomic, those at Warning
+	errors   int64 // Atomic, those at Error or more severe
+	ended    int32 // Atomic, non-zero once ended
+}
+
+// Begin opens a scope for an operation, logging "Begin <name>" at Info,
+// for tree-structured logs of complex operations without tracing:
+//
+//	s := l.Begin("checkout", map[string]interface{}{"order": id})
+//	defer s.End()
+//	s.Info("Charging card")
+//	inner := s.Begin("reserve stock")
+//
+// The entries of the scope carry ScopeField, ScopeIDField, ScopeDepthField
+// and, when nested, ScopeParentField, besides fields. End logs a summary.
+func (l *Logger) Begin(name string, fields ...map[string]interface{}) *Scope {
+	s := &Scope{
+		name:   name,
+		id:     NewULID(),
+		parent: l.scope,
+		start:  l.now(),
+	}
+	scopeFields := []Field{
+		{Key: ScopeField, Value: name},
+		{Key: ScopeIDField, Value: s.id},
+	}
+	if s.parent != nil {
+		s.depth = s.parent.depth + 1
+		scopeFields = append(scopeFields, Field{Key: ScopeParentField, Value: s.parent.id})
+	}
+	scopeFields = append(scopeFields, Field{Key: ScopeDepthField, Value: s.depth})
+
+	s.Logger = l.WithFieldList(scopeFields...)
+	for _, f := range fields {
+		s.Logger = s.Logger.WithFields(f)
+	}
+	// The entry opening the scope counts towards the enclosing ones only
+	s.Logger.log(LevelInfo, 1, "Begin "+name)
+	s.Logger.scope = s
+	return s
+}
+
+// End closes the scope, logging "End <name>" at Info with ElapsedField and
+// the number of "entries", "warnings" and "errors" logged in it, nested
+// scopes included. Only the first call logs.
+func (s *Scope) End() {
+	if !atomic.CompareAndSwapInt32(&s.ended, 0, 1) {
+		return
+	}
+	s.Logger.log(LevelInfo, 1, "End "+s.name, map[string]interface{}{
+		ElapsedField: elapsedSince(s.start, s.now()),
+		"entries":    atomic.LoadInt64(&s.entries),
+		"warnings":   atomic.LoadInt64(&s.warnings),
+		"errors":     atomic.LoadInt64(&s.errors),
+	})
+}
+
+// ID returns the ID of the scope, as carried in ScopeIDField
+func (s *Scope) ID() string {
+	return s.id
+}
+
+// count accounts for an entry logged in the scope, and in the scopes
+// enclosing it, except those already ended: the entry is the summary of
+// End, or late
+func (s *Scope) count(level Level) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if atomic.LoadInt32(&scope.ended) != 0 {
+			continue
+		}
+		atomic.AddInt64(&scope.entries, 1)
+		switch {
+		case level == LevelWarning:
+			atomic.AddInt64(&scope.warnings, 1)
+		case level <= LevelError:
+			atomic.AddInt64(&scope.errors, 1)
+		}
+	}
+}
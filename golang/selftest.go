@@ -0,0 +1,245 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Prober is implemented by outputs that can check their destination is
+// reachable without writing an entry, such as network sinks. SelfTest
+// writes a test entry to the outputs that are neither files, consoles nor
+// Probers.
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+// SelfTestField marks the entries SelfTest writes to outputs it can't probe
+// otherwise, so they can be told apart from real ones
+const SelfTestField = "self_test"
+
+// CheckStatus is the outcome of a self-test check
+type CheckStatus string
+
+const (
+	// CheckOK means nothing is wrong
+	CheckOK CheckStatus = "ok"
+	// CheckWarning means entries are written, but something is likely to
+	// go wrong or went wrong before
+	CheckWarning CheckStatus = "warning"
+	// CheckFailed means entries are lost
+	CheckFailed CheckStatus = "failed"
+)
+
+// SelfTestCheck is the outcome of one check
+type SelfTestCheck struct {
+	// Output names the output checked, as in OutputStats, or is empty for
+	// checks of the logger itself
+	Output string `json:"output,omitempty"`
+	// Check tells what was checked, e.g. "writable"
+	Check  string      `json:"check"`
+	Status CheckStatus `json:"status"`
+	// Detail explains a warning or failure
+	Detail string `json:"detail,omitempty"`
+}
+
+// String returns the check as a line such as
+// "failed  file:/var/log/app.log: writable: permission denied"
+func (c SelfTestCheck) String() string {
+	return fmt.Sprintf("%-7s %s", c.Status, c.describe())
+}
+
+// describe tells what was checked and how it went
+func (c SelfTestCheck) describe() string {
+	var b strings.Builder
+	if c.Output != "" {
+		b.WriteString(c.Output + ": ")
+	}
+	b.WriteString(c.Check)
+	if c.Detail != "" {
+		b.WriteString(": " + c.Detail)
+	}
+	return b.String()
+}
+
+// SelfTestReport is the diagnosis of a logger, see Logger.SelfTest
+type SelfTestReport struct {
+	// OK is false if any check failed; warnings leave it true
+	OK     bool            `json:"ok"`
+	Checks []SelfTestCheck `json:"checks"`
+}
+
+// String returns the checks, one per line
+func (r SelfTestReport) String() string {
+	lines := make([]string, len(r.Checks))
+	for i, c := range r.Checks {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns the failed checks joined as an error, or nil if none failed
+func (r SelfTestReport) Err() error {
+	var errs []error
+	for _, c := range r.Checks {
+		if c.Status == CheckFailed {
+			errs = append(errs, errors.New(c.describe()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add records a check
+func (r *SelfTestReport) add(output, check string, err error, status CheckStatus) {
+	c := SelfTestCheck{Output: output, Check: check, Status: CheckOK}
+	if err != nil {
+		c.Status, c.Detail = status, err.Error()
+	}
+	if c.Status == CheckFailed {
+		r.OK = false
+	}
+	r.Checks = append(r.Checks, c)
+}
+
+// SelfTest checks that the logger can write its entries, for startup checks
+// and operators looking into missing logs, rather than finding out from
+// errors on stderr once entries are lost:
+//
+//	if report := l.SelfTest(ctx); !report.OK {
+//	    fmt.Fprintln(os.Stderr, report)
+//	    os.Exit(1)
+//	}
+//
+// It checks that the logger is open, has outputs and isn't dropping
+// entries, then each output: files are still at their path and writable,
+// and their directory lets them rotate; outputs implementing Prober are
+// probed, within ctx; other outputs, except consoles, are written a test
+// entry carrying SelfTestField. Failures of earlier writes and open
+// circuits are reported as warnings.
+func (l *Logger) SelfTest(ctx context.Context) SelfTestReport {
+	report := SelfTestReport{OK: true}
+
+	l.lifecycle.mu.RLock()
+	closed := l.lifecycle.closed
+	l.lifecycle.mu.RUnlock()
+	var err error
+	if closed {
+		err = errors.New("the logger is closed")
+	}
+	report.add("", "open", err, CheckFailed)
+	if closed {
+		// The outputs are closed along with it
+		return report
+	}
+
+	outputs := l.ListOutputs()
+	err = nil
+	if len(outputs) == 0 {
+		err = errors.New("no outputs, entries are discarded")
+	}
+	report.add("", "outputs", err, CheckFailed)
+
+	err = nil
+	if dropped := l.QueueStats().Dropped; dropped > 0 {
+		err = fmt.Errorf("%d entries dropped with the queue full", dropped)
+	}
+	report.add("", "queue", err, CheckWarning)
+
+	for _, o := range outputs {
+		l.selfTestOutput(ctx, &report, o)
+	}
+	return report
+}
+
+// selfTestOutput checks one output
+func (l *Logger) selfTestOutput(ctx context.Context, report *SelfTestReport, o Output) {
+	health := l.outputHealth(o)
+	name := health.Name
+	switch health.Status {
+	case OutputDegraded:
+		report.add(name, "last write", errors.New(health.LastError), CheckWarning)
+	case OutputCircuitOpen:
+		report.add(name, "circuit", fmt.Errorf("open: %s", health.LastError), CheckWarning)
+	}
+
+	// Look through wrappers such as RetryOutput for what can be checked
+	for inner := o; inner != nil; {
+		switch v := inner.(type) {
+		case *FileOutput:
+			v.selfTest(report, name)
+			return
+		case *ConsoleOutput:
+			report.add(name, "console", nil, CheckOK)
+			return
+		case Prober:
+			report.add(name, "probe", v.Probe(ctx), CheckFailed)
+			return
+		}
+		wrapper, ok := inner.(interface{ Unwrap() Output })
+		if !ok {
+			break
+		}
+		inner = wrapper.Unwrap()
+	}
+
+	entry := &LogEntry{
+		Timestamp:  l.now(),
+		Level:      LevelInfo.String(),
+		Message:    "logger self-test",
+		Component:  l.component,
+		InstanceID: l.instanceID,
+	}
+	entry.setField(SelfTestField, true)
+	report.add(name, "test entry", o.Write(entry), CheckFailed)
+}
+
+// selfTest checks that the file can be written and rotated
+func (o *FileOutput) selfTest(report *SelfTestReport, name string) {
+	o.mu.Lock()
+	file, mode, maxSize := o.file, o.rotation, o.maxSize
+	o.mu.Unlock()
+
+	// Writes to a file removed or moved by another program never show up
+	var err error
+	if info, statErr := os.Stat(o.path); statErr != nil {
+		err = statErr
+	} else if open, openErr := file.Stat(); openErr != nil {
+		err = openErr
+	} else if !os.SameFile(info, open) {
+		err = errors.New("the file at the path isn't the one written; it was moved or replaced")
+	}
+	report.add(name, "path", err, CheckFailed)
+
+	f, err := os.OpenFile(o.path, os.O_APPEND|os.O_WRONLY, 0)
+	if err == nil {
+		f.Close()
+	}
+	report.add(name, "writable", err, CheckFailed)
+
+	// Rotation creates a file next to this one, and copying it reads it
+	err = checkDirWritable(filepath.Dir(o.path))
+	if err == nil && mode == RotateCopyTruncate {
+		if f, err = os.Open(o.path); err == nil {
+			f.Close()
+		}
+	}
+	status := CheckWarning
+	if maxSize > 0 {
+		// Rotation is due at some point, which fails every write after it
+		status = CheckFailed
+	}
+	report.add(name, "rotation ("+mode.String()+")", err, status)
+}
+
+// checkDirWritable creates and removes a file in dir
+func checkDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".logger-selftest-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// Rotator is implemented by outputs that can start a new file on demand
+type Rotator interface {
+	Rotate() error
+}
+
+// Rotate rotates every output of l that implements Rotator, including those
+// wrapped by outputs such as RetryOutput, and returns the errors joined
+func (l *Logger) Rotate() error {
+	var errs []error
+	for _, o := range l.ListOutputs() {
+		if r := findRotator(o); r != nil {
+			errs = append(errs, r.Rotate())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// findRotator returns the Rotator among o and the outputs it wraps, if any
+func findRotator(o Output) Rotator {
+	for o != nil {
+		if r, ok := o.(Rotator); ok {
+			return r
+		}
+		wrapper, ok := o.(interface{ Unwrap() Output })
+		if !ok {
+			return nil
+		}
+		o = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// Dump logs the state of the logger at notice level: its metrics and
+// health, for operators looking into a running process
+func (l *Logger) Dump() {
+	l.write(LevelNotice, 1, "Logger state", nil, map[string]interface{}{
+		"stats":  l.Stats(),
+		"health": l.Health(),
+	})
+}
+
+// HandleSignals gives a process the same signal handling across a fleet:
+//
+//   - the given signals (SIGINT and SIGTERM if none) write the queued
+//     entries, sync and close the outputs, and exit with status 128 plus
+//     the signal number, through the exit function (see SetExitFunc)
+//   - SIGUSR1 rotates the file outputs (see Rotate)
+//   - SIGUSR2 logs the logger's state (see Dump)
+//
+// SIGUSR1 and SIGUSR2 are only handled where they exist. The returned
+// function stops handling the signals.
+//
+//	stop := logger.HandleSignals(l, syscall.SIGTERM, syscall.SIGINT)
+//	defer stop()
+func HandleSignals(l *Logger, signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, slices.Concat(signals, controlSignals)...)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				switch sig {
+				case rotateSignal:
+					if err := l.Rotate(); err != nil {
+						l.reportError(nil, err)
+					}
+				case dumpSignal:
+					l.Dump()
+				default:
+					l.Noticef("Received %v, shutting down", sig)
+					code := 1
+					if s, ok := sig.(syscall.Signal); ok {
+						code = 128 + int(s)
+					}
+					l.Exit(code)
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
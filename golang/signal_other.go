@@ -0,0 +1,23 @@
+//go:build !unix
+
+package logger
+
+import "os"
+
+// Signals HandleSignals binds to rotating the outputs and dumping the state,
+// which don't exist on this platform
+var (
+	rotateSignal os.Signal
+	dumpSignal   os.Signal
+)
+
+// controlSignals are the signals HandleSignals handles besides the shutdown
+// signals it is given
+var controlSignals []os.Signal
+
+// quitSignal dumps the goroutine stacks with HandleDiagnosticSignals, and
+// doesn't exist on this platform either
+var quitSignal os.Signal
+
+// diagnosticSignals are the signals HandleDiagnosticSignals handles
+var diagnosticSignals []os.Signal
@@ -0,0 +1,24 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals HandleSignals binds to rotating the outputs and dumping the state
+var (
+	rotateSignal os.Signal = syscall.SIGUSR1
+	dumpSignal   os.Signal = syscall.SIGUSR2
+)
+
+// controlSignals are the signals HandleSignals handles besides the shutdown
+// signals it is given
+var controlSignals = []os.Signal{rotateSignal, dumpSignal}
+
+// quitSignal dumps the goroutine stacks with HandleDiagnosticSignals
+var quitSignal os.Signal = syscall.SIGQUIT
+
+// diagnosticSignals are the signals HandleDiagnosticSignals handles
+var diagnosticSignals = []os.Signal{quitSignal, dumpSignal}
@@ -0,0 +1,339 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// Wrap returns a driver logging every statement run through d with q.
+// Register it under a name of its own and open databases by that name:
+//
+//	sql.Register("sqlite3-logged", sqllog.Wrap(&sqlite3.SQLiteDriver{}, q))
+//
+// Executions log the rows affected, queries only their duration up to the
+// first row. Transactions log their commit and rollback.
+func Wrap(d driver.Driver, q *QueryLogger) driver.Driver {
+	return &wrappedDriver{driver: d, q: q}
+}
+
+// WrapConnector returns a connector whose connections log every statement
+// with q, for sql.OpenDB:
+//
+//	db := sql.OpenDB(sqllog.WrapConnector(connector, q))
+func WrapConnector(c driver.Connector, q *QueryLogger) driver.Connector {
+	return &connector{connector: c, driver: &wrappedDriver{driver: c.Driver(), q: q}}
+}
+
+// wrappedDriver is the driver returned by Wrap
+type wrappedDriver struct {
+	driver driver.Driver
+	q      *QueryLogger
+}
+
+// Open opens a logged connection
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, q: d.q}, nil
+}
+
+// OpenConnector returns a connector for name, through the connector of the
+// wrapped driver if it has one
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &connector{connector: c, driver: d}, nil
+	}
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+// connector logs the connections of a wrapped connector
+type connector struct {
+	connector driver.Connector
+	driver    *wrappedDriver
+}
+
+// Connect opens a logged connection
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	inner, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: inner, q: c.driver.q}, nil
+}
+
+// Driver returns the wrapping driver
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector opens connections by name, for drivers without connectors
+type dsnConnector struct {
+	name   string
+	driver *wrappedDriver
+}
+
+// Connect opens a logged connection
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+// Driver returns the wrapping driver
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// conn logs the statements of a connection. It implements the optional
+// interfaces database/sql looks for whether the wrapped connection does or
+// not, falling back the way database/sql would.
+type conn struct {
+	driver.Conn
+	q *QueryLogger
+}
+
+// Prepare prepares a logged statement
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares a logged statement
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		c.q.log(ctx, "prepare", query, nil, 0, -1, err)
+		return nil, err
+	}
+	return &stmt{Stmt: s, query: query, q: c.q}, nil
+}
+
+// Begin starts a logged transaction
+//
+// Deprecated: drivers implement BeginTx instead
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx starts a logged transaction
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var inner driver.Tx
+	var err error
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		inner, err = bc.BeginTx(ctx, opts)
+	} else {
+		if opts.Isolation != 0 || opts.ReadOnly {
+			return nil, errors.New("sqllog: driver does not support transaction options")
+		}
+		inner, err = c.Conn.Begin() //nolint:staticcheck // Fallback for old drivers
+	}
+	if err != nil {
+		c.q.log(ctx, "begin", "BEGIN", nil, 0, -1, err)
+		return nil, err
+	}
+	return &tx{Tx: inner, ctx: ctx, q: c.q}, nil
+}
+
+// ExecContext runs a logged statement, or has database/sql prepare it if
+// the wrapped connection can't run it directly
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+	c.q.log(ctx, "exec", query, namedValues(args), time.Since(start), rowsAffected(res, err), err)
+	return res, err
+}
+
+// QueryContext runs a logged query, or has database/sql prepare it if the
+// wrapped connection can't run it directly
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+	c.q.log(ctx, "query", query, namedValues(args), time.Since(start), -1, err)
+	return rows, err
+}
+
+// Ping pings the wrapped connection, if it can be
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the wrapped connection, if it can be
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the wrapped connection can be reused
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue checks an argument as the wrapped connection would
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt logs the executions of a prepared statement
+type stmt struct {
+	driver.Stmt
+	query string
+	q     *QueryLogger
+}
+
+// Exec runs the statement
+//
+// Deprecated: drivers implement ExecContext instead
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesToNamed(args))
+}
+
+// Query runs the query
+//
+// Deprecated: drivers implement QueryContext instead
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamed(args))
+}
+
+// ExecContext runs the statement and logs it
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else if values, convErr := namedToValues(args); convErr != nil {
+		return nil, convErr
+	} else {
+		res, err = s.Stmt.Exec(values) //nolint:staticcheck // Fallback for old drivers
+	}
+	s.q.log(ctx, "exec", s.query, namedValues(args), time.Since(start), rowsAffected(res, err), err)
+	return res, err
+}
+
+// QueryContext runs the query and logs it
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else if values, convErr := namedToValues(args); convErr != nil {
+		return nil, convErr
+	} else {
+		rows, err = s.Stmt.Query(values) //nolint:staticcheck // Fallback for old drivers
+	}
+	s.q.log(ctx, "query", s.query, namedValues(args), time.Since(start), -1, err)
+	return rows, err
+}
+
+// CheckNamedValue checks an argument as the wrapped statement would
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tx logs the end of a transaction
+type tx struct {
+	driver.Tx
+	ctx context.Context
+	q   *QueryLogger
+}
+
+// Commit commits the transaction and logs it
+func (t *tx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.q.log(t.ctx, "commit", "COMMIT", nil, time.Since(start), -1, err)
+	return err
+}
+
+// Rollback rolls the transaction back and logs it
+func (t *tx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.q.log(t.ctx, "rollback", "ROLLBACK", nil, time.Since(start), -1, err)
+	return err
+}
+
+// rowsAffected returns the rows affected by a statement, -1 if unknown
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil || res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// namedValues returns the values of args, as QueryLogger.Log takes them
+func namedValues(args []driver.NamedValue) []interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// valuesToNamed converts positional arguments
+func valuesToNamed(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// namedToValues converts arguments for drivers without named parameters
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sqllog: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,217 @@
+// Package sqllog logs SQL statements with their duration, rows affected and
+// the types of their arguments, either from hand-written calls to QueryLogger.Log or
+// for every statement of a database/sql driver wrapped with Wrap:
+//
+//	q := sqllog.New(l, sqllog.WithSlowThreshold(200*time.Millisecond))
+//	sql.Register("postgres-logged", sqllog.Wrap(&pq.Driver{}, q))
+//	db, err := sql.Open("postgres-logged", dsn)
+//
+// Statements are logged through the "db" component by default, so their
+// verbosity is controlled apart from the rest of the application:
+//
+//	l.SetComponentLevel("db", logger.LevelDebug)
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+const (
+	// DefaultComponent is the component statements are logged through
+	// unless set with WithComponent
+	DefaultComponent = "db"
+	// DefaultLevel is the level statements are logged at unless set with
+	// WithLevel
+	DefaultLevel = logger.LevelDebug
+	// MaxArgLength is the length past which string arguments are cut when
+	// sanitized
+	MaxArgLength = 64
+	// MaxQueryLength is the length past which statements are cut
+	MaxQueryLength = 4096
+)
+
+// ArgsMode controls how statement arguments are logged
+type ArgsMode int
+
+const (
+	// ArgsTypes logs the types of arguments only, so their values, which
+	// may be passwords, tokens or personal data, never reach the logs
+	ArgsTypes ArgsMode = iota
+	// ArgsSanitized logs the values of arguments, with long strings cut at
+	// MaxArgLength and byte slices replaced by their size. Short secrets are
+	// logged as they are, so it suits statements known to carry none.
+	ArgsSanitized
+	// ArgsNone leaves arguments out
+	ArgsNone
+)
+
+// QueryLogger logs SQL statements
+type QueryLogger struct {
+	logger    *logger.Logger
+	component string
+	level     logger.Level
+	slow      time.Duration
+	args      ArgsMode
+}
+
+// Option configures a QueryLogger
+type Option func(q *QueryLogger)
+
+// WithLevel sets the level statements are logged at (default LevelDebug)
+func WithLevel(level logger.Level) Option {
+	return func(q *QueryLogger) {
+		q.level = level
+	}
+}
+
+// WithSlowThreshold logs statements taking at least d at Warning, whatever
+// their level, with "slow" set. Zero, the default, disables it.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(q *QueryLogger) {
+		q.slow = d
+	}
+}
+
+// WithArgs sets how arguments are logged (default ArgsTypes)
+func WithArgs(mode ArgsMode) Option {
+	return func(q *QueryLogger) {
+		q.args = mode
+	}
+}
+
+// WithComponent sets the component statements are logged through (default
+// DefaultComponent); an empty name keeps the component of the logger
+func WithComponent(component string) Option {
+	return func(q *QueryLogger) {
+		q.component = component
+	}
+}
+
+// New returns a QueryLogger logging through l
+func New(l *logger.Logger, opts ...Option) *QueryLogger {
+	q := &QueryLogger{
+		logger:    l,
+		component: DefaultComponent,
+		level:     DefaultLevel,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if q.component != "" {
+		q.logger = l.With(q.component)
+	}
+	return q
+}
+
+// Log logs a statement that took elapsed, with rows affected unless rows
+// is negative. Statements that failed are logged at Error with err, except
+// for sql.ErrNoRows; slow ones at Warning. The entry carries "query",
+// "args", logger.ElapsedField and "rows", and is logged with ctx (see
+// Logger.WithContext), so context extractors add request and trace IDs.
+func (q *QueryLogger) Log(ctx context.Context, query string, args []interface{}, elapsed time.Duration, rows int64, err error) {
+	q.log(ctx, "query", query, args, elapsed, rows, err)
+}
+
+// log logs a statement of kind op, such as "exec"
+func (q *QueryLogger) log(ctx context.Context, op, query string, args []interface{}, elapsed time.Duration, rows int64, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	slow := q.slow > 0 && elapsed >= q.slow
+	level, msg := q.level, "SQL "+op
+	switch {
+	case err != nil:
+		level, msg = logger.LevelError, "SQL "+op+" failed"
+	case slow:
+		level, msg = min(level, logger.LevelWarning), "Slow SQL "+op
+	}
+
+	l := q.logger
+	if ctx != nil {
+		l = l.WithContext(ctx)
+	}
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := []logger.Field{
+		logger.F("query", cleanQuery(query)),
+	}
+	if logged := q.formatArgs(args); logged != nil {
+		fields = append(fields, logger.F("args", logged))
+	}
+	fields = append(fields, logger.F(logger.ElapsedField, float64(elapsed.Microseconds())/1000))
+	if rows >= 0 {
+		fields = append(fields, logger.F("rows", rows))
+	}
+	if slow {
+		fields = append(fields, logger.F("slow", true))
+	}
+	if err != nil {
+		fields = append(fields, logger.F(logger.ErrorFieldKey, err))
+	}
+	l.Log(level, msg, fields...)
+}
+
+// cleanQuery collapses the whitespace of a statement, which is often
+// indented across lines, and cuts it at MaxQueryLength
+func cleanQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > MaxQueryLength {
+		query = truncate(query, MaxQueryLength)
+	}
+	return query
+}
+
+// formatArgs returns the arguments as logged, nil to leave them out
+func (q *QueryLogger) formatArgs(args []interface{}) []interface{} {
+	if len(args) == 0 || q.args == ArgsNone {
+		return nil
+	}
+	logged := make([]interface{}, len(args))
+	for i, arg := range args {
+		if q.args == ArgsTypes {
+			logged[i] = fmt.Sprintf("%T", arg)
+			continue
+		}
+		logged[i] = sanitizeArg(arg)
+	}
+	return logged
+}
+
+// sanitizeArg returns an argument as logged by ArgsSanitized
+func sanitizeArg(arg interface{}) interface{} {
+	switch v := arg.(type) {
+	case string:
+		if len(v) > MaxArgLength {
+			return truncate(v, MaxArgLength)
+		}
+		return v
+	case []byte:
+		return fmt.Sprintf("<%d bytes>", len(v))
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Time:
+		return v
+	default:
+		s := fmt.Sprint(v)
+		if len(s) > MaxArgLength {
+			return truncate(s, MaxArgLength)
+		}
+		return s
+	}
+}
+
+// truncate cuts s to at most n bytes, at a rune boundary, marking the cut
+func truncate(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the logger's own metrics, shared by a logger and
+// everything derived from it. Counters start at zero when the logger is
+// created and only grow.
+type Stats struct {
+	// Logged counts the entries queued for the outputs per level name
+	Logged map[string]uint64 `json:"logged"`
+	// Dropped counts the entries dropped because the queue was full
+	Dropped uint64 `json:"dropped"`
+	// QueueLength is the number of entries currently queued
+	QueueLength int `json:"queue_length"`
+	// QueueMax is the largest number of entries seen waiting in a queue
+	QueueMax int `json:"queue_max"`
+	// QueueCapacity is the maximum number of queued entries
+	QueueCapacity int `json:"queue_capacity"`
+	// Workers is the number of goroutines writing queued entries
+	Workers int `json:"workers"`
+	// Outputs holds the metrics of every output written to, by name
+	Outputs []OutputStats `json:"outputs"`
+}
+
+// OutputStats holds the write metrics of one output
+type OutputStats struct {
+	// Name identifies the output, e.g. "file:/var/log/app.log"
+	Name string `json:"name"`
+	// Writes counts the entries written, including failed writes
+	Writes uint64 `json:"writes"`
+	// Errors counts the writes that failed
+	Errors uint64 `json:"errors"`
+	// AvgLatency is the mean time a write took
+	AvgLatency time.Duration `json:"avg_latency"`
+	// MaxLatency is the longest time a write took
+	MaxLatency time.Duration `json:"max_latency"`
+
+	// Output is the output itself
+	Output Output `json:"-"`
+}
+
+// outputMetrics holds the counters of one output
+type outputMetrics struct {
+	writes     atomic.Uint64
+	errors     atomic.Uint64
+	latency    atomic.Uint64 // Total nanoseconds spent writing
+	maxLatency atomic.Uint64 // Nanoseconds of the slowest write
+	failing    atomic.Bool   // The last write failed
+	lastErr    atomic.Pointer[writeError]
+}
+
+// writeError is a failed write and when it happened
+type writeError struct {
+	err error
+	at  time.Time
+}
+
+// logged counts an entry queued at the level with the given name
+func (s *loggerStats) logged(level string) {
+	if l, ok := levelByName(level); ok {
+		atomic.AddUint64(&s.levels[l], 1)
+	}
+}
+
+// queued records the length of a queue after an entry was pushed
+func (s *loggerStats) queued(length int) {
+	for {
+		cur := atomic.LoadInt64(&s.queueMax)
+		if int64(length) <= cur || atomic.CompareAndSwapInt64(&s.queueMax, cur, int64(length)) {
+			return
+		}
+	}
+}
+
+// output returns the counters of an output, creating them on first use
+func (s *loggerStats) output(o Output) *outputMetrics {
+	if m, ok := s.outputs.Load(o); ok {
+		return m.(*outputMetrics)
+	}
+	m, _ := s.outputs.LoadOrStore(o, &outputMetrics{})
+	return m.(*outputMetrics)
+}
+
+// forget drops the counters of outputs no longer written to
+func (s *loggerStats) forget(outputs ...Output) {
+	for _, o := range outputs {
+		s.outputs.Delete(o)
+	}
+}
+
+// wrote records a write to an output that took d and failed if err is set
+func (m *outputMetrics) wrote(d time.Duration, err error) {
+	m.writes.Add(1)
+	m.failing.Store(err != nil)
+	if err != nil {
+		m.errors.Add(1)
+		m.lastErr.Store(&writeError{err: err, at: time.Now()})
+	}
+	ns := uint64(d)
+	m.latency.Add(ns)
+	for {
+		cur := m.maxLatency.Load()
+		if ns <= cur || m.maxLatency.CompareAndSwap(cur, ns) {
+			return
+		}
+	}
+}
+
+// outputName describes an output for its metrics
+func outputName(o Output) string {
+	switch v := o.(type) {
+	case fmt.Stringer:
+		return v.String()
+	case *FileOutput:
+		return "file:" + v.path
+	case *ConsoleOutput:
+		return "console"
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", o), "*")
+	}
+}
+
+// Stats returns a snapshot of the logger's own metrics, such as how many
+// entries were logged or dropped and how the outputs perform
+func (l *Logger) Stats() Stats {
+	queue := l.QueueStats()
+	stats := Stats{
+		Logged:        make(map[string]uint64, len(AllLevels)),
+		Dropped:       queue.Dropped,
+		QueueLength:   queue.Length,
+		QueueMax:      int(atomic.LoadInt64(&l.stats.queueMax)),
+		QueueCapacity: queue.Capacity,
+		Workers:       int(atomic.LoadInt32(&l.lifecycle.workers)),
+	}
+	for i, level := range AllLevels {
+		stats.Logged[level.String()] = atomic.LoadUint64(&l.stats.levels[i])
+	}
+
+	l.stats.outputs.Range(func(key, value interface{}) bool {
+		o, m := key.(Output), value.(*outputMetrics)
+		s := OutputStats{
+			Name:       outputName(o),
+			Writes:     m.writes.Load(),
+			Errors:     m.errors.Load(),
+			MaxLatency: time.Duration(m.maxLatency.Load()),
+			Output:     o,
+		}
+		if s.Writes > 0 {
+			s.AvgLatency = time.Duration(m.latency.Load() / s.Writes)
+		}
+		stats.Outputs = append(stats.Outputs, s)
+		return true
+	})
+	slices.SortStableFunc(stats.Outputs, func(a, b OutputStats) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return stats
+}
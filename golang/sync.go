@@ -0,0 +1,30 @@
+package logger
+
+// WithSynchronous makes the logger write every entry on the calling goroutine
+// before the logging call returns, with no queue and no workers. Nothing is
+// ever held in memory or dropped for lack of room, at the cost of each call
+// waiting for the outputs. It suits CLIs, tests and code that may crash at
+// any moment. Flush only writes out what batching outputs hold back.
+func WithSynchronous(enabled bool) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("synchronous mode")
+		}
+		l.options.synchronous = enabled
+		return nil
+	}
+}
+
+// Synchronous reports whether entries are written by the logging goroutine
+// (see WithSynchronous)
+func (l *Logger) Synchronous() bool {
+	return l.synchronous
+}
+
+// writeNow writes an entry on the calling goroutine, for synchronous loggers
+func (l *Logger) writeNow(entry *LogEntry) {
+	defer l.guard.leaveWorker(l.guard.enterWorker())
+
+	batch := [1]*LogEntry{entry}
+	l.writeBatch(batch[:])
+}
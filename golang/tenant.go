@@ -0,0 +1,505 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TenantField is the field tenant IDs are logged under
+const TenantField = "tenant"
+
+// WithTenant creates a logger whose entries belong to a tenant: they carry
+// its ID in the tenant field, which a TenantRouter routes them by. The field
+// is always top level, even on loggers made with WithGroup, so grouped
+// entries are routed like any other.
+func (l *Logger) WithTenant(id string) *Logger {
+	newLogger := l.WithFields(nil)
+	newLogger.defaultFields = appendFieldList(newLogger.defaultFields, Field{Key: TenantField, Value: id})
+	return newLogger
+}
+
+// WithTenantID returns a copy of ctx carrying the tenant ID and a logger
+// derived from the context's logger (see FromContext) whose entries belong
+// to the tenant (see WithTenant)
+func WithTenantID(ctx context.Context, id string) context.Context {
+	l := FromContext(ctx).WithTenant(id)
+	return NewContext(context.WithValue(ctx, tenantKey, id), l)
+}
+
+// TenantIDFromContext returns the tenant ID stored by WithTenantID, or ""
+func TenantIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(tenantKey).(string)
+	return id
+}
+
+// ErrInvalidTenant is returned for tenant IDs that can't name a file, such
+// as IDs holding a path separator
+var ErrInvalidTenant = errors.New("logger: invalid tenant ID")
+
+// ValidTenantID reports whether id is a non-empty tenant ID made of ASCII
+// letters, digits, '.', '-' and '_' only, other than "." and "..", so that
+// it can name a file, a directory, a topic or an object prefix without
+// escaping into another tenant's
+func ValidTenantID(id string) bool {
+	if id == "" || id == "." || id == ".." || len(id) > 128 {
+		return false
+	}
+	for _, c := range []byte(id) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// TenantFiles returns a TenantOptions.NewOutput function creating a file
+// output per tenant at pattern, in which "{tenant}" is replaced by the
+// tenant ID, e.g. "/var/log/tenants/{tenant}/app.log". Missing directories
+// are created.
+func TenantFiles(pattern string, format OutputFormat, maxSizeMB int) func(tenant string) (Output, error) {
+	return func(tenant string) (Output, error) {
+		path := strings.ReplaceAll(pattern, "{tenant}", tenant)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		return NewFileOutput(path, format, maxSizeMB)
+	}
+}
+
+// errTenantRouterClosed is returned for tenants opened after Close
+var errTenantRouterClosed = errors.New("logger: tenant router is closed")
+
+// TenantOptions configures a TenantRouter
+type TenantOptions struct {
+	// NewOutput creates the output of a tenant when its first entry is
+	// written, such as a file (see TenantFiles), a topic or an object
+	// prefix named after it. It is only called with valid tenant IDs
+	// (see ValidTenantID).
+	NewOutput func(tenant string) (Output, error)
+	// Fallback receives the entries without a tenant; they are discarded
+	// if it is nil. Entries with an invalid tenant ID are never written.
+	Fallback Output
+	// Quota limits each tenant to this many entries per second, with
+	// bursts of up to QuotaBurst entries; 0 means no limit. SetQuota
+	// overrides it per tenant.
+	Quota      float64
+	QuotaBurst int
+	// MaxTenants, if positive, is the number of tenant outputs kept open;
+	// past it the least recently written, if idle, is closed, and created
+	// again if its tenant logs again. Tenants left without an output are
+	// forgotten, counters included, unless their level or quota was set.
+	MaxTenants int
+}
+
+// TenantStats are the counters of one tenant of a TenantRouter
+type TenantStats struct {
+	Tenant string `json:"tenant"`
+	// Written counts the entries written to the tenant's output
+	Written uint64 `json:"written"`
+	// OverQuota counts the entries discarded over the tenant's quota
+	OverQuota uint64 `json:"over_quota"`
+	// Open reports whether the tenant's output is open
+	Open bool `json:"open"`
+}
+
+// tenant is the state of one tenant of a TenantRouter
+type tenant struct {
+	mu       sync.Mutex // Serializes writes and guards the fields below
+	output   Output     // nil until the first entry, or once closed
+	lastUsed time.Time
+	level    *Level
+	bucket   *tokenBucket
+	stats    TenantStats
+	pinned   bool // Level or quota set, so the tenant is never forgotten
+	pruned   bool // Forgotten by the router, to be looked up again
+}
+
+// TenantRouter is an output routing the entries of each tenant (see
+// WithTenant) to an output of its own, so that the logs of different
+// tenants are kept physically apart. Tenant outputs are created lazily,
+// have their own level and quota, and are closed with the router.
+//
+//	router := logger.NewTenantRouter(logger.TenantOptions{
+//	    NewOutput: logger.TenantFiles("/var/log/tenants/{tenant}/app.log", logger.FormatJSON, 100),
+//	    Quota:     1000,
+//	})
+//	l.AddOutput(router)
+//	l.WithTenant("acme").Info("Invoice sent")
+type TenantRouter struct {
+	opts TenantOptions
+
+	mu           sync.Mutex
+	tenants      map[string]*tenant
+	open         int // Tenant outputs open
+	pruneAt      int // Tenants past which those without an output are forgotten
+	defaultLevel Level
+	closed       bool
+}
+
+// NewTenantRouter returns a router creating tenant outputs as opts describe
+func NewTenantRouter(opts TenantOptions) *TenantRouter {
+	return &TenantRouter{
+		opts:         opts,
+		tenants:      make(map[string]*tenant),
+		defaultLevel: LevelTrace,
+	}
+}
+
+// tenantOf returns the tenant ID of an entry, or "" if it has none
+func tenantOf(entry *LogEntry) string {
+	switch id := entry.Fields[TenantField].(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	default:
+		return fmt.Sprint(id)
+	}
+}
+
+// Write writes the entry to the output of its tenant, or to the fallback
+// output if it has none
+func (r *TenantRouter) Write(entry *LogEntry) error {
+	id := tenantOf(entry)
+	if id == "" {
+		if r.opts.Fallback != nil {
+			return r.opts.Fallback.Write(entry)
+		}
+		return nil
+	}
+	if !ValidTenantID(id) {
+		return fmt.Errorf("%w %q", ErrInvalidTenant, id)
+	}
+
+	t := r.lockTenant(id)
+	defer t.mu.Unlock()
+
+	r.mu.Lock()
+	minLevel := r.defaultLevel
+	r.mu.Unlock()
+	if t.level != nil {
+		minLevel = *t.level
+	}
+	if level, err := ParseLevel(entry.Level); err == nil && level > minLevel {
+		return nil
+	}
+
+	now := time.Now()
+	if t.bucket != nil && !t.bucket.take(now) {
+		t.stats.OverQuota++
+		return nil
+	}
+
+	if t.output == nil {
+		if err := r.openLocked(id, t); err != nil {
+			return err
+		}
+	}
+	t.lastUsed = now
+
+	// Tell the tenant what it lost once its quota lets entries through
+	if t.bucket != nil && t.bucket.suppressed > 0 {
+		summary := &LogEntry{
+			Timestamp: now,
+			Level:     LevelWarning.String(),
+			Message:   fmt.Sprintf("Log quota exceeded, %d entries discarded", t.bucket.suppressed),
+			Component: entry.Component,
+		}
+		summary.setField(TenantField, id)
+		summary.setField("discarded", t.bucket.suppressed)
+		t.bucket.suppressed = 0
+		if err := t.output.Write(summary); err != nil {
+			return err
+		}
+	}
+
+	if err := t.output.Write(entry); err != nil {
+		return err
+	}
+	t.stats.Written++
+	return nil
+}
+
+// tenant returns the state of a tenant, creating it if needed. It may be
+// pruned before it is locked; see lockTenant.
+func (r *TenantRouter) tenant(id string) *tenant {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tenants[id]
+	if !ok {
+		if r.opts.MaxTenants > 0 && len(r.tenants) >= max(r.pruneAt, 2*r.opts.MaxTenants) {
+			r.pruneLocked()
+			r.pruneAt = 2 * len(r.tenants)
+		}
+		t = &tenant{stats: TenantStats{Tenant: id}}
+		if r.opts.Quota > 0 {
+			t.bucket = newTokenBucket(r.opts.Quota, r.opts.QuotaBurst)
+		}
+		r.tenants[id] = t
+	}
+	return t
+}
+
+// lockTenant returns the state of a tenant, created if needed, with its
+// lock held
+func (r *TenantRouter) lockTenant(id string) *tenant {
+	for {
+		t := r.tenant(id)
+		t.mu.Lock()
+		if !t.pruned {
+			return t
+		}
+		t.mu.Unlock()
+	}
+}
+
+// pruneLocked forgets the idle tenants without an output, unless their
+// level or quota was set or they have discarded entries to report; r.mu
+// must be held
+func (r *TenantRouter) pruneLocked() {
+	for id, t := range r.tenants {
+		if !t.mu.TryLock() {
+			// A tenant being written to is in use
+			continue
+		}
+		if t.output == nil && !t.pinned && (t.bucket == nil || t.bucket.suppressed == 0) {
+			t.pruned = true
+			delete(r.tenants, id)
+		}
+		t.mu.Unlock()
+	}
+}
+
+// openLocked creates the output of a tenant, first closing the least
+// recently used ones while MaxTenants are open; t.mu must be held
+func (r *TenantRouter) openLocked(id string, t *tenant) error {
+	if r.opts.NewOutput == nil {
+		return errors.New("logger: tenant router has no NewOutput function")
+	}
+	for {
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			return errTenantRouterClosed
+		}
+		if r.opts.MaxTenants <= 0 || r.open < r.opts.MaxTenants {
+			r.mu.Unlock()
+			break
+		}
+		evict := r.leastRecentlyUsedLocked(t)
+		r.mu.Unlock()
+		if evict == nil {
+			// Every other open tenant is being written to; the next
+			// tenant opened makes up for it
+			break
+		}
+		r.evict(evict)
+	}
+
+	output, err := r.opts.NewOutput(id)
+	if err != nil {
+		return fmt.Errorf("tenant %s: %w", id, err)
+	}
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		output.Close()
+		return errTenantRouterClosed
+	}
+	r.open++
+	r.mu.Unlock()
+	t.output = output
+	return nil
+}
+
+// evict closes the output of a tenant, unless it is being written to, which
+// could be waiting to evict the tenant being opened. It is called outside
+// r.mu, as writes take t.mu before r.mu.
+func (r *TenantRouter) evict(t *tenant) {
+	if !t.mu.TryLock() {
+		return
+	}
+	defer t.mu.Unlock()
+	if t.output == nil {
+		return
+	}
+	t.output.Close()
+	t.output = nil
+	r.mu.Lock()
+	r.open--
+	r.mu.Unlock()
+}
+
+// leastRecentlyUsedLocked returns the open tenant written least recently,
+// other than skip; r.mu must be held
+func (r *TenantRouter) leastRecentlyUsedLocked(skip *tenant) *tenant {
+	var oldest *tenant
+	var oldestAt time.Time
+	for _, t := range r.tenants {
+		if t == skip || !t.mu.TryLock() {
+			// A tenant being written to is in use
+			continue
+		}
+		if t.output != nil && (oldest == nil || t.lastUsed.Before(oldestAt)) {
+			oldest, oldestAt = t, t.lastUsed
+		}
+		t.mu.Unlock()
+	}
+	return oldest
+}
+
+// SetLevel sets the level of a tenant's entries written, overriding the
+// default level
+func (r *TenantRouter) SetLevel(tenant string, level Level) {
+	t := r.lockTenant(tenant)
+	t.level = &level
+	t.pinned = true
+	t.mu.Unlock()
+}
+
+// SetDefaultLevel sets the level of the entries written for tenants without
+// a level of their own; all entries are written by default
+func (r *TenantRouter) SetDefaultLevel(level Level) {
+	r.mu.Lock()
+	r.defaultLevel = level
+	r.mu.Unlock()
+}
+
+// SetQuota limits a tenant to perSecond entries per second with bursts of
+// up to burst entries, overriding TenantOptions.Quota. A rate of zero or
+// less removes its limit.
+func (r *TenantRouter) SetQuota(tenant string, perSecond float64, burst int) {
+	t := r.lockTenant(tenant)
+	t.bucket = nil
+	if perSecond > 0 {
+		t.bucket = newTokenBucket(perSecond, burst)
+	}
+	t.pinned = true
+	t.mu.Unlock()
+}
+
+// Tenants returns the counters of every tenant seen, sorted by ID, save
+// those forgotten once their output was closed (see MaxTenants)
+func (r *TenantRouter) Tenants() []TenantStats {
+	tenants := r.list()
+	stats := make([]TenantStats, len(tenants))
+	for i, t := range tenants {
+		t.mu.Lock()
+		stats[i] = t.stats
+		stats[i].Open = t.output != nil
+		t.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tenant < stats[j].Tenant })
+	return stats
+}
+
+// list returns every tenant seen
+func (r *TenantRouter) list() []*tenant {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tenants := make([]*tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t)
+	}
+	return tenants
+}
+
+// each calls fn with the fallback output and every open tenant output, while
+// it can't be closed, and returns the errors joined
+func (r *TenantRouter) each(fn func(Output) error) error {
+	var errs []error
+	if r.opts.Fallback != nil {
+		errs = append(errs, fn(r.opts.Fallback))
+	}
+	for _, t := range r.list() {
+		t.mu.Lock()
+		if t.output != nil {
+			errs = append(errs, fn(t.output))
+		}
+		t.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// EndBatch ends a batch of the outputs that buffer writes
+func (r *TenantRouter) EndBatch() error {
+	return r.each(func(o Output) error {
+		if b, ok := o.(BufferedOutput); ok {
+			return b.EndBatch()
+		}
+		return nil
+	})
+}
+
+// Flush flushes the outputs that buffer writes
+func (r *TenantRouter) Flush() error {
+	return r.each(func(o Output) error {
+		if b, ok := o.(BufferedOutput); ok {
+			return b.Flush()
+		}
+		return nil
+	})
+}
+
+// Sync syncs the outputs implementing Syncer
+func (r *TenantRouter) Sync() error {
+	return r.each(func(o Output) error {
+		if s, ok := o.(Syncer); ok {
+			return s.Sync()
+		}
+		return nil
+	})
+}
+
+// Rotate rotates the outputs implementing Rotator
+func (r *TenantRouter) Rotate() error {
+	return r.each(func(o Output) error {
+		if rot := findRotator(o); rot != nil {
+			return rot.Rotate()
+		}
+		return nil
+	})
+}
+
+// Close closes the fallback and tenant outputs; entries written afterwards
+// for tenants without an open output are refused
+func (r *TenantRouter) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	var errs []error
+	if r.opts.Fallback != nil {
+		errs = append(errs, r.opts.Fallback.Close())
+	}
+	for _, t := range r.list() {
+		t.mu.Lock()
+		if t.output != nil {
+			errs = append(errs, t.output.Close())
+			t.output = nil
+		}
+		t.mu.Unlock()
+	}
+	r.mu.Lock()
+	r.open = 0
+	r.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// String names the output
+func (r *TenantRouter) String() string {
+	return "tenants"
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+// recordingOutput keeps the messages written to it
+type recordingOutput struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (o *recordingOutput) Write(entry *LogEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, entry.Message)
+	return nil
+}
+
+func (o *recordingOutput) Close() error { return nil }
+
+// written returns the messages written so far
+func (o *recordingOutput) written() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.messages...)
+}
+
+func TestTenantRouterRoutesGroupedLoggers(t *testing.T) {
+	var mu sync.Mutex
+	outputs := make(map[string]*recordingOutput)
+	fallback := &recordingOutput{}
+	router := NewTenantRouter(TenantOptions{
+		NewOutput: func(tenant string) (Output, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			o := &recordingOutput{}
+			outputs[tenant] = o
+			return o, nil
+		},
+		Fallback: fallback,
+	})
+	l := NewLogger(WithOutputs(router))
+
+	l.WithGroup("http").WithTenant("acme").Info("grouped then tenant")
+	l.WithTenant("acme").WithGroup("http").Info("tenant then group", map[string]interface{}{"path": "/"})
+	l.WithGroup("http").Info("no tenant")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	acme := outputs["acme"]
+	mu.Unlock()
+	if acme == nil {
+		t.Fatal("no output created for acme")
+	}
+	if got := acme.written(); len(got) != 2 || got[0] != "grouped then tenant" || got[1] != "tenant then group" {
+		t.Errorf("acme got %q", got)
+	}
+	if got := fallback.written(); len(got) != 1 || got[0] != "no tenant" {
+		t.Errorf("fallback got %q", got)
+	}
+}
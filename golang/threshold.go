@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ThresholdAlert describes a threshold crossed, see RegisterThreshold
+type ThresholdAlert struct {
+	Level  Level         // Least severe level counted
+	Count  int           // Entries counted within Window, one more than the threshold
+	Window time.Duration // Window the entries were counted in
+	Since  time.Time     // Timestamp of the earliest entry counted
+	// Suppressed is the number of times the threshold was crossed during
+	// the cooldown after the previous alert
+	Suppressed int
+	// Message, Component and Fields are those of the entry that crossed the
+	// threshold
+	Message   string
+	Component string
+	Fields    map[string]interface{}
+}
+
+// ThresholdFunc is called when a threshold is crossed, on a goroutine of its
+// own so that paging or calling a webhook doesn't hold up logging
+type ThresholdFunc func(alert ThresholdAlert)
+
+// Threshold is a registered alert threshold
+type Threshold struct {
+	level   Level
+	count   int
+	window  time.Duration
+	fn      ThresholdFunc
+	stopped int32 // Atomic, non-zero once stopped
+
+	mu         sync.Mutex
+	times      []time.Time // Ring of the timestamps of the last count+1 entries
+	next       int         // Position of the oldest timestamp in times
+	cooldown   time.Duration
+	quietUntil time.Time // End of the cooldown of the last alert
+	suppressed int
+}
+
+// RegisterThreshold calls fn when more than count entries at level or more
+// severe are logged within window, such as to page someone or call a
+// webhook without an alerting pipeline:
+//
+//	l.RegisterThreshold(logger.LevelError, 50, time.Minute, func(a logger.ThresholdAlert) {
+//	    pager.Trigger(fmt.Sprintf("%d errors in %s, last: %s", a.Count, a.Window, a.Message))
+//	})
+//
+// Once fn has been called it isn't called again for a cooldown, the window
+// unless set with SetCooldown. Entries are counted as hooks see them, so
+// entries filtered out, sampled or rate limited don't count. Thresholds are
+// shared with every logger derived via With or WithFields.
+func (l *Logger) RegisterThreshold(level Level, count int, window time.Duration, fn ThresholdFunc) *Threshold {
+	if count < 0 {
+		count = 0
+	}
+	t := &Threshold{
+		level:    level,
+		count:    count,
+		window:   window,
+		fn:       fn,
+		times:    make([]time.Time, count+1),
+		cooldown: window,
+	}
+	levels := make([]Level, 0, int(level)+1)
+	for lv := LevelEmergency; lv <= level; lv++ {
+		levels = append(levels, lv)
+	}
+	l.RegisterHook(levels, t.observe)
+	return t
+}
+
+// SetCooldown sets how long the threshold stays quiet after an alert
+func (t *Threshold) SetCooldown(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cooldown = d
+}
+
+// Stop stops the threshold from alerting and counting entries
+func (t *Threshold) Stop() {
+	atomic.StoreInt32(&t.stopped, 1)
+}
+
+// observe is the hook counting entries
+func (t *Threshold) observe(entry *LogEntry) error {
+	if atomic.LoadInt32(&t.stopped) != 0 {
+		return nil
+	}
+	now := entry.Timestamp
+
+	t.mu.Lock()
+	// The ring holds the last count+1 timestamps; the threshold is crossed
+	// when the oldest of them is within the window
+	t.times[t.next] = now
+	t.next = (t.next + 1) % len(t.times)
+	oldest := t.times[t.next]
+	if oldest.IsZero() || now.Sub(oldest) >= t.window {
+		t.mu.Unlock()
+		return nil
+	}
+	if now.Before(t.quietUntil) {
+		t.suppressed++
+		t.mu.Unlock()
+		return nil
+	}
+	alert := ThresholdAlert{
+		Level:      t.level,
+		Count:      len(t.times),
+		Window:     t.window,
+		Since:      oldest,
+		Suppressed: t.suppressed,
+		Message:    entry.Message,
+		Component:  entry.Component,
+	}
+	t.quietUntil = now.Add(t.cooldown)
+	t.suppressed = 0
+	t.mu.Unlock()
+
+	// The entry goes back to the pool once written
+	if len(entry.Fields) > 0 {
+		alert.Fields = make(map[string]interface{}, len(entry.Fields))
+		for k, v := range entry.Fields {
+			alert.Fields[k] = v
+		}
+	}
+	go t.fn(alert)
+	return nil
+}
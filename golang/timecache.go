@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// timeCache formats entry timestamps, reusing the formatted date and time of
+// day while entries keep arriving within the same second. Only the
+// sub-second digits are formatted for each entry, so a burst of entries
+// doesn't each pay for a full time.Format.
+type timeCache struct {
+	layout   string // Layout up to whole seconds, e.g. "2006-01-02 15:04:05"
+	digits   int    // Sub-second digits to append
+	trimZero bool   // Drop trailing zeros of the fraction, as RFC 3339 does
+	zone     bool   // Append the zone offset as in RFC 3339
+	current  atomic.Pointer[cachedSecond]
+}
+
+// cachedSecond is the formatted form of one second in one location
+type cachedSecond struct {
+	unix   int64
+	loc    *time.Location
+	prefix []byte
+	zone   []byte
+}
+
+var (
+	// textTimes formats timestamps of the text format
+	textTimes = &timeCache{layout: "2006-01-02 15:04:05", digits: 3}
+	// jsonTimes formats timestamps of the JSON format, like
+	// time.RFC3339Nano
+	jsonTimes = &timeCache{layout: "2006-01-02T15:04:05", digits: 9, trimZero: true, zone: true}
+)
+
+// second returns the formatted second t falls in
+func (c *timeCache) second(t time.Time) *cachedSecond {
+	unix, loc := t.Unix(), t.Location()
+	if s := c.current.Load(); s != nil && s.unix == unix && s.loc == loc {
+		return s
+	}
+	s := &cachedSecond{unix: unix, loc: loc, prefix: t.AppendFormat(nil, c.layout)}
+	if c.zone {
+		s.zone = t.AppendFormat(nil, "Z07:00")
+	}
+	c.current.Store(s)
+	return s
+}
+
+// appendTime appends t formatted with the cache's layout
+func (c *timeCache) appendTime(buf []byte, t time.Time) []byte {
+	s := c.second(t)
+	buf = append(buf, s.prefix...)
+
+	var frac [9]byte
+	ns := t.Nanosecond()
+	for i := 8; i >= 0; i-- {
+		frac[i] = byte('0' + ns%10)
+		ns /= 10
+	}
+	n := c.digits
+	if c.trimZero {
+		for n > 0 && frac[n-1] == '0' {
+			n--
+		}
+	}
+	if n > 0 {
+		buf = append(buf, '.')
+		buf = append(buf, frac[:n]...)
+	}
+	return append(buf, s.zone...)
+}
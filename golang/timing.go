@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// OperationField carries the name of operations timed with TimeOperation
+	OperationField = "operation"
+	// ElapsedField carries the duration of an operation in milliseconds
+	ElapsedField = "elapsed_ms"
+)
+
+// TimeOperation logs the start of an operation at level and returns a
+// function logging its end, with ElapsedField, when called:
+//
+//	done := l.TimeOperation(logger.LevelDebug, "rebuild index")
+//	defer done()
+//
+// Passing errors to the function, such as done(err) from a deferred
+// closure, logs a failure at Error with the errors in ErrorFieldKey
+// instead; nil errors are ignored. Only the first call logs. Both entries
+// carry the name in OperationField and fields.
+func (l *Logger) TimeOperation(level Level, name string, fields ...map[string]interface{}) func(errs ...error) {
+	return l.timeOperation(1, level, name, fields)
+}
+
+// timeOperation is TimeOperation for callers skip frames up
+func (l *Logger) timeOperation(skip int, level Level, name string, fields []map[string]interface{}) func(errs ...error) {
+	start := l.now()
+	l.log(level, skip+1, "Starting "+name, withFields(fields, map[string]interface{}{OperationField: name})...)
+
+	var done int32
+	return func(errs ...error) {
+		if !atomic.CompareAndSwapInt32(&done, 0, 1) {
+			return
+		}
+		end := map[string]interface{}{
+			OperationField: name,
+			ElapsedField:   elapsedSince(start, l.now()),
+		}
+		if err := joinErrors(errs); err != nil {
+			end[ErrorFieldKey] = err
+			l.log(min(level, LevelError), 1, "Failed "+name, withFields(fields, end)...)
+			return
+		}
+		l.log(level, 1, "Finished "+name, withFields(fields, end)...)
+	}
+}
+
+// joinErrors returns the only non-nil error of errs as is, or joins them
+func joinErrors(errs []error) error {
+	var found error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if found != nil {
+			return errors.Join(errs...)
+		}
+		found = err
+	}
+	return found
+}
+
+// withFields returns fields followed by more, leaving fields unchanged
+func withFields(fields []map[string]interface{}, more map[string]interface{}) []map[string]interface{} {
+	all := make([]map[string]interface{}, 0, len(fields)+1)
+	return append(append(all, fields...), more)
+}
+
+// TimeOperation logs the start of an operation to the default logger and
+// returns a function logging its end (see Logger.TimeOperation)
+func TimeOperation(level Level, name string, fields ...map[string]interface{}) func(errs ...error) {
+	return GetLogger().timeOperation(1, level, name, fields)
+}
+
+// elapsedSince returns the milliseconds from start to now, as ElapsedField
+// holds them
+func elapsedSince(start, now time.Time) float64 {
+	return float64(now.Sub(start).Microseconds()) / 1000
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// MinAutoQueueSize and MaxAutoQueueSize bound the queue capacity chosen
+	// from GOMAXPROCS when the size isn't set with WithQueueSize. Small
+	// machines get DefaultQueueSize, as without tuning.
+	MinAutoQueueSize = DefaultQueueSize
+	MaxAutoQueueSize = 16384
+	// MaxAutoWorkers bounds the number of workers added under sustained load
+	// unless set with WithMaxWorkers
+	MaxAutoWorkers = 8
+
+	autoQueuePerProc = 256         // Queue capacity per GOMAXPROCS
+	tuneInterval     = time.Second // How often the tuner samples the queue
+	tuneBacklogTicks = 3           // Samples in a row showing a backlog before a worker is added
+)
+
+// autoQueueSize picks the queue capacity from GOMAXPROCS, so small programs
+// don't reserve memory they never use and large servers get room for bursts
+func autoQueueSize() int {
+	return min(max(autoQueuePerProc*runtime.GOMAXPROCS(0), MinAutoQueueSize), MaxAutoQueueSize)
+}
+
+// autoMaxWorkers is the default bound on workers added under load. Workers
+// mostly wait on their outputs, so a second one helps even on one processor.
+func autoMaxWorkers() int {
+	return min(max(runtime.GOMAXPROCS(0)/2, 2), MaxAutoWorkers)
+}
+
+// WithAutoTuning enables or disables sizing the queue and workers
+// automatically (enabled by default). When enabled, a queue size not set with
+// WithQueueSize is chosen from GOMAXPROCS, between MinAutoQueueSize and
+// MaxAutoQueueSize, and unless set with WithWorkers or component ordering is
+// enabled, workers are added while the queue stays more than half full or
+// drops entries, up to WithMaxWorkers. When disabled, DefaultQueueSize and
+// DefaultWorkers apply.
+func WithAutoTuning(enabled bool) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("auto tuning")
+		}
+		l.options.fixed = !enabled
+		return nil
+	}
+}
+
+// WithMaxWorkers bounds the number of workers automatic tuning may run
+// (default half of GOMAXPROCS, at least 2 and at most MaxAutoWorkers)
+func WithMaxWorkers(n int) Option {
+	return func(l *Logger) error {
+		if l.options == nil || !l.options.creating {
+			return errConstructionOnly("max workers")
+		}
+		if n < 1 {
+			return errInvalidWorkers(n)
+		}
+		l.options.maxWorkers = n
+		return nil
+	}
+}
+
+// tune adds workers, up to maxWorkers, while the shared queue stays backed
+// up, until the logger is closed
+func (l *Logger) tune(maxWorkers, batchSize int) {
+	ticker := time.NewTicker(tuneInterval)
+	defer ticker.Stop()
+
+	queue := l.queues[0]
+	backlog := 0
+	dropped := atomic.LoadUint64(&l.stats.dropped)
+	for atomic.LoadInt32(&l.lifecycle.workers) < int32(maxWorkers) {
+		select {
+		case <-ticker.C:
+		case <-l.done:
+			return
+		}
+
+		d := atomic.LoadUint64(&l.stats.dropped)
+		if queue.len() > queue.cap()/2 || d > dropped {
+			backlog++
+		} else {
+			backlog = 0
+		}
+		dropped = d
+
+		if backlog >= tuneBacklogTicks && l.addWorker(queue, batchSize) {
+			backlog = 0
+		}
+	}
+}
+
+// addWorker starts another worker on a shared queue. It waits for a flush in
+// flight to complete first, since each flush sends one sentinel per worker.
+func (l *Logger) addWorker(queue entryQueue, batchSize int) bool {
+	select {
+	case l.lifecycle.flushing <- struct{}{}:
+		defer func() { <-l.lifecycle.flushing }()
+	case <-l.done:
+		return false
+	}
+
+	// While the logger isn't closed, workers are running, so the running
+	// count can't have reached zero
+	l.lifecycle.mu.RLock()
+	defer l.lifecycle.mu.RUnlock()
+	if l.lifecycle.closed {
+		return false
+	}
+	atomic.AddInt32(&l.lifecycle.running, 1)
+	atomic.AddInt32(&l.lifecycle.workers, 1)
+	l.wg.Add(1)
+	go l.processLogQueue(queue, batchSize)
+	return true
+}
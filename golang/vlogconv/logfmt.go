@@ -0,0 +1,167 @@
+package vlogconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// appendLogfmt appends an entry as a logfmt line:
+//
+//	time=2024-01-02T15:04:05Z level=INFO msg="user logged in" component=auth user_id=42
+//
+// Fields of nested objects are flattened into dotted keys; other values
+// that aren't scalars are written as their JSON encoding.
+func appendLogfmt(buf []byte, entry *logger.LogEntry) []byte {
+	buf = append(buf, "time="...)
+	buf = entry.Timestamp.AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, " level="...)
+	buf = appendLogfmtString(buf, entry.Level)
+	buf = append(buf, " msg="...)
+	buf = appendLogfmtString(buf, entry.Message)
+	if entry.Component != "" {
+		buf = append(buf, " component="...)
+		buf = appendLogfmtString(buf, entry.Component)
+	}
+	if entry.File != "" {
+		buf = append(buf, " file="...)
+		buf = appendLogfmtString(buf, entry.File)
+	}
+	if entry.Line != 0 {
+		buf = append(buf, " line="...)
+		buf = strconv.AppendInt(buf, int64(entry.Line), 10)
+	}
+	if entry.InstanceID != "" {
+		buf = append(buf, " instance_id="...)
+		buf = appendLogfmtString(buf, entry.InstanceID)
+	}
+	for _, f := range entry.FieldList() {
+		buf = appendLogfmtField(buf, f.Key, f.Value)
+	}
+	return append(buf, '\n')
+}
+
+// appendLogfmtField appends " key=value", flattening nested objects
+func appendLogfmtField(buf []byte, key string, value interface{}) []byte {
+	if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			buf = appendLogfmtField(buf, key+"."+k, m[k])
+		}
+		return buf
+	}
+	buf = append(buf, ' ')
+	buf = appendLogfmtKey(buf, key)
+	buf = append(buf, '=')
+	return appendLogfmtValue(buf, value)
+}
+
+// appendLogfmtKey appends a key, replacing the characters logfmt keys can't
+// hold
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+	for _, c := range []byte(key) {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			c = '_'
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+// appendLogfmtValue appends a scalar bare, anything else quoted
+func appendLogfmtValue(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendLogfmtString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case float64:
+		return appendLogfmtFloat(buf, v)
+	case float32:
+		return appendLogfmtFloat(buf, float64(v))
+	case json.Number:
+		return append(buf, v...)
+	case time.Time:
+		return v.AppendFormat(buf, time.RFC3339Nano)
+	case time.Duration:
+		return appendLogfmtString(buf, v.String())
+	case error:
+		return appendLogfmtString(buf, v.Error())
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return appendLogfmtString(buf, fmt.Sprint(value))
+	}
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		// Values encoding to a JSON string, e.g. with MarshalText
+		return appendLogfmtString(buf, s)
+	}
+	if len(data) > 0 && (data[0] == '-' || data[0] >= '0' && data[0] <= '9') {
+		return append(buf, data...)
+	}
+	return strconv.AppendQuote(buf, string(data))
+}
+
+// appendLogfmtFloat appends a float, quoting NaN and infinities so they
+// read back as strings, like the JSON format writes them
+func appendLogfmtFloat(buf []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.AppendQuote(buf, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, 64)
+}
+
+// appendLogfmtString appends a string, quoted unless it reads back as the
+// same string bare
+func appendLogfmtString(buf []byte, s string) []byte {
+	if needsLogfmtQuote(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// needsLogfmtQuote reports whether s must be quoted: if it is empty, holds
+// spaces, quotes, '=' or control characters, or would read back as a
+// number, boolean or null
+func needsLogfmtQuote(s string) bool {
+	if s == "" || s == "true" || s == "false" || s == "null" {
+		return true
+	}
+	if s[0] == '-' || s[0] >= '0' && s[0] <= '9' {
+		if json.Valid([]byte(s)) {
+			return true
+		}
+	}
+	for _, c := range []byte(s) {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
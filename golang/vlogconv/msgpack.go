@@ -0,0 +1,242 @@
+package vlogconv
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// appendMsgpackEntry appends an entry as a MessagePack map with the keys of
+// the JSON format. The timestamp is a MessagePack timestamp.
+func appendMsgpackEntry(buf []byte, entry *logger.LogEntry) []byte {
+	n := 3
+	for _, present := range []bool{entry.Component != "", entry.File != "", entry.Line != 0, len(entry.Fields) > 0, entry.InstanceID != ""} {
+		if present {
+			n++
+		}
+	}
+	buf = appendMsgpackMapHeader(buf, n)
+	buf = appendMsgpackString(buf, "timestamp")
+	buf = appendMsgpackTime(buf, entry.Timestamp)
+	buf = appendMsgpackString(buf, "level")
+	buf = appendMsgpackString(buf, entry.Level)
+	buf = appendMsgpackString(buf, "message")
+	buf = appendMsgpackString(buf, entry.Message)
+	if entry.Component != "" {
+		buf = appendMsgpackString(buf, "component")
+		buf = appendMsgpackString(buf, entry.Component)
+	}
+	if entry.File != "" {
+		buf = appendMsgpackString(buf, "file")
+		buf = appendMsgpackString(buf, entry.File)
+	}
+	if entry.Line != 0 {
+		buf = appendMsgpackString(buf, "line")
+		buf = appendMsgpackInt(buf, int64(entry.Line))
+	}
+	if len(entry.Fields) > 0 {
+		buf = appendMsgpackString(buf, "fields")
+		fields := entry.FieldList()
+		buf = appendMsgpackMapHeader(buf, len(fields))
+		for _, f := range fields {
+			buf = appendMsgpackString(buf, f.Key)
+			buf = appendMsgpack(buf, f.Value)
+		}
+	}
+	if entry.InstanceID != "" {
+		buf = appendMsgpackString(buf, "instance_id")
+		buf = appendMsgpackString(buf, entry.InstanceID)
+	}
+	return buf
+}
+
+// appendMsgpack appends a value. Types without a MessagePack equivalent are
+// written as their JSON encoding would decode.
+func appendMsgpack(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if v {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case string:
+		return appendMsgpackString(buf, v)
+	case []byte:
+		return appendMsgpackBinary(buf, v)
+	case int:
+		return appendMsgpackInt(buf, int64(v))
+	case int8:
+		return appendMsgpackInt(buf, int64(v))
+	case int16:
+		return appendMsgpackInt(buf, int64(v))
+	case int32:
+		return appendMsgpackInt(buf, int64(v))
+	case int64:
+		return appendMsgpackInt(buf, v)
+	case uint:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint8:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint16:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint32:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint64:
+		return appendMsgpackUint(buf, v)
+	case float32:
+		buf = append(buf, 0xca)
+		return binary.BigEndian.AppendUint32(buf, math.Float32bits(v))
+	case float64:
+		buf = append(buf, 0xcb)
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(v))
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendMsgpackInt(buf, i)
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendMsgpackUint(buf, u)
+		}
+		f, _ := strconv.ParseFloat(string(v), 64)
+		return appendMsgpack(buf, f)
+	case time.Time:
+		return appendMsgpackTime(buf, v)
+	case time.Duration:
+		return appendMsgpackString(buf, v.String())
+	case error:
+		return appendMsgpackString(buf, v.Error())
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		buf = appendMsgpackMapHeader(buf, len(v))
+		for _, k := range keys {
+			buf = appendMsgpackString(buf, k)
+			buf = appendMsgpack(buf, v[k])
+		}
+		return buf
+	case []interface{}:
+		buf = appendMsgpackArrayHeader(buf, len(v))
+		for _, e := range v {
+			buf = appendMsgpack(buf, e)
+		}
+		return buf
+	}
+
+	// Structs, typed slices and maps, and types with their own JSON
+	// encoding are written as that encoding decodes
+	data, err := json.Marshal(value)
+	if err != nil {
+		return appendMsgpackString(buf, fmt.Sprint(value))
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return appendMsgpackString(buf, string(data))
+	}
+	return appendMsgpack(buf, generic)
+}
+
+// appendMsgpackInt appends a signed integer in its smallest encoding
+func appendMsgpackInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(buf, uint64(v))
+	case v >= -32:
+		return append(buf, byte(int8(v)))
+	case v >= math.MinInt8:
+		return append(buf, 0xd0, byte(int8(v)))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(v))
+	}
+}
+
+// appendMsgpackUint appends an unsigned integer in its smallest encoding
+func appendMsgpackUint(buf []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(buf, byte(v))
+	case v <= math.MaxUint8:
+		return append(buf, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), v)
+	}
+}
+
+// appendMsgpackString appends a string
+func appendMsgpackString(buf []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n <= 31:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+// appendMsgpackBinary appends a byte slice
+func appendMsgpackBinary(buf []byte, b []byte) []byte {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xc5), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xc6), uint32(n))
+	}
+	return append(buf, b...)
+}
+
+// appendMsgpackMapHeader appends the header of a map of n pairs
+func appendMsgpackMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdf), uint32(n))
+	}
+}
+
+// appendMsgpackArrayHeader appends the header of an array of n elements
+func appendMsgpackArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdd), uint32(n))
+	}
+}
+
+// appendMsgpackTime appends a time as a 96-bit MessagePack timestamp, which
+// holds any time.Time, zone aside
+func appendMsgpackTime(buf []byte, t time.Time) []byte {
+	buf = append(buf, 0xc7, 12, 0xff) // ext 8, 12 bytes, type -1
+	buf = binary.BigEndian.AppendUint32(buf, uint32(t.Nanosecond()))
+	return binary.BigEndian.AppendUint64(buf, uint64(t.Unix()))
+}
@@ -0,0 +1,96 @@
+// Package vlogconv converts logs between formats: the text and JSON formats
+// of the logger package, logfmt and MessagePack, for feeding archived logs
+// into systems that only accept one of them.
+//
+//	n, err := vlogconv.Convert(out, in, vlogread.FormatLogfmt, vlogread.Options{})
+//
+// A Writer writes entries in any of these formats, and as an output lets a
+// logger write logfmt or MessagePack directly.
+package vlogconv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+// Writer writes entries to an io.Writer in one format. Every attribute and
+// field of an entry is kept, except in the text format, which has no
+// instance ID. It implements logger.Output.
+type Writer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format vlogread.Format
+	buf    []byte
+}
+
+// NewWriter returns a Writer writing entries to w in format, which can't be
+// FormatAuto
+func NewWriter(w io.Writer, format vlogread.Format) (*Writer, error) {
+	switch format {
+	case vlogread.FormatJSON, vlogread.FormatText, vlogread.FormatLogfmt, vlogread.FormatMsgpack:
+		return &Writer{w: w, format: format}, nil
+	default:
+		return nil, errors.New("no output format given")
+	}
+}
+
+// Write writes an entry
+func (w *Writer) Write(entry *logger.LogEntry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	buf := w.buf[:0]
+	switch w.format {
+	case vlogread.FormatJSON:
+		buf, err = logger.AppendEntry(buf, entry, logger.FormatJSON)
+	case vlogread.FormatText:
+		buf, err = logger.AppendEntry(buf, entry, logger.FormatText)
+	case vlogread.FormatLogfmt:
+		buf = appendLogfmt(buf, entry)
+	case vlogread.FormatMsgpack:
+		buf = appendMsgpackEntry(buf, entry)
+	}
+	if err != nil {
+		return err
+	}
+	w.buf = buf
+	_, err = w.w.Write(buf)
+	return err
+}
+
+// Close does nothing; the io.Writer belongs to the caller
+func (w *Writer) Close() error {
+	return nil
+}
+
+// Convert reads the entries of src, parsed as opts describe, and writes
+// them to dst in format to. It returns the number of entries written.
+// Lines that aren't entries are skipped unless opts.Strict is set, as with
+// vlogread.Reader.
+func Convert(dst io.Writer, src io.Reader, to vlogread.Format, opts vlogread.Options) (int, error) {
+	out := bufio.NewWriter(dst)
+	w, err := NewWriter(out, to)
+	if err != nil {
+		return 0, err
+	}
+
+	r := vlogread.NewReader(src, opts)
+	n := 0
+	for r.Next() {
+		if err := w.Write(r.Entry()); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := r.Err(); err != nil {
+		out.Flush()
+		return n, err
+	}
+	return n, out.Flush()
+}
@@ -0,0 +1,100 @@
+package vlogread
+
+import (
+	"bytes"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// chunk is the text of one entry: a JSON or logfmt line, or the lines of a
+// text entry
+type chunk struct {
+	text   []byte
+	line   int   // Number of its first line
+	offset int64 // Byte offset of its first line
+	format Format
+}
+
+// assembler groups lines into chunks. A text entry whose message holds
+// newlines spans the lines up to the next entry, so it is held back until
+// the next entry starts or flush is called.
+type assembler struct {
+	format  Format
+	pending *chunk // Text entry that may continue
+	ready   []chunk
+}
+
+// add takes the next line, numbered n and starting at offset; the assembler
+// keeps it
+func (a *assembler) add(line []byte, n int, offset int64) {
+	switch format := lineFormat(line, a.format); {
+	case format != FormatText:
+		a.flush()
+		a.ready = append(a.ready, chunk{text: line, line: n, offset: offset, format: format})
+	case isTextStart(line):
+		a.flush()
+		a.pending = &chunk{text: line, line: n, offset: offset, format: FormatText}
+	case a.pending != nil:
+		a.pending.text = append(append(a.pending.text, '\n'), line...)
+	case len(bytes.TrimSpace(line)) > 0:
+		// Not an entry, left for parsing to report
+		a.ready = append(a.ready, chunk{text: line, line: n, offset: offset, format: FormatText})
+	}
+}
+
+// flush makes the held back text entry, if any, ready
+func (a *assembler) flush() {
+	if a.pending != nil {
+		a.ready = append(a.ready, *a.pending)
+		a.pending = nil
+	}
+}
+
+// pop returns the oldest ready chunk
+func (a *assembler) pop() (chunk, bool) {
+	if len(a.ready) == 0 {
+		return chunk{}, false
+	}
+	c := a.ready[0]
+	a.ready = a.ready[1:]
+	return c, true
+}
+
+// lineFormat returns the format of a line, given the expected format:
+// FormatText if it is or may be part of a text entry
+func lineFormat(line []byte, format Format) Format {
+	switch format {
+	case FormatJSON, FormatLogfmt:
+		if len(bytes.TrimSpace(line)) == 0 {
+			return FormatText
+		}
+		return format
+	case FormatAuto:
+		trimmed := bytes.TrimLeft(line, " \t")
+		switch {
+		case bytes.HasPrefix(trimmed, []byte{'{'}):
+			return FormatJSON
+		case bytes.HasPrefix(trimmed, []byte("time=")), bytes.HasPrefix(trimmed, []byte("ts=")):
+			return FormatLogfmt
+		}
+	}
+	return FormatText
+}
+
+// parseChunk parses a chunk into an entry
+func parseChunk(c chunk, opts Options) (*logger.LogEntry, *LineError) {
+	var entry *logger.LogEntry
+	var err error
+	switch c.format {
+	case FormatJSON:
+		entry, err = ParseJSON(c.text)
+	case FormatLogfmt:
+		entry, err = ParseLogfmt(c.text)
+	default:
+		entry, err = ParseText(c.text, opts.Location)
+	}
+	if err != nil {
+		return nil, &LineError{Line: c.line, Text: string(c.text), Err: err}
+	}
+	return entry, nil
+}
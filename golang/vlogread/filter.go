@@ -0,0 +1,70 @@
+package vlogread
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// Filter selects entries. Its zero value selects every entry; each field set
+// narrows the selection.
+type Filter struct {
+	// Level selects entries at this level or more severe; audit entries,
+	// which have no severity, are left out
+	Level *logger.Level
+	// Component selects entries of this component and its descendants
+	// ("db" selects "db.pool"), or of the components matching it if it is
+	// a glob pattern such as "http.*"
+	Component string
+	// Fields selects entries having each of these fields, with a value
+	// printing as the given string
+	Fields map[string]string
+	// Contains selects entries whose message contains this string
+	Contains string
+	// Since selects entries logged at or after this time
+	Since time.Time
+	// Until selects entries logged before this time
+	Until time.Time
+}
+
+// Match reports whether the filter selects entry
+func (f *Filter) Match(entry *logger.LogEntry) bool {
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.Level != nil {
+		level, err := logger.ParseLevel(entry.Level)
+		if err != nil || level > *f.Level {
+			return false
+		}
+	}
+	if f.Component != "" && !matchComponent(f.Component, entry.Component) {
+		return false
+	}
+	if f.Contains != "" && !strings.Contains(entry.Message, f.Contains) {
+		return false
+	}
+	for key, want := range f.Fields {
+		value, ok := entry.Fields[key]
+		if !ok || fmt.Sprint(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// matchComponent reports whether component is pattern, a descendant of it,
+// or matches it as a glob
+func matchComponent(pattern, component string) bool {
+	if component == pattern || strings.HasPrefix(component, pattern+".") {
+		return true
+	}
+	matched, _ := path.Match(pattern, component)
+	return matched
+}
@@ -0,0 +1,240 @@
+package vlogread
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// IndexSuffix is appended to the path of a log file to name its index
+const IndexSuffix = ".idx"
+
+// DefaultIndexChunkSize is the size of the chunks an index describes, unless
+// IndexOptions say otherwise
+const DefaultIndexChunkSize = 1 << 20
+
+// indexVersion is the version of the index format; indexes of other
+// versions are ignored
+const indexVersion = 1
+
+// maxIndexComponents is the number of components a chunk lists before it
+// stops listing them
+const maxIndexComponents = 32
+
+// Index summarizes the chunks of a log file, so that queries can skip the
+// chunks holding no entry they select. It is stored as JSON next to the
+// file, at its path with IndexSuffix.
+type Index struct {
+	Version int          `json:"version"`
+	Size    int64        `json:"size"`     // Size of the file indexed
+	ModTime time.Time    `json:"mod_time"` // Modification time of the file indexed
+	Chunks  []IndexChunk `json:"chunks"`
+}
+
+// IndexChunk summarizes consecutive entries of a log file
+type IndexChunk struct {
+	Offset  int64     `json:"offset"` // Offset of its first entry
+	Size    int64     `json:"size"`   // Bytes up to the next chunk, or the end of the file
+	Line    int       `json:"line"`   // Number of its first line, or MessagePack entry
+	Entries int       `json:"entries"`
+	First   time.Time `json:"first"` // Earliest timestamp
+	Last    time.Time `json:"last"`  // Latest timestamp
+	Level   string    `json:"level"` // Most severe level
+	// Components lists the components of its entries, "" for entries
+	// without one; it is nil if there are too many to list
+	Components []string `json:"components,omitempty"`
+}
+
+// mayMatch reports whether the chunk can hold entries f selects. Only the
+// time range, level and component are indexed.
+func (c *IndexChunk) mayMatch(f *Filter) bool {
+	if c.Entries == 0 {
+		return false
+	}
+	if !f.Since.IsZero() && c.Last.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !c.First.Before(f.Until) {
+		return false
+	}
+	if f.Level != nil {
+		level, err := logger.ParseLevel(c.Level)
+		if err == nil && level > *f.Level {
+			return false
+		}
+	}
+	if f.Component != "" && c.Components != nil {
+		for _, component := range c.Components {
+			if matchComponent(f.Component, component) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// add accounts for an entry of the chunk
+func (c *IndexChunk) add(entry *logger.LogEntry) {
+	if c.Entries == 0 || entry.Timestamp.Before(c.First) {
+		c.First = entry.Timestamp
+	}
+	if c.Entries == 0 || entry.Timestamp.After(c.Last) {
+		c.Last = entry.Timestamp
+	}
+	if level, err := logger.ParseLevel(entry.Level); err == nil {
+		if most, err := logger.ParseLevel(c.Level); err != nil || level < most {
+			c.Level = entry.Level
+		}
+	}
+	if c.Entries == 0 || c.Components != nil {
+		c.addComponent(entry.Component)
+	}
+	c.Entries++
+}
+
+// addComponent lists a component, or stops listing them past
+// maxIndexComponents
+func (c *IndexChunk) addComponent(component string) {
+	for _, listed := range c.Components {
+		if listed == component {
+			return
+		}
+	}
+	if len(c.Components) == maxIndexComponents {
+		c.Components = nil
+		return
+	}
+	c.Components = append(c.Components, component)
+}
+
+// IndexOptions control how a file is indexed
+type IndexOptions struct {
+	// Options parse the file; their Filter is ignored. Text timestamps
+	// are read in their Location, which queries should share.
+	Options
+	// ChunkSize is the approximate size of the chunks, DefaultIndexChunkSize
+	// if 0. Smaller chunks let queries skip more, with a larger index.
+	ChunkSize int64
+}
+
+// IndexFile indexes the log file at path and writes the index next to it.
+// Files are indexed once they no longer change, such as rotated files; the
+// index of a file changed since is ignored.
+func IndexFile(path string, opts IndexOptions) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultIndexChunkSize
+	}
+	readOpts := opts.Options
+	readOpts.Filter = Filter{}
+	idx := &Index{Version: indexVersion, Size: info.Size(), ModTime: info.ModTime()}
+
+	r := NewReader(f, readOpts)
+	var chunk *IndexChunk
+	for r.Next() {
+		if chunk == nil || r.Offset()-chunk.Offset >= chunkSize {
+			if chunk != nil {
+				chunk.Size = r.Offset() - chunk.Offset
+			}
+			idx.Chunks = append(idx.Chunks, IndexChunk{Offset: r.Offset(), Line: r.startLine})
+			chunk = &idx.Chunks[len(idx.Chunks)-1]
+		}
+		chunk.add(r.Entry())
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	if chunk != nil {
+		chunk.Size = info.Size() - chunk.Offset
+	}
+
+	if err := idx.write(path + IndexSuffix); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// write writes the index to a temporary file renamed to path, so readers
+// never see it partially written
+func (idx *Index) write(path string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// ReadIndex reads the index of the log file at path
+func ReadIndex(path string) (*Index, error) {
+	data, err := os.ReadFile(path + IndexSuffix)
+	if err != nil {
+		return nil, err
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("index of %s: %w", path, err)
+	}
+	if idx.Version != indexVersion {
+		return nil, fmt.Errorf("index of %s: unsupported version %d", path, idx.Version)
+	}
+	return &idx, nil
+}
+
+// errStaleIndex reports an index of a file changed since it was indexed
+var errStaleIndex = errors.New("index is stale")
+
+// validIndex returns the index of the file at path if it describes the file
+// as info shows it
+func validIndex(path string, info os.FileInfo) (*Index, error) {
+	idx, err := ReadIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	if idx.Size != info.Size() || !idx.ModTime.Equal(info.ModTime()) {
+		return nil, errStaleIndex
+	}
+	return idx, nil
+}
+
+// IndexOnRotate returns a rotate callback for logger.FileOutput indexing
+// every file rotated:
+//
+//	out.SetRotateCallback(vlogread.IndexOnRotate(vlogread.IndexOptions{}))
+//
+// A file that can't be indexed is left without an index, which only makes
+// queries read all of it.
+func IndexOnRotate(opts IndexOptions) func(rotatedPath string) {
+	return func(rotatedPath string) {
+		IndexFile(rotatedPath, opts)
+	}
+}
@@ -0,0 +1,166 @@
+package vlogread
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// ParseLogfmt parses an entry written as logfmt, such as
+//
+//	time=2024-01-02T15:04:05Z level=INFO msg="user logged in" component=auth user_id=42
+//
+// The time, level, msg, component, file, line and instance_id keys (also
+// ts, lvl and message) set the entry's attributes the first time they
+// appear; every other key is a field. Quoted values are strings; bare
+// values are numbers, booleans or null when they read as such, and strings
+// otherwise. Fields of nested objects keep their dotted keys, e.g.
+// "http.status".
+func ParseLogfmt(line []byte) (*logger.LogEntry, error) {
+	entry := &logger.LogEntry{}
+	set := make(map[string]bool)
+	s := string(line)
+	for {
+		key, value, quoted, rest, err := nextLogfmtPair(s)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			break
+		}
+		s = rest
+
+		attr := logfmtAttributes[key]
+		if attr != "" && !set[attr] {
+			set[attr] = true
+			if err := setLogfmtAttribute(entry, attr, value); err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			continue
+		}
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]interface{})
+		}
+		if quoted {
+			entry.Fields[key] = value
+		} else {
+			entry.Fields[key] = logfmtValue(value)
+		}
+	}
+
+	if entry.Timestamp.IsZero() {
+		return nil, errors.New("no timestamp")
+	}
+	if err := checkLevel(entry.Level); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// logfmtAttributes maps the logfmt keys of entry attributes to the
+// attribute
+var logfmtAttributes = map[string]string{
+	"time":        "time",
+	"ts":          "time",
+	"level":       "level",
+	"lvl":         "level",
+	"msg":         "message",
+	"message":     "message",
+	"component":   "component",
+	"file":        "file",
+	"line":        "line",
+	"instance_id": "instance_id",
+}
+
+// setLogfmtAttribute sets an attribute of entry from its logfmt value
+func setLogfmtAttribute(entry *logger.LogEntry, attr, value string) error {
+	switch attr {
+	case "time":
+		ts, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		entry.Timestamp = ts
+	case "level":
+		entry.Level = value
+	case "message":
+		entry.Message = value
+	case "component":
+		entry.Component = value
+	case "file":
+		entry.File = value
+	case "line":
+		line, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		entry.Line = line
+	case "instance_id":
+		entry.InstanceID = value
+	}
+	return nil
+}
+
+// nextLogfmtPair cuts the next key=value pair from s. A key without a value
+// is true. key is empty at the end of s.
+func nextLogfmtPair(s string) (key, value string, quoted bool, rest string, err error) {
+	i := 0
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	start := i
+	for i < len(s) && s[i] != '=' && s[i] != ' ' {
+		i++
+	}
+	key = s[start:i]
+	if key == "" {
+		return "", "", false, "", nil
+	}
+	if i == len(s) || s[i] == ' ' {
+		return key, "true", false, s[i:], nil
+	}
+
+	i++ // '='
+	if i < len(s) && s[i] == '"' {
+		end := i + 1
+		for end < len(s) && s[end] != '"' {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(s) {
+			return "", "", false, "", fmt.Errorf("unterminated value of %s", key)
+		}
+		value, err = strconv.Unquote(s[i : end+1])
+		if err != nil {
+			return "", "", false, "", fmt.Errorf("value of %s: %w", key, err)
+		}
+		return key, value, true, s[end+1:], nil
+	}
+	start = i
+	for i < len(s) && s[i] != ' ' {
+		i++
+	}
+	return key, s[start:i], false, s[i:], nil
+}
+
+// logfmtValue types a bare logfmt value
+func logfmtValue(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if json.Valid([]byte(s)) && (s[0] == '-' || s[0] >= '0' && s[0] <= '9') {
+		return json.Number(s)
+	}
+	return s
+}
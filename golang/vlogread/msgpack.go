@@ -0,0 +1,268 @@
+package vlogread
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// maxMsgpackLength bounds the strings, arrays and maps decoded, so a
+// corrupt length can't make the reader allocate without limit
+const maxMsgpackLength = 64 << 20
+
+// msgpackTimestamp is the extension type of MessagePack timestamps
+const msgpackTimestamp = -1
+
+// isMsgpackMap reports whether b starts a MessagePack map
+func isMsgpackMap(b byte) bool {
+	return b&0xf0 == 0x80 || b == 0xde || b == 0xdf
+}
+
+// readMsgpack decodes one MessagePack value. Maps are decoded as
+// map[string]interface{}, arrays as []interface{}, integers as int64 or
+// uint64 and timestamps as time.Time.
+func readMsgpack(r *bufio.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return readMsgpackMap(r, int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return readMsgpackArray(r, int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		return readMsgpackString(r, int(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readMsgpackLength(r, b-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackBytes(r, n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readMsgpackLength(r, b-0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackExt(r, n)
+	case 0xca:
+		data, err := readMsgpackBytes(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case 0xcb:
+		data, err := readMsgpackBytes(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		data, err := readMsgpackBytes(r, 1<<(b-0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return uint64(bigEndian(data)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		data, err := readMsgpackBytes(r, size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend from the value's size
+		shift := 64 - 8*size
+		return int64(bigEndian(data)<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgpackExt(r, 1<<(b-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := readMsgpackLength(r, b-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, n)
+	case 0xdc, 0xdd:
+		n, err := readMsgpackLength(r, b-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readMsgpackLength(r, b-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, n)
+	}
+	return nil, fmt.Errorf("invalid MessagePack type 0x%02x", b)
+}
+
+// readMsgpackLength reads a big-endian length of 1, 2 or 4 bytes, for size
+// 0, 1 or 2
+func readMsgpackLength(r *bufio.Reader, size byte) (int, error) {
+	data, err := readMsgpackBytes(r, 1<<size)
+	if err != nil {
+		return 0, err
+	}
+	n := bigEndian(data)
+	if n > maxMsgpackLength {
+		return 0, fmt.Errorf("MessagePack length %d too large", n)
+	}
+	return int(n), nil
+}
+
+// bigEndian decodes an unsigned big-endian integer of up to 8 bytes
+func bigEndian(data []byte) uint64 {
+	var n uint64
+	for _, b := range data {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
+
+// readMsgpackBytes reads n bytes
+func readMsgpackBytes(r *bufio.Reader, n int) ([]byte, error) {
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, noEOF(err)
+	}
+	return data, nil
+}
+
+// readMsgpackString reads a string of n bytes
+func readMsgpackString(r *bufio.Reader, n int) (string, error) {
+	data, err := readMsgpackBytes(r, n)
+	return string(data), err
+}
+
+// readMsgpackArray reads the n elements of an array
+func readMsgpackArray(r *bufio.Reader, n int) ([]interface{}, error) {
+	if n > maxMsgpackLength {
+		return nil, fmt.Errorf("MessagePack length %d too large", n)
+	}
+	array := make([]interface{}, 0, min(n, 1024))
+	for range n {
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		array = append(array, v)
+	}
+	return array, nil
+}
+
+// readMsgpackMap reads the n pairs of a map, converting keys to strings
+func readMsgpackMap(r *bufio.Reader, n int) (map[string]interface{}, error) {
+	if n > maxMsgpackLength {
+		return nil, fmt.Errorf("MessagePack length %d too large", n)
+	}
+	m := make(map[string]interface{}, min(n, 1024))
+	for range n {
+		k, err := readMsgpack(r)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// readMsgpackExt reads an extension value of n bytes. Only timestamps are
+// supported.
+func readMsgpackExt(r *bufio.Reader, n int) (interface{}, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	data, err := readMsgpackBytes(r, n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != msgpackTimestamp {
+		return nil, fmt.Errorf("unsupported MessagePack extension %d", int8(typ))
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	}
+	return nil, fmt.Errorf("invalid MessagePack timestamp of %d bytes", n)
+}
+
+// noEOF turns an end of stream in the middle of a value into an error
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// entryFromMsgpack builds an entry from a decoded MessagePack map, which has
+// the keys of a JSON entry
+func entryFromMsgpack(v interface{}) (*logger.LogEntry, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("entry is a %T, not a map", v)
+	}
+	entry := &logger.LogEntry{}
+	switch ts := m["timestamp"].(type) {
+	case time.Time:
+		entry.Timestamp = ts
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return nil, err
+		}
+		entry.Timestamp = t
+	default:
+		return nil, errors.New("no timestamp")
+	}
+	entry.Level, _ = m["level"].(string)
+	if err := checkLevel(entry.Level); err != nil {
+		return nil, err
+	}
+	entry.Message, _ = m["message"].(string)
+	entry.Component, _ = m["component"].(string)
+	entry.File, _ = m["file"].(string)
+	switch line := m["line"].(type) {
+	case int64:
+		entry.Line = int(line)
+	case uint64:
+		entry.Line = int(line)
+	}
+	entry.Fields, _ = m["fields"].(map[string]interface{})
+	entry.InstanceID, _ = m["instance_id"].(string)
+	return entry, nil
+}
@@ -0,0 +1,223 @@
+package vlogread
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// Format is the format of the entries a Reader parses
+type Format int
+
+const (
+	// FormatAuto tells the formats apart: JSON entries are objects,
+	// logfmt entries start with "time=" or "ts=", a stream starting with a
+	// MessagePack map is MessagePack, and anything else is text
+	FormatAuto Format = iota
+	// FormatJSON parses entries written with logger.FormatJSON
+	FormatJSON
+	// FormatText parses entries written with logger.FormatText, with or
+	// without colors
+	FormatText
+	// FormatLogfmt parses entries written as logfmt key=value pairs, e.g.
+	// by vlogconv
+	FormatLogfmt
+	// FormatMsgpack parses a stream of MessagePack maps holding the same
+	// keys as JSON entries, e.g. written by vlogconv
+	FormatMsgpack
+)
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatAuto:
+		return "auto"
+	case FormatJSON:
+		return "json"
+	case FormatText:
+		return "text"
+	case FormatLogfmt:
+		return "logfmt"
+	case FormatMsgpack:
+		return "msgpack"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ParseFormat returns the format with the given name, as returned by
+// Format.String
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "auto", "":
+		return FormatAuto, nil
+	case "json":
+		return FormatJSON, nil
+	case "text":
+		return FormatText, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	case "msgpack", "messagepack":
+		return FormatMsgpack, nil
+	default:
+		return 0, fmt.Errorf("unknown format %q", name)
+	}
+}
+
+// textTimeLayout is the layout of text timestamps, which carry no zone
+const textTimeLayout = "2006-01-02 15:04:05.000"
+
+// ansiEscape matches the color sequences of colored text output
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// ParseJSON parses an entry written in the JSON format. Numbers in fields
+// are kept as json.Number, so they are written back unchanged.
+func ParseJSON(line []byte) (*logger.LogEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	entry := &logger.LogEntry{}
+	if err := dec.Decode(entry); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("data after the entry")
+	}
+	if entry.Timestamp.IsZero() {
+		return nil, errors.New("no timestamp")
+	}
+	if err := checkLevel(entry.Level); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// checkLevel accepts the level names entries are written with, AuditLevel
+// included
+func checkLevel(name string) error {
+	if name == logger.AuditLevel {
+		return nil
+	}
+	_, err := logger.ParseLevel(name)
+	return err
+}
+
+// ParseText parses an entry written in the text format, as one line or
+// several when the message holds newlines. Text timestamps have no zone;
+// they are read in loc, time.Local if nil. Text entries don't record the
+// instance ID.
+func ParseText(text []byte, loc *time.Location) (*logger.LogEntry, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	s := strings.TrimRight(string(text), "\r\n")
+	if strings.IndexByte(s, '\x1b') >= 0 {
+		s = ansiEscape.ReplaceAllString(s, "")
+	}
+
+	// 2024-01-02 15:04:05.000 [INFO] (component) [file.go:42] message {"key":"value"}
+	if len(s) < len(textTimeLayout) {
+		return nil, errors.New("no timestamp")
+	}
+	ts, err := time.ParseInLocation(textTimeLayout, s[:len(textTimeLayout)], loc)
+	if err != nil {
+		return nil, err
+	}
+	entry := &logger.LogEntry{Timestamp: ts}
+	s = s[len(textTimeLayout):]
+
+	level, rest, ok := cutDelimited(s, '[', ']')
+	if !ok {
+		return nil, errors.New("no level")
+	}
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	entry.Level, s = level, rest
+
+	if component, rest, ok := cutDelimited(s, '(', ')'); ok && !strings.ContainsAny(component, " \n") {
+		entry.Component, s = component, rest
+	}
+	if source, rest, ok := cutDelimited(s, '[', ']'); ok {
+		if i := strings.LastIndexByte(source, ':'); i > 0 && !strings.ContainsAny(source, " \n") {
+			if line, err := strconv.Atoi(source[i+1:]); err == nil {
+				entry.File, entry.Line, s = source[:i], line, rest
+			}
+		}
+	}
+
+	s = strings.TrimPrefix(s, " ")
+	entry.Message = s
+	// The fields are the last JSON object of the entry; the message
+	// itself may hold braces
+	for i := len(s); i > 0; {
+		i = strings.LastIndex(s[:i], " {")
+		if i < 0 {
+			break
+		}
+		fields, ok := parseFields(s[i+1:])
+		if ok {
+			entry.Message, entry.Fields = s[:i], fields
+			break
+		}
+	}
+	return entry, nil
+}
+
+// cutDelimited cuts " <open>value<close>" from the start of s
+func cutDelimited(s string, open, close byte) (value, rest string, ok bool) {
+	if len(s) < 3 || s[0] != ' ' || s[1] != open {
+		return "", s, false
+	}
+	end := strings.IndexByte(s[2:], close)
+	if end < 0 {
+		return "", s, false
+	}
+	return s[2 : 2+end], s[3+end:], true
+}
+
+// parseFields decodes s if it is exactly one JSON object
+func parseFields(s string) (map[string]interface{}, bool) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil || fields == nil || dec.More() {
+		return nil, false
+	}
+	// Decode stops at the end of the object; anything but trailing space
+	// after it belongs to the message
+	if strings.TrimSpace(s[dec.InputOffset():]) != "" {
+		return nil, false
+	}
+	return fields, true
+}
+
+// isTextStart reports whether line starts a text entry, i.e. with a
+// timestamp, possibly colored
+func isTextStart(line []byte) bool {
+	if bytes.HasPrefix(line, []byte("\x1b[")) {
+		line = ansiEscape.ReplaceAll(line, nil)
+	}
+	if len(line) < len(textTimeLayout) {
+		return false
+	}
+	for i, c := range line[:len(textTimeLayout)] {
+		switch textTimeLayout[i] {
+		case '-', ' ', ':', '.':
+			if c != textTimeLayout[i] {
+				return false
+			}
+		default:
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package vlogread_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+	"github.com/hemant-mann/logger/golang/vlogconv"
+	"github.com/hemant-mann/logger/golang/vlogread"
+)
+
+func TestAuditEntriesRoundTrip(t *testing.T) {
+	for _, format := range []vlogread.Format{vlogread.FormatJSON, vlogread.FormatText, vlogread.FormatLogfmt, vlogread.FormatMsgpack} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := vlogconv.NewWriter(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := logger.NewLogger()
+			defer l.Close()
+			l.AddAuditOutput(w)
+			if err := l.With("billing").Audit("Invoice deleted", map[string]interface{}{"invoice": "INV-7"}); err != nil {
+				t.Fatal(err)
+			}
+
+			r := vlogread.NewReader(&buf, vlogread.Options{Format: format, Strict: true, Location: time.Local})
+			if !r.Next() {
+				t.Fatalf("no entry read: %v", r.Err())
+			}
+			entry := r.Entry()
+			if entry.Level != logger.AuditLevel || entry.Message != "Invoice deleted" || entry.Component != "billing" {
+				t.Errorf("read %+v", entry)
+			}
+			if got := entry.Fields["invoice"]; got != "INV-7" {
+				t.Errorf("invoice field %v", got)
+			}
+			if r.Next() || r.Err() != nil {
+				t.Errorf("more after the entry: %v", r.Err())
+			}
+		})
+	}
+}
+
+func TestParseRejectsUnknownLevels(t *testing.T) {
+	if _, err := vlogread.ParseJSON([]byte(`{"timestamp":"2024-01-02T15:04:05Z","level":"LOUD","message":"x"}`)); err == nil {
+		t.Error("ParseJSON accepted an unknown level")
+	}
+	if _, err := vlogread.ParseText([]byte("2024-01-02 15:04:05.000 [LOUD] x"), time.UTC); err == nil {
+		t.Error("ParseText accepted an unknown level")
+	}
+}
@@ -0,0 +1,133 @@
+package vlogread
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// Query selects entries across log files, such as a file and the files
+// rotated from it. Files with an up to date index (see IndexFile) only have
+// the chunks that can hold selected entries read.
+type Query struct {
+	// Options parse the files; their Filter selects the entries
+	Options
+	// Limit stops the query after this many entries, if positive
+	Limit int
+	// NoIndex reads every file whole, ignoring their indexes
+	NoIndex bool
+}
+
+// QueryStats describes the work a query did
+type QueryStats struct {
+	Files         int // Files read
+	Indexed       int // Files read through their index
+	ChunksRead    int // Chunks of indexed files read
+	ChunksSkipped int // Chunks of indexed files skipped
+	Entries       int // Entries selected
+}
+
+// errLimit stops a query at its limit
+var errLimit = errors.New("query limit reached")
+
+// Run passes the entries the query selects in the files at paths, in order,
+// to fn. It stops at the first error, from reading a file, fn or ctx, and
+// returns it.
+func (q *Query) Run(ctx context.Context, paths []string, fn func(path string, entry *logger.LogEntry) error) (QueryStats, error) {
+	var stats QueryStats
+	for _, path := range paths {
+		err := q.runFile(ctx, path, &stats, fn)
+		if err == errLimit {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// runFile runs the query on one file
+func (q *Query) runFile(ctx context.Context, path string, stats *QueryStats, fn func(path string, entry *logger.LogEntry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	stats.Files++
+
+	var idx *Index
+	if !q.NoIndex {
+		idx, _ = validIndex(path, info)
+	}
+	if idx == nil {
+		return q.read(ctx, path, f, 0, stats, fn)
+	}
+
+	stats.Indexed++
+	for i := 0; i < len(idx.Chunks); {
+		if !idx.Chunks[i].mayMatch(&q.Filter) {
+			stats.ChunksSkipped++
+			i++
+			continue
+		}
+		// Adjacent chunks are read at once
+		first := idx.Chunks[i]
+		size := int64(0)
+		for ; i < len(idx.Chunks) && idx.Chunks[i].mayMatch(&q.Filter); i++ {
+			size += idx.Chunks[i].Size
+			stats.ChunksRead++
+		}
+		section := io.NewSectionReader(f, first.Offset, size)
+		if err := q.read(ctx, path, section, first.Line-1, stats, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read passes the entries selected in r, whose first line follows line
+// number line, to fn
+func (q *Query) read(ctx context.Context, path string, r io.Reader, line int, stats *QueryStats, fn func(path string, entry *logger.LogEntry) error) error {
+	reader := NewReader(r, q.Options)
+	reader.line = line
+	for reader.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(path, reader.Entry()); err != nil {
+			return err
+		}
+		stats.Entries++
+		if q.Limit > 0 && stats.Entries >= q.Limit {
+			return errLimit
+		}
+	}
+	return reader.Err()
+}
+
+// RotatedFiles returns the files rotated from path by a FileOutput, named
+// after it with a timestamp suffix, oldest first
+func RotatedFiles(path string) []string {
+	matches, _ := filepath.Glob(path + ".[0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]-[0-9][0-9][0-9][0-9][0-9][0-9]")
+	// The timestamps sort chronologically, as Glob sorts
+	return matches
+}
+
+// LogFiles returns the files rotated from path, oldest first, followed by
+// path if it exists: the files to query for every entry logged to path
+func LogFiles(path string) []string {
+	files := RotatedFiles(path)
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
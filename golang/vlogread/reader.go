@@ -0,0 +1,236 @@
+// Package vlogread parses log files written by the logger package, in its
+// JSON and text formats or as logfmt or MessagePack (see vlogconv), back
+// into entries, for tools, tests and replays that work on existing logs:
+//
+//	r := vlogread.NewReader(f, vlogread.Options{})
+//	for r.Next() {
+//	    entry := r.Entry()
+//	    ...
+//	}
+//	if err := r.Err(); err != nil {
+//	    ...
+//	}
+//
+// Entries are read one at a time, so files of any size can be processed.
+// Lines that aren't entries, e.g. a truncated last line or output of
+// another program, are skipped unless Options.Strict is set.
+package vlogread
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// LineError describes a line that could not be parsed
+type LineError struct {
+	Line int    // Number of the line, or of the entry in MessagePack, from 1
+	Text string // The line, without its newline
+	Err  error
+}
+
+// Error implements error
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the parse error
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Options control how entries are parsed and selected
+type Options struct {
+	// Format is the format of the entries, FormatAuto by default
+	Format Format
+	// Location is the time zone text timestamps were written in,
+	// time.Local if nil
+	Location *time.Location
+	// Filter selects the entries returned; the others are skipped
+	Filter Filter
+	// Strict stops reading at the first line that isn't an entry, which
+	// Err then returns, instead of skipping it
+	Strict bool
+	// OnError, if set, is called for every line skipped
+	OnError func(err *LineError)
+}
+
+// Reader reads entries from a stream of log lines
+type Reader struct {
+	r     *bufio.Reader
+	count *countingReader
+	opts  Options
+
+	lines     assembler
+	line      int   // Number of the last line, or MessagePack entry, read
+	start     int64 // Offset of the entry returned
+	startLine int   // Number of its first line
+	eof       bool
+	binary    bool // Reading MessagePack
+	started   bool
+	entry     *logger.LogEntry
+	err       error
+	skipped   int
+}
+
+// NewReader returns a Reader parsing the entries read from r
+func NewReader(r io.Reader, opts Options) *Reader {
+	count := &countingReader{r: r}
+	return &Reader{r: bufio.NewReaderSize(count, 64<<10), count: count, opts: opts, lines: assembler{format: opts.Format}}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// consumed returns the number of bytes of the stream consumed so far
+func (r *Reader) consumed() int64 {
+	return r.count.n - int64(r.r.Buffered())
+}
+
+// Next reads the next entry, which Entry then returns. It returns false at
+// the end of the stream or on an error, which Err returns.
+func (r *Reader) Next() bool {
+	r.entry = nil
+	if !r.started {
+		r.started = true
+		if r.opts.Format == FormatMsgpack {
+			r.binary = true
+		} else if b, err := r.r.Peek(1); err == nil && r.opts.Format == FormatAuto {
+			r.binary = isMsgpackMap(b[0])
+		}
+	}
+	if r.binary {
+		return r.nextMsgpack()
+	}
+
+	for r.err == nil {
+		if c, ok := r.lines.pop(); ok {
+			r.entry, r.err = r.parse(c)
+			if r.entry != nil {
+				r.start, r.startLine = c.offset, c.line
+				return true
+			}
+			continue
+		}
+		if r.eof {
+			return false
+		}
+
+		offset := r.consumed()
+		line, err := r.r.ReadBytes('\n')
+		if len(line) > 0 {
+			r.line++
+			r.lines.add(bytes.TrimRight(line, "\r\n"), r.line, offset)
+		}
+		if err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			r.eof = true
+			r.lines.flush()
+		}
+	}
+	return false
+}
+
+// nextMsgpack reads the next MessagePack entry
+func (r *Reader) nextMsgpack() bool {
+	for r.err == nil {
+		offset := r.consumed()
+		v, err := readMsgpack(r.r)
+		if err == io.EOF {
+			return false
+		}
+		if err != nil {
+			// The stream can't be resynchronized
+			r.err = fmt.Errorf("entry %d: %w", r.line+1, err)
+			return false
+		}
+		r.line++
+
+		entry, err := entryFromMsgpack(v)
+		if err != nil {
+			r.entry, r.err = nil, r.skip(&LineError{Line: r.line, Text: fmt.Sprint(v), Err: err})
+			continue
+		}
+		if r.opts.Filter.Match(entry) {
+			r.entry, r.start, r.startLine = entry, offset, r.line
+			return true
+		}
+	}
+	return false
+}
+
+// parse parses a chunk of lines into an entry. It returns a nil entry if the
+// chunk is skipped, either filtered out or not an entry, and an error if
+// reading must stop.
+func (r *Reader) parse(c chunk) (*logger.LogEntry, error) {
+	entry, lineErr := parseChunk(c, r.opts)
+	if lineErr != nil {
+		return nil, r.skip(lineErr)
+	}
+	if !r.opts.Filter.Match(entry) {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// skip skips what couldn't be parsed, unless in strict mode, where it
+// returns the error to stop at
+func (r *Reader) skip(err *LineError) error {
+	if r.opts.Strict {
+		return err
+	}
+	r.skipped++
+	if r.opts.OnError != nil {
+		r.opts.OnError(err)
+	}
+	return nil
+}
+
+// Offset returns the byte offset in the stream at which the entry read by
+// the last call to Next starts
+func (r *Reader) Offset() int64 {
+	return r.start
+}
+
+// Entry returns the entry read by the last call to Next. Unlike entries
+// passed to outputs, it belongs to the caller.
+func (r *Reader) Entry() *logger.LogEntry {
+	return r.entry
+}
+
+// Err returns the error that stopped Next, if not the end of the stream: a
+// read error, or a *LineError in strict mode
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Skipped returns the number of lines skipped as not being entries; entries
+// filtered out aren't counted
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// ReadAll reads every entry from r
+func ReadAll(r io.Reader, opts Options) ([]*logger.LogEntry, error) {
+	reader := NewReader(r, opts)
+	var entries []*logger.LogEntry
+	for reader.Next() {
+		entries = append(entries, reader.Entry())
+	}
+	return entries, reader.Err()
+}
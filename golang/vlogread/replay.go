@@ -0,0 +1,81 @@
+package vlogread
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// ReplayOptions control how entries are replayed
+type ReplayOptions struct {
+	// Speed paces the entries by their timestamps, Speed times as fast as
+	// they were logged: 1 keeps the original pacing, 10 is ten times
+	// faster. 0 replays them as fast as the logger takes them.
+	Speed float64
+	// MaxGap, if positive, caps the wait between two paced entries, so
+	// quiet periods of the recording don't stall the replay
+	MaxGap time.Duration
+	// Retime stamps the entries with the time they are replayed, instead
+	// of the time they were logged
+	Retime bool
+}
+
+// Replay writes the entries of r to the outputs of l (see logger.Replay),
+// for backfilling a new sink from archived files or load testing the
+// systems downstream of the outputs. It returns the number of entries
+// replayed, and stops at the first error of r or ctx.
+func Replay(ctx context.Context, l *logger.Logger, r *Reader, opts ReplayOptions) (int, error) {
+	n := 0
+	var last time.Time // Latest timestamp replayed
+	next := time.Now() // When to replay the next entry
+	for r.Next() {
+		entry := r.Entry()
+		if opts.Speed > 0 {
+			if !last.IsZero() {
+				// Entries out of order, as several goroutines log them,
+				// are replayed at once
+				gap := time.Duration(float64(entry.Timestamp.Sub(last)) / opts.Speed)
+				if gap < 0 {
+					gap = 0
+				}
+				if opts.MaxGap > 0 && gap > opts.MaxGap {
+					gap = opts.MaxGap
+				}
+				next = next.Add(gap)
+				if err := sleepUntil(ctx, next); err != nil {
+					return n, err
+				}
+			}
+			if entry.Timestamp.After(last) {
+				last = entry.Timestamp
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		if opts.Retime {
+			entry.Timestamp = time.Now()
+		}
+		l.Replay(entry)
+		n++
+	}
+	return n, r.Err()
+}
+
+// sleepUntil waits until t, or for ctx to be done
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
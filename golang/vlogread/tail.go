@@ -0,0 +1,267 @@
+package vlogread
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// DefaultPollInterval is how often a Tailer checks for new entries by
+// default
+const DefaultPollInterval = 250 * time.Millisecond
+
+// TailOptions control how a Tailer follows a file
+type TailOptions struct {
+	Options
+	// FromStart reads the entries already in the file first, instead of
+	// only those written from now on
+	FromStart bool
+	// PollInterval is how often the file is checked for new entries and
+	// rotation (default DefaultPollInterval)
+	PollInterval time.Duration
+}
+
+// Tailer follows a log file as it is written, like tail -F, parsing and
+// filtering its entries as they arrive. It survives both rotation modes of
+// logger.FileOutput:
+//
+//   - when the file is renamed and a new one created, which it notices by
+//     the path leading to another file, it reads the renamed file to its
+//     end and continues with the new one
+//   - when the file is copied and truncated, it reads the entries it
+//     hadn't yet read from the copy, the newest file named after the path
+//     with a rotation timestamp, and continues from the start of the file
+//
+// The file doesn't need to exist yet. MessagePack files can't be followed.
+type Tailer struct {
+	path string
+	opts TailOptions
+
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte // Start of a line still being written
+	lines   assembler
+	line    int
+	started bool
+
+	entry   *logger.LogEntry
+	err     error
+	skipped int
+}
+
+// NewTailer returns a Tailer following the file at path
+func NewTailer(path string, opts TailOptions) *Tailer {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	return &Tailer{path: path, opts: opts, lines: assembler{format: opts.Format}}
+}
+
+// Next waits for the next entry, which Entry then returns. It returns false
+// once ctx is done, or on an error, which Err returns.
+func (t *Tailer) Next(ctx context.Context) bool {
+	t.entry = nil
+	for t.err == nil {
+		if c, ok := t.lines.pop(); ok {
+			entry, lineErr := parseChunk(c, t.opts.Options)
+			switch {
+			case lineErr != nil && t.opts.Strict:
+				t.err = lineErr
+			case lineErr != nil:
+				t.skipped++
+				if t.opts.OnError != nil {
+					t.opts.OnError(lineErr)
+				}
+			case t.opts.Filter.Match(entry):
+				t.entry = entry
+				return true
+			}
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return false
+		}
+		read, err := t.poll()
+		if err != nil {
+			t.err = err
+			return false
+		}
+		if read {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(t.opts.PollInterval):
+		}
+	}
+	return false
+}
+
+// poll reads what was written since the last call, following rotations. It
+// reports whether anything was read.
+func (t *Tailer) poll() (bool, error) {
+	if t.file == nil && !t.open() {
+		return false, nil
+	}
+
+	read, err := t.readFrom(t.file)
+	if err != nil || read {
+		return read, err
+	}
+
+	// At the end of the file: complete entries were written whole, so the
+	// held back one is done
+	t.lines.flush()
+
+	info, err := os.Stat(t.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Renamed, and the new file isn't created yet
+		return false, nil
+	case err != nil:
+		return false, err
+	case !os.SameFile(info, t.info):
+		// Renamed: read what was written to it before it was, then move
+		// on to the new file
+		if _, err := t.readFrom(t.file); err != nil {
+			return false, err
+		}
+		t.endLine()
+		t.lines.flush()
+		t.file.Close()
+		t.file = nil
+		t.open()
+		return true, nil
+	case info.Size() < t.offset:
+		// Truncated: the entries written since the last read are in the
+		// copy, where the line read last continues
+		if rotated := rotatedCopy(t.path); rotated != "" {
+			if f, err := os.Open(rotated); err == nil {
+				if _, err := f.Seek(t.offset, io.SeekStart); err == nil {
+					t.readFrom(f)
+				}
+				f.Close()
+			}
+		}
+		t.endLine()
+		t.lines.flush()
+		t.offset = 0
+		_, err := t.file.Seek(0, io.SeekStart)
+		return true, err
+	}
+	return false, nil
+}
+
+// open opens the file, at its end if it existed when tailing started, unless
+// FromStart is set. It reports whether the file exists.
+func (t *Tailer) open() bool {
+	first := !t.started
+	t.started = true
+	file, err := os.Open(t.path)
+	if err != nil {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return false
+	}
+	t.file, t.info, t.offset = file, info, 0
+	if first && !t.opts.FromStart {
+		if offset, err := file.Seek(0, io.SeekEnd); err == nil {
+			t.offset = offset
+		}
+	}
+	return true
+}
+
+// readFrom reads f to its end, adding the complete lines. It reports
+// whether anything was read.
+func (t *Tailer) readFrom(f *os.File) (bool, error) {
+	var buf [32 << 10]byte
+	read := false
+	for {
+		n, err := f.Read(buf[:])
+		if n > 0 {
+			read = true
+			if f == t.file {
+				t.offset += int64(n)
+			}
+			t.partial = append(t.partial, buf[:n]...)
+			t.addLines()
+		}
+		if err == io.EOF || n == 0 {
+			return read, nil
+		}
+		if err != nil {
+			return read, err
+		}
+	}
+}
+
+// addLines adds the complete lines at the start of t.partial
+func (t *Tailer) addLines() {
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 {
+			return
+		}
+		t.line++
+		t.lines.add(bytes.Clone(bytes.TrimRight(t.partial[:i], "\r")), t.line, 0)
+		t.partial = t.partial[i+1:]
+	}
+}
+
+// endLine adds what is left of a line whose file ended without a newline
+func (t *Tailer) endLine() {
+	if len(t.partial) > 0 {
+		t.line++
+		t.lines.add(bytes.Clone(t.partial), t.line, 0)
+		t.partial = nil
+	}
+}
+
+// rotatedCopy returns the newest file rotated from path, or "" if there is
+// none
+func rotatedCopy(path string) string {
+	matches := RotatedFiles(path)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1]
+}
+
+// Entry returns the entry read by the last call to Next
+func (t *Tailer) Entry() *logger.LogEntry {
+	return t.entry
+}
+
+// Err returns the error that stopped Next, if not ctx: a read error, or a
+// *LineError in strict mode
+func (t *Tailer) Err() error {
+	return t.err
+}
+
+// Skipped returns the number of lines skipped as not being entries
+func (t *Tailer) Skipped() int {
+	return t.skipped
+}
+
+// Close closes the file
+func (t *Tailer) Close() error {
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
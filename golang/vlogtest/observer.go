@@ -0,0 +1,211 @@
+package vlogtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// ObservedEntry is an entry recorded by an Observer. Unlike the
+// logger.LogEntry it was copied from, it is never recycled.
+type ObservedEntry struct {
+	Timestamp  time.Time
+	Level      logger.Level
+	Message    string
+	Component  string
+	File       string
+	Line       int
+	Fields     []logger.Field // In the order they were added
+	InstanceID string
+}
+
+// Field returns the value of the field with the given key, and whether the
+// entry has it
+func (e ObservedEntry) Field(key string) (interface{}, bool) {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// String formats the entry for failure messages
+func (e ObservedEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]", e.Level)
+	if e.Component != "" {
+		fmt.Fprintf(&b, " (%s)", e.Component)
+	}
+	fmt.Fprintf(&b, " %s", e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	return b.String()
+}
+
+// Entries is a list of observed entries, oldest first
+type Entries []ObservedEntry
+
+// FilterLevel returns the entries logged at exactly the given level
+func (es Entries) FilterLevel(level logger.Level) Entries {
+	return es.filter(func(e ObservedEntry) bool {
+		return e.Level == level
+	})
+}
+
+// FilterMessageContains returns the entries whose message contains substr
+func (es Entries) FilterMessageContains(substr string) Entries {
+	return es.filter(func(e ObservedEntry) bool {
+		return strings.Contains(e.Message, substr)
+	})
+}
+
+// FilterField returns the entries with a field of the given key and value.
+// Values are compared with reflect.DeepEqual, after the logger encoded them:
+// errors, for one, are recorded as the structured value written to outputs.
+func (es Entries) FilterField(key string, value interface{}) Entries {
+	return es.filter(func(e ObservedEntry) bool {
+		v, ok := e.Field(key)
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// filter returns the entries matching fn
+func (es Entries) filter(fn func(ObservedEntry) bool) Entries {
+	var out Entries
+	for _, e := range es {
+		if fn(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Observer is an output recording entries in memory, so tests can check
+// what was logged without parsing formatted lines, like zaptest/observer:
+//
+//	obs := vlogtest.NewObserver()
+//	l, _ := logger.New(logger.WithSynchronous(true), logger.WithOutputs(obs))
+//	handle(l, request)
+//	obs.AssertLogged(t, logger.LevelWarning, "retrying")
+//
+// An asynchronous logger writes entries in the background, so flush it
+// before looking at what was recorded.
+type Observer struct {
+	mu      sync.Mutex
+	entries Entries
+}
+
+// NewObserver returns an empty Observer
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// Write records a copy of the entry
+func (o *Observer) Write(entry *logger.LogEntry) error {
+	level, err := logger.ParseLevel(entry.Level)
+	if err != nil {
+		return err
+	}
+	observed := ObservedEntry{
+		Timestamp:  entry.Timestamp,
+		Level:      level,
+		Message:    entry.Message,
+		Component:  entry.Component,
+		File:       entry.File,
+		Line:       entry.Line,
+		Fields:     entry.FieldList(),
+		InstanceID: entry.InstanceID,
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = append(o.entries, observed)
+	return nil
+}
+
+// Close does nothing; entries can still be inspected
+func (o *Observer) Close() error {
+	return nil
+}
+
+// All returns the recorded entries
+func (o *Observer) All() Entries {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append(Entries(nil), o.entries...)
+}
+
+// TakeAll returns the recorded entries and forgets them
+func (o *Observer) TakeAll() Entries {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Len returns the number of recorded entries
+func (o *Observer) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// FilterLevel returns the recorded entries logged at exactly the given level
+func (o *Observer) FilterLevel(level logger.Level) Entries {
+	return o.All().FilterLevel(level)
+}
+
+// FilterMessageContains returns the recorded entries whose message contains
+// substr
+func (o *Observer) FilterMessageContains(substr string) Entries {
+	return o.All().FilterMessageContains(substr)
+}
+
+// FilterField returns the recorded entries with a field of the given key and
+// value (see Entries.FilterField)
+func (o *Observer) FilterField(key string, value interface{}) Entries {
+	return o.All().FilterField(key, value)
+}
+
+// AssertLogged fails the test unless an entry was logged at level with a
+// message containing substr, and returns the first such entry
+func (o *Observer) AssertLogged(t testing.TB, level logger.Level, substr string) ObservedEntry {
+	t.Helper()
+	all := o.All()
+	matches := all.FilterLevel(level).FilterMessageContains(substr)
+	if len(matches) == 0 {
+		t.Errorf("no %s entry containing %q was logged; got:%s", level, substr, all.list())
+		return ObservedEntry{}
+	}
+	return matches[0]
+}
+
+// AssertNotLogged fails the test if an entry was logged at level with a
+// message containing substr
+func (o *Observer) AssertNotLogged(t testing.TB, level logger.Level, substr string) {
+	t.Helper()
+	if matches := o.All().FilterLevel(level).FilterMessageContains(substr); len(matches) > 0 {
+		t.Errorf("unexpected %s entry containing %q was logged:%s", level, substr, matches.list())
+	}
+}
+
+// list formats the entries one per line for failure messages
+func (es Entries) list() string {
+	if len(es) == 0 {
+		return " nothing"
+	}
+	var b strings.Builder
+	for _, e := range es {
+		b.WriteString("\n\t")
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
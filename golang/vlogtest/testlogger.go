@@ -0,0 +1,163 @@
+package vlogtest
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// TestTime is the time a test logger's clock starts at
+var TestTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestInstanceID is the instance ID of test loggers
+const TestInstanceID = "test"
+
+// Clock is a logger.Clock that only moves when told to
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a clock stopped at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the clock's time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to now
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Add moves the clock forward by d
+func (c *Clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// NewTestLogger returns a logger whose output only depends on what is
+// logged, for comparing it with golden files:
+//
+//   - entries are written synchronously, in the order they are logged
+//   - timestamps come from a Clock stopped at TestTime; move it with
+//     l.Clock().(*vlogtest.Clock).Add
+//   - the instance ID is TestInstanceID
+//
+// Entries go to the log of t, and to any outputs added through opts, which
+// are applied last and may override the settings above. A Buffer records
+// the output without colors and with fields sorted by key:
+//
+//	buf := vlogtest.NewBuffer(logger.FormatJSON)
+//	l := vlogtest.NewTestLogger(t, logger.AddOutputs(buf))
+//
+// The logger is closed when the test ends.
+func NewTestLogger(t testing.TB, opts ...logger.Option) *logger.Logger {
+	t.Helper()
+	defaults := []logger.Option{
+		logger.WithSynchronous(true),
+		logger.WithClock(NewClock(TestTime)),
+		logger.WithInstanceID(TestInstanceID),
+		logger.WithOutputs(NewTestOutput(t)),
+	}
+	l, err := logger.New(append(defaults, opts...)...)
+	if err != nil {
+		t.Fatalf("creating test logger: %v", err)
+	}
+	t.Cleanup(func() {
+		l.Close()
+	})
+	return l
+}
+
+// Buffer is an output keeping formatted entries in memory. Unlike entries
+// written by the logger's own outputs, fields are sorted by key rather than
+// kept in the order they were added, so refactoring how fields are passed
+// doesn't change golden files.
+type Buffer struct {
+	format logger.OutputFormat
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewBuffer returns an empty buffer writing entries in the given format
+func NewBuffer(format logger.OutputFormat) *Buffer {
+	return &Buffer{format: format}
+}
+
+// Write appends the formatted entry to the buffer
+func (b *Buffer) Write(entry *logger.LogEntry) error {
+	// A copy holding only the Fields map, without the order they were
+	// added in, is formatted with its fields sorted
+	fields := make(map[string]interface{}, len(entry.Fields))
+	for k, v := range entry.Fields {
+		fields[k] = v
+	}
+	sorted := logger.LogEntry{
+		Timestamp:  entry.Timestamp,
+		Level:      entry.Level,
+		Message:    entry.Message,
+		Component:  entry.Component,
+		File:       entry.File,
+		Line:       entry.Line,
+		Fields:     fields,
+		InstanceID: entry.InstanceID,
+	}
+
+	data, err := logger.AppendEntry(nil, &sorted, b.format)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Write(data)
+	return nil
+}
+
+// Close does nothing; the contents can still be read
+func (b *Buffer) Close() error {
+	return nil
+}
+
+// Bytes returns a copy of everything written
+func (b *Buffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
+
+// String returns everything written
+func (b *Buffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Lines returns the written entries, one per line, without newlines
+func (b *Buffer) Lines() []string {
+	s := strings.TrimSuffix(b.String(), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// Reset empties the buffer
+func (b *Buffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
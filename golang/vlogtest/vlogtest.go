@@ -0,0 +1,73 @@
+// Package vlogtest helps tests log through a logger and check what it
+// logged.
+//
+//	func TestHandler(t *testing.T) {
+//	    l, _ := logger.New(
+//	        logger.WithSynchronous(true),
+//	        logger.WithOutputs(vlogtest.NewTestOutput(t)),
+//	    )
+//	    ...
+//	}
+package vlogtest
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	logger "github.com/hemant-mann/logger/golang"
+)
+
+// TestOutput writes entries to a test's log through t.Log, so they
+// interleave with the test's own output and, like it, are only shown when
+// the test fails or runs with -v
+type TestOutput struct {
+	t      testing.TB
+	format logger.OutputFormat
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewTestOutput returns an output writing text entries to the log of t. It
+// is closed when the test ends: testing panics on logging after that, so
+// entries written later, e.g. still queued by an asynchronous logger, are
+// discarded. Use a synchronous logger, or close the logger before the test
+// returns, to see every entry.
+func NewTestOutput(t testing.TB) *TestOutput {
+	return NewTestOutputFormat(t, logger.FormatText)
+}
+
+// NewTestOutputFormat is NewTestOutput writing entries in the given format
+func NewTestOutputFormat(t testing.TB, format logger.OutputFormat) *TestOutput {
+	o := &TestOutput{t: t, format: format}
+	t.Cleanup(func() {
+		o.Close()
+	})
+	return o
+}
+
+// Write logs the entry to the test, unless the test is over
+func (o *TestOutput) Write(entry *logger.LogEntry) error {
+	o.t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil
+	}
+
+	data, err := logger.AppendEntry(nil, entry, o.format)
+	if err != nil {
+		return err
+	}
+	o.t.Log(string(bytes.TrimSuffix(data, []byte{'\n'})))
+	return nil
+}
+
+// Close stops writing to the test's log
+func (o *TestOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	return nil
+}
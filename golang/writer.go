@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lineWriter is an io.Writer logging every line written to it
+type lineWriter struct {
+	mu     sync.Mutex
+	logger *Logger
+	level  Level
+	buf    []byte
+}
+
+// Writer returns an io.Writer that logs each line written to it as a message
+// at the given level. It lets libraries that only accept an io.Writer, such
+// as the standard log package or web frameworks, write through this logger.
+// Incomplete lines are buffered until their newline arrives.
+func (l *Logger) Writer(level Level) io.Writer {
+	return &lineWriter{logger: l, level: level}
+}
+
+// Write logs every complete line in p
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := bytes.TrimRight(w.buf[:i], "\r")
+		if len(line) > 0 {
+			w.logger.log(w.level, 2, string(line))
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}